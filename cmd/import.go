@@ -0,0 +1,98 @@
+package main
+
+import (
+	"APIGolang/internal/config"
+	"APIGolang/internal/db"
+	"APIGolang/internal/i18n"
+	"APIGolang/internal/logging"
+	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"APIGolang/internal/spreadsheet"
+	"APIGolang/internal/usecase"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// runImport handles the "import" subcommand:
+//
+//	main import -file produtos.xlsx [-dry-run]
+//
+// It prints the import report as JSON and exits with status 1 when any row is invalid
+func runImport(args []string) int {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	path := flags.String("file", "", "planilha CSV ou XLSX com os produtos")
+	dryRun := flags.Bool("dry-run", false, "somente validar, sem gravar")
+	flags.Parse(args)
+
+	if *path == "" {
+		fmt.Fprintln(os.Stderr, "informe o arquivo com -file")
+		flags.Usage()
+		return 2
+	}
+
+	format, err := spreadsheet.FormatFromFilename(*path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer file.Close()
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// stdout is reserved for the JSON report
+	logger := logging.New(cfg.Log, os.Stderr)
+	slog.SetDefault(logger)
+
+	dbConnection, err := db.ConnectDB(cfg.Database)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer dbConnection.Close()
+
+	timeouts := repository.NewTimeouts(cfg.Database)
+	productRepository := repository.NewProductRepository(dbConnection, timeouts, logger)
+	categoryRepository := repository.NewCategoryRepository(dbConnection, timeouts)
+	supplierRepository := repository.NewSupplierRepository(dbConnection, timeouts)
+	auditRepository := repository.NewAuditRepository(dbConnection, timeouts)
+	unitOfWork := repository.NewUnitOfWork(dbConnection, timeouts)
+	importUsecase := usecase.NewProductImportUseCase(productRepository, categoryRepository, supplierRepository, auditRepository, unitOfWork)
+
+	// Ctrl+C rolls back the import instead of leaving it half applied
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	report, err := importUsecase.Import(ctx, file, format, *dryRun, model.Actor{})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	for i, rowErr := range report.Errors {
+		report.Errors[i].Message = i18n.T(i18n.Default, rowErr.Message, rowErr.Args...)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	if len(report.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
@@ -1,11 +1,36 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	"APIGolang/internal/auth"
+	"APIGolang/internal/config"
 	"APIGolang/internal/db"
+	"APIGolang/internal/health"
+	"APIGolang/internal/i18n"
+	"APIGolang/internal/logging"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/middleware"
+	"APIGolang/internal/repository"
 	"APIGolang/internal/routes"
+	"APIGolang/internal/tracing"
+	"APIGolang/internal/usecase"
+	"APIGolang/internal/validation"
+	"APIGolang/internal/worker"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	_ "APIGolang/swagger/v1"
 
@@ -22,24 +47,107 @@ import (
 // @name Authorization
 
 func main() {
-	
-	server := gin.Default()
+
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImport(os.Args[2:]))
+	}
+
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		os.Exit(0)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	logger := logging.New(cfg.Log, os.Stdout)
+	// Packages without an injected logger and the standard log package go through it too
+	slog.SetDefault(logger)
+	gin.DebugPrintFunc = func(format string, values ...any) {
+		logger.Debug(strings.TrimSpace(fmt.Sprintf(format, values...)), "component", "gin")
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, os.Stdout)
+	if err != nil {
+		logger.Error("failed to set up tracing", "error", err)
+		os.Exit(1)
+	}
+
+	appMetrics := metrics.New()
+
+	server := gin.New()
+	// Tracing comes first so the access log and everything below see the request span
+	server.Use(otelgin.Middleware(cfg.Tracing.ServiceName, otelgin.WithFilter(tracing.Traced)))
+	server.Use(middleware.RequestID(), middleware.Logger(logger), middleware.Metrics(appMetrics), middleware.Recovery(logger))
 
 	server.Use(cors.New(cors.Config{
-		AllowOrigins: []string{"http://localhost:5173"},
-		AllowMethods: []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders: []string{"Origin", "Content-Type", "Authorization"},
+		AllowOrigins:     cfg.Server.CORSOrigins,
+		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", middleware.RequestIDHeader, "traceparent", "tracestate", "baggage"},
+		ExposeHeaders:    []string{middleware.RequestIDHeader},
 		AllowCredentials: true,
 	}))
+	server.Use(middleware.Locale(), middleware.ErrorHandler(logger))
 
-	dbConnection, err := db.ConnectDB()
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		if err := i18n.RegisterValidator(v); err != nil {
+			panic(err)
+		}
+		if err := validation.Register(v); err != nil {
+			panic(err)
+		}
+	}
+
+	dbConnection, err := db.ConnectDB(cfg.Database)
 	if err != nil {
-		panic(err)
+		logger.Error("failed to connect to the database", "error", err)
+		os.Exit(1)
+	}
+
+	if err := appMetrics.RegisterDB(dbConnection, cfg.Database.Name); err != nil {
+		logger.Error("failed to register the database metrics", "error", err)
+		os.Exit(1)
 	}
 
-	routes.RegisterProductRoutes(server, dbConnection)
-	routes.RegisterAuthRoutes(server, dbConnection)
-	routes.RegisterUserRoutes(server, dbConnection)
+	var replicaConnection *sql.DB
+	if cfg.Database.ReplicaURL != "" {
+		replicaConnection, err = db.ConnectReplica(cfg.Database)
+		if err != nil {
+			logger.Error("failed to open the read replica", "error", err)
+			os.Exit(1)
+		}
+		if err := appMetrics.RegisterDB(replicaConnection, cfg.Database.Name+"_replica"); err != nil {
+			logger.Error("failed to register the database metrics", "error", err)
+			os.Exit(1)
+		}
+	}
+	// Listings and reports read from the replica while it is healthy and up to date
+	resolver := db.NewResolver(dbConnection, replicaConnection, cfg.Database.ReplicaMaxLag, cfg.Database.ReplicaCheckInterval)
+
+	tokens := auth.NewTokens(cfg.Auth)
+	timeouts := repository.NewTimeouts(cfg.Database)
+
+	routes.RegisterProductRoutes(server, dbConnection, resolver, timeouts, tokens, logger, appMetrics)
+	routes.RegisterAuthRoutes(server, dbConnection, timeouts, tokens, logger, appMetrics)
+	routes.RegisterUserRoutes(server, dbConnection, resolver, timeouts, tokens, logger, appMetrics)
+	routes.RegisterStockRoutes(server, dbConnection, resolver, timeouts, tokens, logger, appMetrics)
+	routes.RegisterPromotionRoutes(server, dbConnection, timeouts, tokens, logger, appMetrics)
+	routes.RegisterSaleRoutes(server, dbConnection, timeouts, tokens, logger, appMetrics)
+	routes.RegisterCategoryRoutes(server, dbConnection, timeouts, tokens, logger, appMetrics)
+	routes.RegisterReportRoutes(server, dbConnection, resolver, timeouts, tokens, logger, appMetrics)
+	routes.RegisterPurchaseRoutes(server, dbConnection, resolver, timeouts, tokens, logger, appMetrics)
+	routes.RegisterInventoryRoutes(server, dbConnection, resolver, timeouts, tokens, logger, appMetrics)
+	routes.RegisterAuditRoutes(server, dbConnection, resolver, timeouts, tokens, logger, appMetrics)
+
+	priceRepository := repository.NewPriceRepository(dbConnection, timeouts)
+	productRepository := repository.NewProductRepository(dbConnection, timeouts, logger)
+	ruleRepository := repository.NewPricingRuleRepository(dbConnection, timeouts)
+	priceScheduler := worker.NewPriceScheduler(usecase.NewPriceUseCase(priceRepository, productRepository, ruleRepository), time.Minute, logger)
+
+	readiness := &health.Readiness{}
+	routes.RegisterHealthRoutes(server, dbConnection, readiness, cfg.Server.HealthCheckTimeout)
+	routes.RegisterMetricsRoutes(server, appMetrics)
 
 	server.GET("/ping", func(ctx *gin.Context) {
 		ctx.JSON(200, gin.H{
@@ -49,5 +157,19 @@ func main() {
 
 	server.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	server.Run(":8000")
-}
\ No newline at end of file
+	err = serve(server, cfg.Server, readiness, logger, priceScheduler.Run, resolver.Run)
+	dbConnection.Close()
+	if replicaConnection != nil {
+		replicaConnection.Close()
+	}
+
+	flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if err := shutdownTracing(flushCtx); err != nil {
+		logger.Warn("failed to flush pending spans", "error", err)
+	}
+	cancel()
+	if err != nil {
+		logger.Error("server stopped", "error", err)
+		os.Exit(1)
+	}
+}
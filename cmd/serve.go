@@ -0,0 +1,93 @@
+package main
+
+import (
+	"APIGolang/internal/config"
+	"APIGolang/internal/health"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// backgroundTask is a loop that returns once ctx is cancelled
+type backgroundTask func(ctx context.Context)
+
+// serve runs handler and the workers until SIGINT or SIGTERM, then shuts down in order:
+// readiness goes false, new connections stop after ShutdownDelay, in-flight requests
+// drain within ShutdownTimeout and the workers are stopped. Closing shared resources such
+// as the database is left to the caller, after serve returns
+func serve(handler http.Handler, cfg config.Server, readiness *health.Readiness, logger *slog.Logger, workers ...backgroundTask) error {
+
+	httpServer := &http.Server{
+		Addr:              cfg.Addr(),
+		Handler:           handler,
+		ErrorLog:          slog.NewLogLogger(logger.Handler(), slog.LevelWarn),
+		ReadTimeout:       cfg.ReadTimeout,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+
+	signals, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	var running sync.WaitGroup
+	for _, run := range workers {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			run(workersCtx)
+		}()
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("listening", "addr", httpServer.Addr)
+		if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+	readiness.SetReady(true)
+
+	var err error
+	select {
+	case <-signals.Done():
+		logger.Info("shutdown signal received")
+		readiness.SetReady(false)
+		time.Sleep(cfg.ShutdownDelay)
+	case err = <-serverErr:
+		readiness.SetReady(false)
+	}
+	// A second signal during the drain falls back to the default behaviour and kills the process
+	stopSignals()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if shutdownErr := httpServer.Shutdown(ctx); shutdownErr != nil {
+		logger.Warn("requests still running at the shutdown deadline were interrupted", "timeout", cfg.ShutdownTimeout.String(), "error", shutdownErr)
+		httpServer.Close()
+	}
+
+	stopWorkers()
+	stopped := make(chan struct{})
+	go func() {
+		running.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		logger.Warn("background workers did not stop before the shutdown deadline")
+	}
+
+	logger.Info("shutdown complete")
+	return err
+}
@@ -0,0 +1,90 @@
+package analysis
+
+import "math"
+
+// ABC classes
+const (
+	ClassA = "A"
+	ClassB = "B"
+	ClassC = "C"
+)
+
+// Classification is the position of one product in the ABC curve
+// Share and CumulativeShare are percentages of the total revenue
+type Classification struct {
+	Share           float64
+	CumulativeShare float64
+	Class           string
+}
+
+// ClassifyABC classifies the revenues, which must be sorted in descending order.
+// Products are A while the cumulative share before them is below limitA, B while it is
+// below limitB and C afterwards, so the product crossing a limit stays in the upper class.
+// Products without revenue are always C
+func ClassifyABC(revenues []float64, limitA, limitB float64) []Classification {
+
+	total := 0.0
+	for _, revenue := range revenues {
+		if revenue > 0 {
+			total += revenue
+		}
+	}
+
+	result := make([]Classification, len(revenues))
+	cumulative := 0.0
+
+	for i, revenue := range revenues {
+		if total <= 0 || revenue <= 0 {
+			result[i] = Classification{CumulativeShare: round2(cumulative), Class: ClassC}
+			continue
+		}
+
+		class := ClassC
+		switch {
+		case cumulative < limitA:
+			class = ClassA
+		case cumulative < limitB:
+			class = ClassB
+		}
+
+		share := revenue / total * 100
+		cumulative += share
+
+		result[i] = Classification{
+			Share:           round2(share),
+			CumulativeShare: round2(cumulative),
+			Class:           class,
+		}
+	}
+
+	return result
+}
+
+// Coverage returns the average daily sales over the period and how many days the stock
+// lasts at that pace. The coverage is nil when nothing was sold in the period
+func Coverage(stock int, sold float64, days int) (float64, *float64) {
+
+	if days <= 0 || sold <= 0 {
+		return 0, nil
+	}
+
+	daily := sold / float64(days)
+	coverage := math.Round(float64(stock)/daily*10) / 10
+	return round2(daily), &coverage
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// SuggestPurchase returns how many units to buy so the stock covers the minimum plus
+// coverageDays of sales at the daily pace, discounting what is already on order
+func SuggestPurchase(stock, minimum, pending int, daily float64, coverageDays int) int {
+
+	target := float64(minimum) + daily*float64(coverageDays)
+	missing := target - float64(stock) - float64(pending)
+	if missing <= 0 {
+		return 0
+	}
+	return int(math.Ceil(missing - 1e-9))
+}
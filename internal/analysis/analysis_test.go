@@ -0,0 +1,63 @@
+package analysis
+
+import "testing"
+
+func TestClassifyABC(t *testing.T) {
+	revenues := []float64{500, 300, 100, 60, 40, 0}
+	want := []string{ClassA, ClassA, ClassB, ClassB, ClassC, ClassC}
+
+	result := ClassifyABC(revenues, 80, 95)
+
+	for i, classification := range result {
+		if classification.Class != want[i] {
+			t.Errorf("item %d: class %s, want %s", i, classification.Class, want[i])
+		}
+	}
+	if result[0].Share != 50 || result[1].CumulativeShare != 80 {
+		t.Errorf("unexpected shares: %+v", result[:2])
+	}
+	if result[4].CumulativeShare != 100 || result[5].Share != 0 {
+		t.Errorf("unexpected tail: %+v", result[4:])
+	}
+}
+
+func TestClassifyABCWithoutRevenue(t *testing.T) {
+	for _, classification := range ClassifyABC([]float64{0, 0}, 80, 95) {
+		if classification.Class != ClassC {
+			t.Errorf("class %s, want C", classification.Class)
+		}
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	daily, coverage := Coverage(45, 90, 30)
+	if daily != 3 || coverage == nil || *coverage != 15 {
+		t.Errorf("Coverage(45, 90, 30) = %v, %v", daily, coverage)
+	}
+
+	if _, coverage := Coverage(10, 0, 30); coverage != nil {
+		t.Errorf("expected nil coverage without sales")
+	}
+}
+
+func TestSuggestPurchase(t *testing.T) {
+	tests := []struct {
+		stock, minimum, pending int
+		daily                   float64
+		coverage                int
+		want                    int
+	}{
+		{stock: 5, minimum: 10, daily: 2, coverage: 15, want: 35},
+		{stock: 5, minimum: 10, pending: 20, daily: 2, coverage: 15, want: 15},
+		{stock: 50, minimum: 10, daily: 1, coverage: 15, want: 0},
+		{stock: 0, minimum: 0, daily: 0.3, coverage: 10, want: 3},
+		{stock: -4, minimum: 2, daily: 0, coverage: 15, want: 6},
+	}
+
+	for _, tt := range tests {
+		got := SuggestPurchase(tt.stock, tt.minimum, tt.pending, tt.daily, tt.coverage)
+		if got != tt.want {
+			t.Errorf("SuggestPurchase(%+v) = %d, want %d", tt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Package apperror defines the domain errors returned by usecases and repositories.
+// The kind decides the HTTP status and the code is a stable identifier clients can rely on
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindValidation
+	KindNotFound
+	KindConflict
+	KindForbidden
+	KindUnauthorized
+)
+
+// Error is a domain error, its message is safe to show to the user.
+// Message is a pt-BR format string, kept apart from Args so it can be translated
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Args    []any
+}
+
+func (e *Error) Error() string {
+	if len(e.Args) == 0 {
+		return e.Message
+	}
+	return fmt.Sprintf(e.Message, e.Args...)
+}
+
+// Is matches any error of the same kind and code, so errors.Is still recognizes
+// a sentinel after With
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// With returns a copy of the error with a more specific message
+func (e *Error) With(message string, args ...any) *Error {
+	return &Error{Kind: e.Kind, Code: e.Code, Message: message, Args: args}
+}
+
+func Validation(code, message string, args ...any) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Args: args}
+}
+
+func NotFound(code, message string, args ...any) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message, Args: args}
+}
+
+func Conflict(code, message string, args ...any) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message, Args: args}
+}
+
+func Forbidden(code, message string, args ...any) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message, Args: args}
+}
+
+func Unauthorized(code, message string, args ...any) *Error {
+	return &Error{Kind: KindUnauthorized, Code: code, Message: message, Args: args}
+}
+
+// As returns the domain error wrapped by err, if any
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
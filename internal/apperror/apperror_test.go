@@ -0,0 +1,44 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestAsWrappedError(t *testing.T) {
+	sentinel := Conflict("insufficient_stock", "Estoque insuficiente")
+	err := fmt.Errorf("%w para o produto Café", sentinel)
+
+	appErr, ok := As(err)
+	if !ok {
+		t.Fatal("expected a domain error")
+	}
+	if appErr.Kind != KindConflict || appErr.Code != "insufficient_stock" {
+		t.Errorf("unexpected error: %+v", appErr)
+	}
+	if !errors.Is(err, sentinel) {
+		t.Error("errors.Is must match the sentinel")
+	}
+}
+
+func TestAsPlainError(t *testing.T) {
+	if _, ok := As(errors.New("connection refused")); ok {
+		t.Error("plain errors are not domain errors")
+	}
+}
+
+func TestWithKeepsIdentity(t *testing.T) {
+	sentinel := Conflict("insufficient_stock", "Estoque insuficiente")
+	err := sentinel.With("Estoque insuficiente para o produto %s", "Café")
+
+	if err.Error() != "Estoque insuficiente para o produto Café" {
+		t.Errorf("unexpected message: %s", err.Error())
+	}
+	if !errors.Is(err, sentinel) {
+		t.Error("errors.Is must match the sentinel after With")
+	}
+	if errors.Is(err, Conflict("lot_insufficient", "Estoque insuficiente")) {
+		t.Error("errors with other codes must not match")
+	}
+}
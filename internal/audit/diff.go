@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the value of sensitive fields in a diff, so the log shows they changed
+// without storing them
+const Redacted = "***"
+
+var sensitiveFields = []string{"password", "senha", "token", "secret"}
+
+// Change is the value of one field before and after the operation
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Diff compares the JSON representation of before and after and returns the changed fields.
+// Either side may be nil: a create has no before and a delete has no after
+func Diff(before, after any) (map[string]Change, error) {
+
+	old, err := toMap(before)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := toMap(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]Change)
+	for field, value := range old {
+		if newValue, ok := updated[field]; !ok || !reflect.DeepEqual(value, newValue) {
+			changes[field] = change(field, value, updated[field])
+		}
+	}
+	for field, value := range updated {
+		if _, ok := old[field]; !ok {
+			changes[field] = change(field, nil, value)
+		}
+	}
+
+	return changes, nil
+}
+
+func change(field string, before, after any) Change {
+	if isSensitive(field) {
+		if before != nil {
+			before = Redacted
+		}
+		if after != nil {
+			after = Redacted
+		}
+	}
+	return Change{Before: before, After: after}
+}
+
+func isSensitive(field string) bool {
+	field = strings.ToLower(field)
+	for _, sensitive := range sensitiveFields {
+		if strings.Contains(field, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+func toMap(value any) (map[string]any, error) {
+
+	result := make(map[string]any)
+	if value == nil || (reflect.ValueOf(value).Kind() == reflect.Pointer && reflect.ValueOf(value).IsNil()) {
+		return result, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
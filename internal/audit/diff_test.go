@@ -0,0 +1,50 @@
+package audit
+
+import "testing"
+
+type sample struct {
+	Name     string   `json:"name"`
+	Price    *float64 `json:"price"`
+	Password string   `json:"password,omitempty"`
+}
+
+func TestDiffUpdate(t *testing.T) {
+	oldPrice, newPrice := 10.0, 12.5
+	before := sample{Name: "Café", Price: &oldPrice, Password: "a"}
+	after := &sample{Name: "Café", Price: &newPrice, Password: "b"}
+
+	changes, err := Diff(before, after)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("expected 2 changes, got %v", changes)
+	}
+	if changes["price"].Before != 10.0 || changes["price"].After != 12.5 {
+		t.Errorf("unexpected price change: %+v", changes["price"])
+	}
+	if changes["password"].Before != Redacted || changes["password"].After != Redacted {
+		t.Errorf("password must be redacted: %+v", changes["password"])
+	}
+}
+
+func TestDiffCreateAndDelete(t *testing.T) {
+	var missing *sample
+
+	created, err := Diff(missing, sample{Name: "Pão"})
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if created["name"].Before != nil || created["name"].After != "Pão" {
+		t.Errorf("unexpected create diff: %+v", created)
+	}
+
+	deleted, err := Diff(sample{Name: "Pão"}, nil)
+	if err != nil {
+		t.Fatalf("Diff: %v", err)
+	}
+	if deleted["name"].Before != "Pão" || deleted["name"].After != nil {
+		t.Errorf("unexpected delete diff: %+v", deleted)
+	}
+}
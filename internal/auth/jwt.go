@@ -1,20 +1,31 @@
 package auth
 
 import (
-	"os"
+	"APIGolang/internal/config"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var secretKey = []byte(os.Getenv("JWT_SECRET"))
-
-func GenerateToken(userId int, username, email, perfil, role string, active bool) (string, error) {
+// Tokens signs and validates the JWTs with the secret and lifetimes from the configuration
+type Tokens struct {
+	secretKey   []byte
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+	rememberTTL time.Duration
+}
 
-	if len(secretKey) == 0 {
-		panic("erro token jwt")
+func NewTokens(cfg config.Auth) *Tokens {
+	return &Tokens{
+		secretKey:   []byte(cfg.JWTSecret),
+		accessTTL:   cfg.AccessTokenTTL,
+		refreshTTL:  cfg.RefreshTokenTTL,
+		rememberTTL: cfg.RememberTTL,
+	}
 }
 
+func (t *Tokens) GenerateToken(userId int, username, email, perfil, role string, active bool) (string, error) {
+
 	claims := jwt.MapClaims{
 		"userId": userId,
 		"username": username,
@@ -22,32 +33,37 @@ func GenerateToken(userId int, username, email, perfil, role string, active bool
 		"profile": perfil,
 		"role": role,
 		"active": active,
-		"exp":     time.Now().Add(15 * time.Minute).Unix(),
+		"exp":     time.Now().Add(t.accessTTL).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	return token.SignedString(secretKey)
+	return token.SignedString(t.secretKey)
 }
 
-func GenerateRefreshToken(userId int) (string, error) {
+func (t *Tokens) GenerateRefreshToken(userId int) (string, error) {
 	
 	claims := jwt.MapClaims{
 		"id": userId,
-		"exp": time.Now().Add(2 * time.Hour).Unix(),
+		"exp": time.Now().Add(t.refreshTTL).Unix(),
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(secretKey)
+	return token.SignedString(t.secretKey)
 }
 
-func ValidateToken(tokenString string) (*jwt.Token, error) {
+// RememberMaxAge is the max age in seconds of the refresh token cookie of remembered users
+func (t *Tokens) RememberMaxAge() int {
+	return int(t.rememberTTL.Seconds())
+}
+
+func (t *Tokens) ValidateToken(tokenString string) (*jwt.Token, error) {
 
 	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error){
 
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, jwt.ErrSignatureInvalid	
 		}
-		return secretKey, nil
+		return t.secretKey, nil
 	})
-}
\ No newline at end of file
+}
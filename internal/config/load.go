@@ -0,0 +1,307 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// setting binds one field of Config to its key in the config file, its environment
+// variable and, optionally, its command line flag. Secrets have no flag so they never
+// show up in the process list
+type setting struct {
+	key   string
+	env   string
+	flag  string
+	usage string
+	set   func(c *Config, value string) error
+}
+
+var settings = []setting{
+	{"server.port", "PORT", "port", "porta HTTP", setInt(func(c *Config) *int { return &c.Server.Port })},
+	{"server.cors_origins", "CORS_ORIGINS", "cors-origins", "origens permitidas, separadas por vírgula", setList(func(c *Config) *[]string { return &c.Server.CORSOrigins })},
+	{"server.read_timeout", "HTTP_READ_TIMEOUT", "read-timeout", "tempo máximo para ler a requisição", setDuration(func(c *Config) *time.Duration { return &c.Server.ReadTimeout })},
+	{"server.read_header_timeout", "HTTP_READ_HEADER_TIMEOUT", "read-header-timeout", "tempo máximo para ler os headers", setDuration(func(c *Config) *time.Duration { return &c.Server.ReadHeaderTimeout })},
+	{"server.write_timeout", "HTTP_WRITE_TIMEOUT", "write-timeout", "tempo máximo para escrever a resposta", setDuration(func(c *Config) *time.Duration { return &c.Server.WriteTimeout })},
+	{"server.idle_timeout", "HTTP_IDLE_TIMEOUT", "idle-timeout", "tempo máximo de conexões keep-alive ociosas", setDuration(func(c *Config) *time.Duration { return &c.Server.IdleTimeout })},
+	{"server.shutdown_delay", "SHUTDOWN_DELAY", "shutdown-delay", "tempo respondendo não pronto antes de parar de aceitar conexões", setDuration(func(c *Config) *time.Duration { return &c.Server.ShutdownDelay })},
+	{"server.shutdown_timeout", "SHUTDOWN_TIMEOUT", "shutdown-timeout", "prazo para concluir as requisições em andamento", setDuration(func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout })},
+	{"server.health_check_timeout", "HEALTH_CHECK_TIMEOUT", "health-check-timeout", "prazo das verificações do /readyz", setDuration(func(c *Config) *time.Duration { return &c.Server.HealthCheckTimeout })},
+	{"database.url", "DB_URL", "", "", setString(func(c *Config) *string { return &c.Database.URL })},
+	{"database.host", "DB_HOST", "db-host", "host do PostgreSQL", setString(func(c *Config) *string { return &c.Database.Host })},
+	{"database.port", "DB_PORT", "db-port", "porta do PostgreSQL", setInt(func(c *Config) *int { return &c.Database.Port })},
+	{"database.user", "DB_USER", "db-user", "usuário do PostgreSQL", setString(func(c *Config) *string { return &c.Database.User })},
+	{"database.password", "DB_PASSWORD", "", "", setString(func(c *Config) *string { return &c.Database.Password })},
+	{"database.name", "DB_NAME", "db-name", "nome do banco", setString(func(c *Config) *string { return &c.Database.Name })},
+	{"database.sslmode", "DB_SSLMODE", "db-sslmode", "modo TLS do PostgreSQL: disable, require, verify-ca ou verify-full", setString(func(c *Config) *string { return &c.Database.SSLMode })},
+	{"database.sslrootcert", "DB_SSLROOTCERT", "db-sslrootcert", "arquivo da CA que assina o certificado do servidor", setString(func(c *Config) *string { return &c.Database.SSLRootCert })},
+	{"database.sslcert", "DB_SSLCERT", "db-sslcert", "arquivo do certificado do cliente", setString(func(c *Config) *string { return &c.Database.SSLCert })},
+	{"database.sslkey", "DB_SSLKEY", "db-sslkey", "arquivo da chave do certificado do cliente", setString(func(c *Config) *string { return &c.Database.SSLKey })},
+	{"database.max_open_conns", "DB_MAX_OPEN_CONNS", "db-max-open-conns", "máximo de conexões abertas, 0 para ilimitado", setInt(func(c *Config) *int { return &c.Database.MaxOpenConns })},
+	{"database.max_idle_conns", "DB_MAX_IDLE_CONNS", "db-max-idle-conns", "máximo de conexões ociosas mantidas", setInt(func(c *Config) *int { return &c.Database.MaxIdleConns })},
+	{"database.conn_max_lifetime", "DB_CONN_MAX_LIFETIME", "db-conn-max-lifetime", "tempo máximo de uso de uma conexão", setDuration(func(c *Config) *time.Duration { return &c.Database.ConnMaxLifetime })},
+	{"database.conn_max_idle_time", "DB_CONN_MAX_IDLE_TIME", "db-conn-max-idle-time", "tempo máximo de uma conexão ociosa", setDuration(func(c *Config) *time.Duration { return &c.Database.ConnMaxIdleTime })},
+	{"database.replica_url", "DB_REPLICA_URL", "", "", setString(func(c *Config) *string { return &c.Database.ReplicaURL })},
+	{"database.replica_max_lag", "DB_REPLICA_MAX_LAG", "db-replica-max-lag", "atraso máximo da réplica antes de voltar a ler do primário", setDuration(func(c *Config) *time.Duration { return &c.Database.ReplicaMaxLag })},
+	{"database.replica_check_interval", "DB_REPLICA_CHECK_INTERVAL", "db-replica-check-interval", "intervalo entre as verificações da réplica", setDuration(func(c *Config) *time.Duration { return &c.Database.ReplicaCheckInterval })},
+	{"database.query_timeout", "DB_QUERY_TIMEOUT", "db-query-timeout", "prazo de cada consulta ou transação", setDuration(func(c *Config) *time.Duration { return &c.Database.QueryTimeout })},
+	{"database.report_timeout", "DB_REPORT_TIMEOUT", "db-report-timeout", "prazo das consultas de relatórios e exportações", setDuration(func(c *Config) *time.Duration { return &c.Database.ReportTimeout })},
+	{"auth.jwt_secret", "JWT_SECRET", "", "", setString(func(c *Config) *string { return &c.Auth.JWTSecret })},
+	{"auth.access_token_ttl", "ACCESS_TOKEN_TTL", "access-token-ttl", "validade do token de acesso, ex.: 15m", setDuration(func(c *Config) *time.Duration { return &c.Auth.AccessTokenTTL })},
+	{"auth.refresh_token_ttl", "REFRESH_TOKEN_TTL", "refresh-token-ttl", "validade do refresh token, ex.: 2h", setDuration(func(c *Config) *time.Duration { return &c.Auth.RefreshTokenTTL })},
+	{"auth.remember_ttl", "REMEMBER_TTL", "remember-ttl", "validade do cookie com \"lembrar de mim\", ex.: 1h", setDuration(func(c *Config) *time.Duration { return &c.Auth.RememberTTL })},
+	{"log.level", "LOG_LEVEL", "log-level", "nível de log: debug, info, warn ou error", setLevel(func(c *Config) *slog.Level { return &c.Log.Level })},
+	{"log.format", "LOG_FORMAT", "log-format", "formato do log: json ou text", setString(func(c *Config) *string { return &c.Log.Format })},
+	{"tracing.exporter", "OTEL_TRACES_EXPORTER", "traces-exporter", "destino dos traces: none, console ou otlp", setString(func(c *Config) *string { return &c.Tracing.Exporter })},
+	{"tracing.service_name", "OTEL_SERVICE_NAME", "service-name", "nome do serviço nos traces", setString(func(c *Config) *string { return &c.Tracing.ServiceName })},
+	{"tracing.sample_ratio", "TRACE_SAMPLE_RATIO", "trace-sample-ratio", "fração dos traces registrados, de 0 a 1", setFloat(func(c *Config) *float64 { return &c.Tracing.SampleRatio })},
+}
+
+// Load builds the configuration from, in increasing precedence: defaults, the file informed
+// by -config or CONFIG_FILE (.yaml, .yml or .toml), environment variables and flags.
+// Every environment variable also accepts a NAME_FILE variant holding the path of a file
+// with the value, as mounted by Docker secrets. The result is validated before returning
+func Load(args []string) (*Config, error) {
+
+	flags := flag.NewFlagSet("config", flag.ContinueOnError)
+	configFile := flags.String("config", "", "arquivo de configuração YAML ou TOML")
+	flagKeys := make(map[string]string)
+	for _, s := range settings {
+		if s.flag != "" {
+			flags.String(s.flag, "", s.usage)
+			flagKeys[s.flag] = s.key
+		}
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	cfg := Default()
+
+	path := *configFile
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := apply(&cfg, values, path); err != nil {
+			return nil, err
+		}
+	}
+
+	values, err := environment()
+	if err != nil {
+		return nil, err
+	}
+	if err := apply(&cfg, values, "environment"); err != nil {
+		return nil, err
+	}
+
+	// Only flags actually passed override, so their empty defaults don't erase other sources
+	values = make(map[string]string)
+	flags.Visit(func(f *flag.Flag) {
+		if key, ok := flagKeys[f.Name]; ok {
+			values[key] = f.Value.String()
+		}
+	})
+	if err := apply(&cfg, values, "flags"); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Database.applyURL(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%s: %w", settingName("database.url"), err)
+	}
+	if _, err := cfg.Database.Replica(); err != nil {
+		return nil, fmt.Errorf("invalid configuration:\n%s: %w", settingName("database.replica_url"), err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// environment reads every setting from NAME or from the file named by NAME_FILE
+func environment() (map[string]string, error) {
+
+	values := make(map[string]string)
+	for _, s := range settings {
+		value, hasValue := os.LookupEnv(s.env)
+		path, hasFile := os.LookupEnv(s.env + "_FILE")
+
+		switch {
+		case hasValue && hasFile:
+			return nil, fmt.Errorf("%s and %s_FILE are both set, use only one", s.env, s.env)
+		case hasFile:
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("%s_FILE: %w", s.env, err)
+			}
+			values[s.key] = strings.TrimSpace(string(content))
+		case hasValue:
+			values[s.key] = value
+		}
+	}
+	return values, nil
+}
+
+// readFile decodes the config file into the same flat keys used by settings,
+// e.g. the "host" entry of the "database" section becomes "database.host"
+func readFile(path string) (map[string]string, error) {
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("config file: %w", err)
+	}
+
+	var tree map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &tree)
+	case ".toml":
+		err = toml.Unmarshal(content, &tree)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", tree, values)
+	return values, nil
+}
+
+func flatten(prefix string, tree map[string]any, values map[string]string) {
+	for name, value := range tree {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(key, v, values)
+		case []any:
+			items := make([]string, len(v))
+			for i, item := range v {
+				items[i] = fmt.Sprint(item)
+			}
+			values[key] = strings.Join(items, ",")
+		default:
+			values[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+// apply sets every value on cfg, failing on unknown keys so typos in the file are not ignored
+func apply(cfg *Config, values map[string]string, source string) error {
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		s := settingByKey(key)
+		if s == nil {
+			return fmt.Errorf("%s: unknown setting %q", source, key)
+		}
+		if err := s.set(cfg, values[key]); err != nil {
+			return fmt.Errorf("%s: %s: %w", source, settingName(key), err)
+		}
+	}
+	return nil
+}
+
+func settingByKey(key string) *setting {
+	for i := range settings {
+		if settings[i].key == key {
+			return &settings[i]
+		}
+	}
+	return nil
+}
+
+// settingName identifies a setting in error messages by its variable and file key
+func settingName(key string) string {
+	if s := settingByKey(key); s != nil {
+		return fmt.Sprintf("%s (%s)", s.env, s.key)
+	}
+	return key
+}
+
+func setString(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func setInt(field func(*Config) *int) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func setFloat(field func(*Config) *float64) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+func setDuration(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not a duration like 15m or 2h", value)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func setLevel(field func(*Config) *slog.Level) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		if err := field(c).UnmarshalText([]byte(strings.TrimSpace(value))); err != nil {
+			return fmt.Errorf("%q is not a level like debug, info, warn or error", value)
+		}
+		return nil
+	}
+}
+
+func setList(field func(*Config) *[]string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*field(c) = items
+		return nil
+	}
+}
@@ -0,0 +1,84 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type auditController struct {
+	auditUsecase usecase.AuditUseCase
+}
+
+func NewAuditController(usecase usecase.AuditUseCase) auditController {
+	return auditController{
+		auditUsecase: usecase,
+	}
+}
+
+// GetAuditEntries godoc
+// @Summary Consultar log de auditoria
+// @Description Alterações de usuários, produtos e senhas com autor, IP, user agent e o diff antes/depois. Restrito a administradores
+// @Tags Audit
+// @Produce json
+// @Security BearerAuth
+// @Param entity query string false "usuario ou produto"
+// @Param entity_id query string false "ID da entidade"
+// @Param user_id query int false "ID do usuário que fez a alteração"
+// @Param action query string false "CRIACAO, ALTERACAO, EXCLUSAO ou NEGACAO"
+// @Param from query string false "Data inicial (AAAA-MM-DD), padrão 30 dias atrás"
+// @Param to query string false "Data final inclusiva (AAAA-MM-DD), padrão hoje"
+// @Param limit query int false "Quantidade de registros" default(50)
+// @Param offset query int false "Registros a pular" default(0)
+// @Success 200 {array} model.AuditEntry
+// @Failure 400 {object} model.Problem
+// @Failure 403 {object} model.Problem
+// @Router /audit [get]
+func (a *auditController) GetAuditEntries(ctx *gin.Context) {
+
+	from, to, ok := reportPeriod(ctx)
+	if !ok {
+		return
+	}
+	filter := model.AuditFilter{From: from, To: to.AddDate(0, 0, 1)}
+
+	if value := ctx.Query("entity"); value != "" {
+		filter.Entity = &value
+	}
+	if value := ctx.Query("entity_id"); value != "" {
+		filter.EntityId = &value
+	}
+	if value := ctx.Query("action"); value != "" {
+		filter.Action = &value
+	}
+	if value := ctx.Query("user_id"); value != "" {
+		userId, err := strconv.Atoi(value)
+		if err != nil {
+			ctx.Error(apperror.Validation("invalid_id", "Id do usuário precisa ser um número"))
+			return
+		}
+		filter.UserId = &userId
+	}
+
+	var err error
+	if filter.Limit, err = strconv.Atoi(ctx.DefaultQuery("limit", "0")); err != nil {
+		ctx.Error(apperror.Validation("invalid_limit", "O limite precisa ser um número"))
+		return
+	}
+	if filter.Offset, err = strconv.Atoi(ctx.DefaultQuery("offset", "0")); err != nil {
+		ctx.Error(apperror.Validation("invalid_offset", "O deslocamento precisa ser um número"))
+		return
+	}
+
+	entries, err := a.auditUsecase.GetEntries(ctx.Request.Context(), filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
@@ -1,68 +1,71 @@
 package controller
 
 import (
+	"APIGolang/internal/apperror"
 	"APIGolang/internal/auth"
 	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
 	"APIGolang/internal/usecase"
+	"errors"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
+// errInvalidRefreshToken is reported for expired, tampered or malformed refresh tokens
+var errInvalidRefreshToken = apperror.Unauthorized("refresh_token_invalid", "refresh token inválido")
+
 type AuthController struct {
 	authUsecase *usecase.AuthUseCase
 	userUsecase *usecase.UserUseCase
+	tokens      *auth.Tokens
 }
 
-func NewAuthController(authUc *usecase.AuthUseCase, userUc *usecase.UserUseCase) *AuthController {
-	return &AuthController{authUsecase: authUc, userUsecase: userUc}
+func NewAuthController(authUc *usecase.AuthUseCase, userUc *usecase.UserUseCase, tokens *auth.Tokens) *AuthController {
+	return &AuthController{authUsecase: authUc, userUsecase: userUc, tokens: tokens}
 }
 
 // Login godoc
 // @Summary Autenticar usuário
-// @Description Realiza login e retorna o token
+// @Description Realiza login e retorna o token. Após 5 senhas erradas o login fica bloqueado por 15 minutos
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param credentials body model.TokenRequest true "Credenciais do usuário"
 // @Success 200 {object} map[string]string
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} model.Problem
+// @Failure 401 {object} model.Problem
+// @Failure 403 {object} model.Problem
 // @Router /auth/login [post]
 func (authCtrl *AuthController) Login(c *gin.Context) {
 
 	var req model.TokenRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-		if req.Remember == nil {
-			c.JSON(400,gin.H{"error": "informe a tag remember"})
-			return
-		}
-		c.JSON(400,gin.H{"error": "dados inválidos"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	user, err := authCtrl.authUsecase.Login(req.Username, req.Password)
+	user, err := authCtrl.authUsecase.Login(c.Request.Context(), req.Username, req.Password)
 	if err != nil {
-		c.JSON(401, gin.H{"error": "credenciais inválidas"})
+		c.Error(err)
 		return
 	}
 
-	accessToken, err := auth.GenerateToken(user.Id, user.Username, user.Email, user.Profile, user.Role, user.Active)
+	accessToken, err := authCtrl.tokens.GenerateToken(user.Id, user.Username, user.Email, user.Profile, user.Role, user.Active)
 	if err != nil {
-		c.JSON(500, gin.H{"error": "erro ao gerar access token"})
+		c.Error(err)
 		return
 	}
 
-	refreshToken, err := auth.GenerateRefreshToken(user.Id)
+	refreshToken, err := authCtrl.tokens.GenerateRefreshToken(user.Id)
 	if err != nil {
-		c.JSON(500, gin.H{"error": "erro ao gerar refresh token"})
+		c.Error(err)
 		return
 	}
 
 	maxAge := 0
 	if req.Remember != nil && *req.Remember {
-		maxAge = 60 * 60
+		maxAge = authCtrl.tokens.RememberMaxAge()
 	}
 	c.SetCookie(
 		"refresh_token",
@@ -89,35 +92,47 @@ func (authCtrl *AuthController) Login(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Success 201 {object} map[string]string
-// @Failure 400 {object} map[string]interface{}
+// @Failure 400 {object} model.Problem
 // @Router /auth/refresh [post]
 func (authCtrl *AuthController) Refresh(c *gin.Context) {
 	
 	refreshToken, err := c.Cookie("refresh_token")
 	if err != nil {
-		c.JSON(401, gin.H{"error": "refresh token não encontrado"})
+		c.Error(apperror.Unauthorized("refresh_token_missing", "refresh token não encontrado"))
 		return
 	}
 
-	token, err := auth.ValidateToken(refreshToken)
+	token, err := authCtrl.tokens.ValidateToken(refreshToken)
 	if err != nil || !token.Valid {
-		c.JSON(401, gin.H{"error": "refresh token inválido"})
+		c.Error(errInvalidRefreshToken)
 		return
 	}
 
-	claims := token.Claims.(jwt.MapClaims)
-	idFloat := claims["id"].(float64)
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		c.Error(errInvalidRefreshToken)
+		return
+	}
+	idFloat, ok := claims["id"].(float64)
+	if !ok {
+		c.Error(errInvalidRefreshToken)
+		return
+	}
 	userId := int(idFloat)
 
-	user, err := authCtrl.userUsecase.GetUserById(userId)
+	user, err := authCtrl.userUsecase.GetUserById(c.Request.Context(), userId)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		c.Error(apperror.Unauthorized("user_not_found", "usuário não encontrado"))
+		return
+	}
 	if err != nil {
-		c.JSON(401, gin.H{"error": "usuário não encontrado"})
+		c.Error(err)
 		return
 	}
 
-	newAccessToken, err := auth.GenerateToken(user.Id, user.Username, user.Email, user.Profile, user.Role, user.Active)
+	newAccessToken, err := authCtrl.tokens.GenerateToken(user.Id, user.Username, user.Email, user.Profile, user.Role, user.Active)
 	if err != nil {
-		c.JSON(500, gin.H{"error": "erro ao gerar access token"})
+		c.Error(err)
 		return
 	}
 	
@@ -131,78 +146,36 @@ func (authCtrl *AuthController) Refresh(c *gin.Context) {
 
 // AlterPassword godoc
 // @Summary Alterar senha
-// @Description Altera a senha de um usuário
+// @Description Altera a senha de um usuário, que precisa informar a senha atual
 // @Tags Auth
 // @Accept json
 // @Produce json
-// @Success 201 {object} map[string]string
-// @Failure 400 {object} map[string]interface{}
-// @Router /auth/alterpassword [post]
+// @Param request body model.ChangePassword true "Email, senha atual e nova senha"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} model.Problem
+// @Failure 401 {object} model.Problem
+// @Failure 403 {object} model.Problem
+// @Router /auth/changePassword [post]
 func (authCtrl *AuthController) ChangePassword(c *gin.Context) {
 
 	var req model.ChangePassword
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(401, gin.H{"error": "É necessário preencher todas as informações"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	isUpdated, err := authCtrl.authUsecase.ChangePassword(req)
+	isUpdated, err := authCtrl.authUsecase.ChangePassword(c.Request.Context(), req, currentActor(c))
 	if err != nil {
-		c.JSON(400, gin.H{"error": err.Error()})
+		c.Error(err)
 		return
 	}
 
 	if !isUpdated {
-		c.JSON(404, gin.H{"error": "Usuário não encontrado"})
+		c.Error(repository.ErrUserNotFound)
 		return
 	}
 
 	c.JSON(200, gin.H{
-		"message": "Senha alterada com sucesso",
+		"message": translate(c, "Senha alterada com sucesso"),
 	})
 
 }
-// 	var req model.CreateUserRequest
-
-// 	if err := c.ShouldBindJSON(&req); err != nil {
-
-// 		validationErrors, ok := err.(validator.ValidationErrors)
-// 		if ok {
-// 			errors := make(map[string]string)
-
-// 			for _, filedErr := range validationErrors {
-// 				switch filedErr.Field() {
-// 				case "Nome":
-// 					errors["name"] = "Nome é obrigatório"
-// 				case "NomeUsuario":
-// 					errors["username"] = "Nome de usuário é obrigatório"
-// 				case "Email":
-// 					errors["email"] = "Email é obrigatório"
-// 				case "Senha":
-// 					errors["password"] = "Senha é obrigatório"
-// 				}
-// 			}
-
-// 			c.JSON(400, gin.H{
-// 				"errors": err.Error() + " aqui ",
-// 			})
-// 			return
-// 		}
-// 		c.JSON(400, gin.H{"error": "Dados inválidos"})
-// 		return
-// 	}
-
-// 	err := authCtrl.usecase.CreateUser(req)
-// 	if err != nil {
-// 		c.JSON(400, gin.H{
-// 			"error": err.Error(),
-// 		})
-// 		return
-// 	}
-
-// 	c.JSON(201, gin.H{
-// 		"message": "Usuário criado com sucesso",
-// 	})
-// }
-
-
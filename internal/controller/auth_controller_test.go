@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"APIGolang/internal/i18n"
+	"APIGolang/internal/middleware"
+	"APIGolang/internal/model"
+	"APIGolang/internal/validation"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var registerValidator sync.Once
+
+// newTestServer returns an engine with the middleware and validation rules set up by main
+func newTestServer(t *testing.T) *gin.Engine {
+	t.Helper()
+
+	registerValidator.Do(func() {
+		v := binding.Validator.Engine().(*validator.Validate)
+		if err := i18n.RegisterValidator(v); err != nil {
+			t.Fatalf("RegisterValidator: %v", err)
+		}
+		if err := validation.Register(v); err != nil {
+			t.Fatalf("Register: %v", err)
+		}
+	})
+
+	gin.SetMode(gin.TestMode)
+	server := gin.New()
+	server.Use(middleware.Locale(), middleware.ErrorHandler(slog.Default()))
+	return server
+}
+
+func TestChangePasswordRequiresTheCurrentPassword(t *testing.T) {
+	server := newTestServer(t)
+	// The body is rejected before the usecase is reached
+	server.POST("/auth/changePassword", NewAuthController(nil, nil, nil).ChangePassword)
+
+	body := `{"email": "maria@mercado.com", "new_password": "nova12345"}`
+	request := httptest.NewRequest(http.MethodPost, "/auth/changePassword", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d", response.Code, http.StatusBadRequest)
+	}
+	var problem model.Problem
+	if err := json.Unmarshal(response.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("invalid problem: %v", err)
+	}
+	if _, ok := problem.Errors["current_password"]; !ok || len(problem.Errors) != 1 {
+		t.Errorf("expected only current_password reported, got %v", problem.Errors)
+	}
+}
@@ -0,0 +1,62 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/i18n"
+	"APIGolang/internal/model"
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// errInvalidBody is reported when the request body cannot be decoded
+var errInvalidBody = apperror.Validation("invalid_body", "Dados inválidos")
+
+// bindJSON decodes the body into obj and checks its binding tags
+// Field errors reach middleware.ErrorHandler as they are, which answers with one message per field
+func bindJSON(ctx *gin.Context, obj any) bool {
+	err := ctx.ShouldBindJSON(obj)
+	if err == nil {
+		return true
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		ctx.Error(validationErrs)
+	} else {
+		ctx.Error(errInvalidBody)
+	}
+	return false
+}
+
+// translate returns message in the locale negotiated by middleware.Locale
+func translate(ctx *gin.Context, message string, args ...any) string {
+	return i18n.T(ctx.GetString(i18n.ContextKey), message, args...)
+}
+
+// currentUserId returns the id of the authenticated user set by middleware.JWTAuth
+func currentUserId(ctx *gin.Context) *int {
+	value, exists := ctx.Get("userId")
+	if !exists {
+		return nil
+	}
+	id, ok := value.(int)
+	if !ok {
+		return nil
+	}
+	return &id
+}
+
+// currentActor identifies the author of a mutating request for the audit log
+func currentActor(ctx *gin.Context) model.Actor {
+	actor := model.Actor{
+		UserId:    currentUserId(ctx),
+		IP:        ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+	if username := ctx.GetString("username"); username != "" {
+		actor.Username = &username
+	}
+	return actor
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"APIGolang/internal/model"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBindJSONValidatesTheItems(t *testing.T) {
+	server := newTestServer(t)
+	server.POST("/sales", func(c *gin.Context) {
+		var req model.SaleRequest
+		if bindJSON(c, &req) {
+			c.Status(http.StatusCreated)
+		}
+	})
+
+	body := `{"cash_register_id": 1, "items": [{"product_id": 3, "quantity": 2}, {"product_id": 4}]}`
+	request := httptest.NewRequest(http.MethodPost, "/sales", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d", response.Code, http.StatusBadRequest)
+	}
+	var problem model.Problem
+	if err := json.Unmarshal(response.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("invalid problem: %v", err)
+	}
+	if _, ok := problem.Errors["items[1].quantity"]; !ok || len(problem.Errors) != 1 {
+		t.Errorf("expected only items[1].quantity reported, got %v", problem.Errors)
+	}
+}
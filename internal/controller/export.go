@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/spreadsheet"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportFormat reads the format requested through ?format= or the Accept header
+// Returns false after reporting a validation error when the format is not supported
+func exportFormat(ctx *gin.Context) (string, bool) {
+	format, err := spreadsheet.NegotiateFormat(ctx.Query("format"), ctx.GetHeader("Accept"))
+	if err != nil {
+		ctx.Error(apperror.Validation("unsupported_format", "Formato não suportado, use csv, xlsx ou pdf"))
+		return "", false
+	}
+	return format, true
+}
+
+// exportTable streams the rows produced by each as a CSV, XLSX or PDF attachment
+// Once the first byte is sent the status can no longer change, so errors in the
+// middle of the export abort the connection instead of answering 500
+func exportTable(ctx *gin.Context, format, name, title string, header []string, each func(write func([]string) error) error) {
+
+	filename := fmt.Sprintf("%s_%s.%s", name, time.Now().Format("20060102_150405"), format)
+	ctx.Header("Content-Type", spreadsheet.ContentType(format))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Status(http.StatusOK)
+
+	writer, err := spreadsheet.NewWriter(ctx.Writer, format, title, header)
+	if err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	if err := each(writer.WriteRow); err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+		return
+	}
+
+	if err := writer.Close(); err != nil {
+		ctx.Error(err)
+		ctx.Abort()
+	}
+}
+
+func formatMoney(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', 2, 64)
+}
+
+func formatInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
+func formatString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func formatBool(value bool) string {
+	if value {
+		return "sim"
+	}
+	return "não"
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+	"APIGolang/internal/health"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type healthController struct {
+	checker *health.Checker
+}
+
+func NewHealthController(checker *health.Checker) healthController {
+	return healthController{
+		checker: checker,
+	}
+}
+
+// Liveness godoc
+// @Summary Liveness
+// @Description Indica que o processo está respondendo. Não verifica dependências
+// @Tags Health
+// @Produce json
+// @Success 200 {object} model.HealthReport
+// @Router /healthz [get]
+func (h *healthController) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, h.checker.Liveness())
+}
+
+// Readiness godoc
+// @Summary Readiness
+// @Description Verifica o banco de dados e a versão das migrations. Responde 503 quando alguma dependência falha ou durante o desligamento
+// @Tags Health
+// @Produce json
+// @Success 200 {object} model.HealthReport
+// @Failure 503 {object} model.HealthReport
+// @Router /readyz [get]
+func (h *healthController) Readiness(ctx *gin.Context) {
+
+	report, ready := h.checker.Readiness(ctx.Request.Context())
+	if !ready {
+		ctx.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
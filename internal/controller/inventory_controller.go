@@ -0,0 +1,241 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/spreadsheet"
+	"APIGolang/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type inventoryController struct {
+	inventoryUsecase usecase.InventoryUseCase
+}
+
+func NewInventoryController(usecase usecase.InventoryUseCase) inventoryController {
+	return inventoryController{
+		inventoryUsecase: usecase,
+	}
+}
+
+// OpenSession godoc
+// @Summary Abrir inventário
+// @Description Abre uma contagem de todos os produtos ou de uma categoria, congelando o estoque atual
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param session body model.InventorySessionRequest false "Escopo do inventário"
+// @Success 201 {object} model.InventorySession
+// @Failure 400 {object} model.Problem
+// @Failure 409 {object} model.Problem
+// @Router /inventory [post]
+func (i *inventoryController) OpenSession(ctx *gin.Context) {
+
+	var request model.InventorySessionRequest
+	if ctx.Request.ContentLength != 0 {
+		if !bindJSON(ctx, &request) {
+			return
+		}
+	}
+
+	session, err := i.inventoryUsecase.OpenSession(ctx.Request.Context(), request, currentUserId(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, session)
+}
+
+// GetSessions godoc
+// @Summary Listar inventários
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "ABERTO ou FECHADO"
+// @Success 200 {array} model.InventorySession
+// @Router /inventory [get]
+func (i *inventoryController) GetSessions(ctx *gin.Context) {
+
+	var status *string
+	if value := ctx.Query("status"); value != "" {
+		status = &value
+	}
+
+	sessions, err := i.inventoryUsecase.GetSessions(ctx.Request.Context(), status)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sessions)
+}
+
+// GetSession godoc
+// @Summary Buscar inventário
+// @Tags Inventory
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID do inventário"
+// @Success 200 {object} model.InventorySession
+// @Failure 404 {object} model.Problem
+// @Router /inventory/{id} [get]
+func (i *inventoryController) GetSession(ctx *gin.Context) {
+
+	id, ok := inventoryId(ctx)
+	if !ok {
+		return
+	}
+
+	session, err := i.inventoryUsecase.GetSession(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, session)
+}
+
+// SubmitCounts godoc
+// @Summary Enviar contagem
+// @Description Registra as quantidades contadas por um dispositivo. Uma nova contagem do mesmo dispositivo substitui a anterior; contagens de dispositivos diferentes são somadas
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID do inventário"
+// @Param counts body model.InventoryCountRequest true "Contagem"
+// @Success 200 {object} model.InventorySession
+// @Failure 400 {object} model.Problem
+// @Failure 404 {object} model.Problem
+// @Failure 409 {object} model.Problem
+// @Router /inventory/{id}/counts [post]
+func (i *inventoryController) SubmitCounts(ctx *gin.Context) {
+
+	id, ok := inventoryId(ctx)
+	if !ok {
+		return
+	}
+
+	var request model.InventoryCountRequest
+	if !bindJSON(ctx, &request) {
+		return
+	}
+
+	session, err := i.inventoryUsecase.SubmitCounts(ctx.Request.Context(), id, request, currentUserId(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, session)
+}
+
+// CloseSession godoc
+// @Summary Fechar inventário
+// @Description Lança os ajustes de estoque das divergências e retorna o relatório de valorização
+// @Tags Inventory
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID do inventário"
+// @Param close body model.InventoryCloseRequest false "Opções de fechamento"
+// @Success 200 {object} model.InventoryReport
+// @Failure 404 {object} model.Problem
+// @Failure 409 {object} model.Problem
+// @Router /inventory/{id}/close [post]
+func (i *inventoryController) CloseSession(ctx *gin.Context) {
+
+	id, ok := inventoryId(ctx)
+	if !ok {
+		return
+	}
+
+	var request model.InventoryCloseRequest
+	if ctx.Request.ContentLength != 0 {
+		if !bindJSON(ctx, &request) {
+			return
+		}
+	}
+
+	report, err := i.inventoryUsecase.CloseSession(ctx.Request.Context(), id, request, currentUserId(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetReport godoc
+// @Summary Relatório de divergências do inventário
+// @Description Compara as quantidades contadas com o estoque congelado na abertura e valoriza as divergências pelo preço de custo. Use ?format=csv|xlsx|pdf ou o header Accept para exportar
+// @Tags Inventory
+// @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param id path int true "ID do inventário"
+// @Param format query string false "Formato de exportação (csv, xlsx, pdf)"
+// @Success 200 {object} model.InventoryReport
+// @Failure 404 {object} model.Problem
+// @Router /inventory/{id}/report [get]
+func (i *inventoryController) GetReport(ctx *gin.Context) {
+
+	format, ok := exportFormat(ctx)
+	if !ok {
+		return
+	}
+	id, ok := inventoryId(ctx)
+	if !ok {
+		return
+	}
+
+	report, err := i.inventoryUsecase.GetReport(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if format == spreadsheet.FormatJSON {
+		ctx.JSON(http.StatusOK, report)
+		return
+	}
+
+	header := []string{"codigo_produto", "nome", "categoria", "estoque_congelado", "quantidade_contada",
+		"divergencia", "custo_unitario", "valor_divergencia"}
+	title := "Inventário " + strconv.Itoa(id)
+
+	exportTable(ctx, format, "inventario_"+strconv.Itoa(id), title, header, func(write func([]string) error) error {
+		for _, item := range report.Items {
+			err := write([]string{
+				item.Code,
+				item.Name,
+				item.Category,
+				strconv.Itoa(item.FrozenStock),
+				formatInt(item.CountedQuantity),
+				formatInt(item.Variance),
+				formatMoney(&item.UnitCost),
+				formatMoney(item.VarianceValue),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func inventoryId(ctx *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_id", "Id do inventário precisa ser um número"))
+		return 0, false
+	}
+	return id, true
+}
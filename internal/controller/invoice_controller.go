@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/usecase"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type invoiceController struct {
+	invoiceUsecase usecase.InvoiceUseCase
+}
+
+func NewInvoiceController(usecase usecase.InvoiceUseCase) invoiceController {
+	return invoiceController{
+		invoiceUsecase: usecase,
+	}
+}
+
+// ImportInvoice godoc
+// @Summary Importar NF-e de entrada
+// @Description Lê o XML da NF-e 4.00 do fornecedor, associa os itens aos produtos e registra a entrada no estoque. Itens sem produto são cadastrados na categoria informada
+// @Tags Stock
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "XML da NF-e"
+// @Param category_id formData int false "Categoria dos produtos que serão cadastrados"
+// @Param dry_run query bool false "Somente conferir, sem gravar"
+// @Success 200 {object} model.InvoiceImportResult
+// @Failure 400 {object} model.Problem
+// @Failure 409 {object} model.Problem
+// @Failure 422 {object} model.InvoiceImportResult
+// @Router /stock/invoice [post]
+func (i *invoiceController) ImportInvoice(ctx *gin.Context) {
+
+	dryRun := false
+	if value := ctx.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			ctx.Error(apperror.Validation("invalid_dry_run", "dry_run precisa ser true ou false"))
+			return
+		}
+		dryRun = parsed
+	}
+
+	var categoryId *int
+	if value := ctx.PostForm("category_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			ctx.Error(apperror.Validation("invalid_id", "Id da categoria precisa ser um número"))
+			return
+		}
+		categoryId = &id
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.Error(apperror.Validation("file_required", "Arquivo não informado"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_file", "Não foi possível ler o arquivo"))
+		return
+	}
+	defer file.Close()
+
+	result, err := i.invoiceUsecase.Import(ctx.Request.Context(), file, dryRun, categoryId, currentActor(ctx))
+	if result != nil {
+		for idx, item := range result.Invoice.Items {
+			if item.Error != "" {
+				result.Invoice.Items[idx].Error = translate(ctx, item.Error, item.ErrorArgs...)
+			}
+		}
+	}
+	if errors.Is(err, usecase.ErrInvoiceItemsPending) {
+		ctx.JSON(http.StatusUnprocessableEntity, result)
+		return
+	}
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,178 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/spreadsheet"
+	"APIGolang/internal/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type lotController struct {
+	lotUsecase usecase.LotUseCase
+}
+
+func NewLotController(usecase usecase.LotUseCase) lotController {
+	return lotController{
+		lotUsecase: usecase,
+	}
+}
+
+// RegisterEntry godoc
+// @Summary Entrada manual de estoque
+// @Description Dá entrada de unidades no estoque, criando o lote quando o produto controla lotes
+// @Tags Stock
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param entry body model.StockEntryRequest true "Entrada"
+// @Success 201 {object} model.Response
+// @Failure 400 {object} model.Problem
+// @Failure 404 {object} model.Problem
+// @Router /stock/entry [post]
+func (l *lotController) RegisterEntry(ctx *gin.Context) {
+
+	var entry model.StockEntryRequest
+	if !bindJSON(ctx, &entry) {
+		return
+	}
+
+	err := l.lotUsecase.RegisterEntry(ctx.Request.Context(), entry, currentUserId(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, model.Response{Message: translate(ctx, "Entrada registrada")})
+}
+
+// GetProductLots godoc
+// @Summary Lotes do produto
+// @Description Lista os lotes com saldo, do que vence primeiro ao que vence por último
+// @Tags Products
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID do produto"
+// @Success 200 {array} model.Lot
+// @Failure 404 {object} model.Problem
+// @Router /product/{id}/lots [get]
+func (l *lotController) GetProductLots(ctx *gin.Context) {
+
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_id", "Id do produto precisa ser um número"))
+		return
+	}
+
+	lots, err := l.lotUsecase.GetProductLots(ctx.Request.Context(), productId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, lots)
+}
+
+// GetExpiringLots godoc
+// @Summary Lotes a vencer
+// @Description Lista os lotes com saldo que vencem nos próximos N dias, incluindo os já vencidos, para remarcação ou baixa. Use ?format=csv|xlsx|pdf ou o header Accept para exportar
+// @Tags Stock
+// @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param days query int false "Dias até o vencimento" default(30)
+// @Param format query string false "Formato de exportação (csv, xlsx, pdf)"
+// @Success 200 {array} model.Lot
+// @Failure 400 {object} model.Problem
+// @Router /stock/lots/expiring [get]
+func (l *lotController) GetExpiringLots(ctx *gin.Context) {
+
+	format, ok := exportFormat(ctx)
+	if !ok {
+		return
+	}
+
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", "30"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_days", "A quantidade de dias precisa ser um número"))
+		return
+	}
+
+	lots, err := l.lotUsecase.GetExpiringLots(ctx.Request.Context(), days, time.Now())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if format == spreadsheet.FormatJSON {
+		ctx.JSON(http.StatusOK, lots)
+		return
+	}
+
+	header := []string{"codigo_produto", "nome", "lote", "validade", "dias_para_vencer", "quantidade", "valor_estoque"}
+	title := "Lotes a vencer em " + strconv.Itoa(days) + " dias"
+
+	exportTable(ctx, format, "lotes_a_vencer", title, header, func(write func([]string) error) error {
+		for _, lot := range lots {
+			expiresAt := ""
+			if lot.ExpiresAt != nil {
+				expiresAt = lot.ExpiresAt.Format("02/01/2006")
+			}
+			err := write([]string{
+				lot.ProductCode,
+				lot.ProductName,
+				lot.Number,
+				expiresAt,
+				formatInt(lot.DaysToExpire),
+				strconv.Itoa(lot.Quantity),
+				formatMoney(&lot.StockValue),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// WriteOffLot godoc
+// @Summary Baixar lote
+// @Description Retira do estoque unidades vencidas ou avariadas do lote
+// @Tags Stock
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID do lote"
+// @Param writeOff body model.LotWriteOffRequest true "Quantidade e motivo"
+// @Success 200 {object} model.Lot
+// @Failure 400 {object} model.Problem
+// @Failure 404 {object} model.Problem
+// @Failure 409 {object} model.Problem
+// @Router /stock/lots/{id}/write-off [post]
+func (l *lotController) WriteOffLot(ctx *gin.Context) {
+
+	lotId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_id", "Id do lote precisa ser um número"))
+		return
+	}
+
+	var request model.LotWriteOffRequest
+	if !bindJSON(ctx, &request) {
+		return
+	}
+
+	lot, err := l.lotUsecase.WriteOff(ctx.Request.Context(), lotId, request, currentUserId(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, lot)
+}
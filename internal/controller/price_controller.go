@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type priceController struct {
+	priceUsecase usecase.PriceUseCase
+}
+
+func NewPriceController(usecase usecase.PriceUseCase) priceController {
+	return priceController{
+		priceUsecase: usecase,
+	}
+}
+
+// GetPriceTimeline godoc
+// @Summary Histórico de preços do produto
+// @Description Retorna os preços atuais, as alterações anteriores e as alterações agendadas
+// @Tags Products
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID do produto"
+// @Success 200 {object} model.PriceTimeline
+// @Failure 400 {object} model.Problem
+// @Failure 404 {object} model.Problem
+// @Router /product/{id}/prices [get]
+func (p *priceController) GetPriceTimeline(ctx *gin.Context) {
+
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_id", "Id do produto precisa ser um número"))
+		return
+	}
+
+	timeline, err := p.priceUsecase.GetPriceTimeline(ctx.Request.Context(), productId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, timeline)
+}
+
+// SchedulePriceChange godoc
+// @Summary Agendar alteração de preço
+// @Description Agenda um novo preço de venda (e opcionalmente de custo) que entra em vigor na data informada
+// @Tags Products
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID do produto"
+// @Param schedule body model.SchedulePriceRequest true "Novo preço e data de vigência"
+// @Success 201 {object} model.ScheduledPriceChange
+// @Failure 400 {object} model.Problem
+// @Failure 404 {object} model.Problem
+// @Router /product/{id}/prices/schedule [post]
+func (p *priceController) SchedulePriceChange(ctx *gin.Context) {
+
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_id", "Id do produto precisa ser um número"))
+		return
+	}
+
+	var request model.SchedulePriceRequest
+	if !bindJSON(ctx, &request) {
+		return
+	}
+
+	change, err := p.priceUsecase.SchedulePriceChange(ctx.Request.Context(), productId, request, currentUserId(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, change)
+}
+
+// CancelScheduledChange godoc
+// @Summary Cancelar alteração de preço agendada
+// @Tags Products
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID do produto"
+// @Param scheduleId path int true "ID do agendamento"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Problem
+// @Router /product/{id}/prices/schedule/{scheduleId} [delete]
+func (p *priceController) CancelScheduledChange(ctx *gin.Context) {
+
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_id", "Id do produto precisa ser um número"))
+		return
+	}
+	scheduleId, err := strconv.Atoi(ctx.Param("scheduleId"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_id", "Id do agendamento precisa ser um número"))
+		return
+	}
+
+	cancelled, err := p.priceUsecase.CancelScheduledChange(ctx.Request.Context(), productId, scheduleId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if !cancelled {
+		ctx.Error(apperror.NotFound("price_schedule_not_found", "Agendamento pendente não encontrado"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.Response{Message: translate(ctx, "Agendamento cancelado com sucesso")})
+}
@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type pricingController struct {
+	pricingUsecase usecase.PricingUseCase
+}
+
+func NewPricingController(usecase usecase.PricingUseCase) pricingController {
+	return pricingController{
+		pricingUsecase: usecase,
+	}
+}
+
+// GetProductPricing godoc
+// @Summary Markup e margem do produto
+// @Description Retorna markup, margem e o preço sugerido pela regra da categoria
+// @Tags Products
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID do produto"
+// @Success 200 {object} model.ProductPricing
+// @Failure 404 {object} model.Problem
+// @Router /product/{id}/pricing [get]
+func (p *pricingController) GetProductPricing(ctx *gin.Context) {
+
+	productId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_id", "Id do produto precisa ser um número"))
+		return
+	}
+
+	productPricing, err := p.pricingUsecase.GetProductPricing(ctx.Request.Context(), productId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, productPricing)
+}
+
+// GetCategoryRule godoc
+// @Summary Regra de preço da categoria
+// @Tags Categories
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID da categoria"
+// @Success 200 {object} model.PricingRule
+// @Failure 404 {object} model.Problem
+// @Router /category/{id}/pricing-rule [get]
+func (p *pricingController) GetCategoryRule(ctx *gin.Context) {
+
+	categoryId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_id", "Id da categoria precisa ser um número"))
+		return
+	}
+
+	rule, err := p.pricingUsecase.GetCategoryRule(ctx.Request.Context(), categoryId)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rule)
+}
+
+// SaveCategoryRule godoc
+// @Summary Definir regra de preço da categoria
+// @Description Define o markup alvo, a margem mínima e o arredondamento (NENHUM, X_99, X_49) da categoria
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID da categoria"
+// @Param rule body model.PricingRule true "Regra de preço"
+// @Success 200 {object} model.PricingRule
+// @Failure 400 {object} model.Problem
+// @Router /category/{id}/pricing-rule [put]
+func (p *pricingController) SaveCategoryRule(ctx *gin.Context) {
+
+	categoryId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_id", "Id da categoria precisa ser um número"))
+		return
+	}
+
+	var rule model.PricingRule
+	if !bindJSON(ctx, &rule) {
+		return
+	}
+	rule.CategoryId = categoryId
+
+	saved, err := p.pricingUsecase.SaveCategoryRule(ctx.Request.Context(), rule)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, saved)
+}
@@ -1,7 +1,9 @@
 package controller
 
 import (
+	"APIGolang/internal/apperror"
 	"APIGolang/internal/model"
+	"APIGolang/internal/spreadsheet"
 	"APIGolang/internal/usecase"
 	"net/http"
 	"strconv"
@@ -21,23 +23,63 @@ func NewProductController(usecase usecase.ProductUsecase) productController {
 
 // GetProducts godoc
 // @Summary Listar produtos
-// @Description Retorna todos os produtos
+// @Description Retorna todos os produtos. Use ?format=csv|xlsx|pdf ou o header Accept para exportar
 // @Tags Products
 // @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
 // @Security BearerAuth
+// @Param format query string false "Formato de exportação (csv, xlsx, pdf)"
 // @Success 200 {array} model.Product
-// @Failure 401 {object} map[string]string
-// @Router /products [get]
+// @Failure 401 {object} model.Problem
+// @Router /product [get]
 func (p *productController) GetProducts(ctx *gin.Context) {
-	
-	products, err := p.productUsecase.GetProducts()
-	if err != nil{
-		ctx.JSON(http.StatusInternalServerError, err)
+
+	format, ok := exportFormat(ctx)
+	if !ok {
+		return
+	}
+	if format != spreadsheet.FormatJSON {
+		p.exportProducts(ctx, format)
+		return
+	}
+
+	products, err := p.productUsecase.GetProducts(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err)
+		return
 	}
 
 	ctx.JSON(http.StatusOK, products)
 }
 
+func (p *productController) exportProducts(ctx *gin.Context, format string) {
+
+	header := []string{"codigo_produto", "codigo_barras", "nome", "categoria_id", "fornecedor_id",
+		"preco_custo", "preco_venda", "markup", "margem", "unidade_medida", "estoque_atual", "estoque_minimo", "ativo"}
+
+	exportTable(ctx, format, "produtos", "Produtos", header, func(write func([]string) error) error {
+		return p.productUsecase.EachProduct(ctx.Request.Context(), func(product model.Product) error {
+			return write([]string{
+				formatString(product.Code),
+				formatString(product.Barcode),
+				formatString(product.Name),
+				formatInt(product.CategoryId),
+				formatInt(product.SupplierId),
+				formatMoney(product.CostPrice),
+				formatMoney(product.Price),
+				formatMoney(product.Markup),
+				formatMoney(product.Margin),
+				formatString(product.Unit),
+				formatInt(product.CurrentStock),
+				formatInt(product.MinimumStock),
+				formatBool(product.Active != nil && *product.Active),
+			})
+		})
+	})
+}
+
 // GetProductById godoc
 // @Summary Buscar produto por ID
 // @Tags Products
@@ -45,39 +87,31 @@ func (p *productController) GetProducts(ctx *gin.Context) {
 // @Security BearerAuth
 // @Param id path int true "ID do produto"
 // @Success 200 {object} model.Product
-// @Failure 400 {object} model.Response
-// @Failure 404 {object} model.Response
+// @Failure 400 {object} model.Problem
+// @Failure 404 {object} model.Problem
 // @Router /products/{id} [get]
 func (p *productController) GetProductById(ctx *gin.Context) {
 
 	id := ctx.Param("id")
 	if id == "" {
-		response := model.Response{
-			Message: "Id do produto não pode ser nulo",
-		}
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(apperror.Validation("invalid_id", "Id do produto não pode ser nulo"))
 		return
 	}
 
 	productId, err := strconv.Atoi(id)
 	if err != nil {
-		response := model.Response{
-			Message: "Id do produto precisa ser um número",
-		}
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(apperror.Validation("invalid_id", "Id do produto precisa ser um número"))
 		return
 	}
 
-	product, err := p.productUsecase.GetProductById(productId)
+	product, err := p.productUsecase.GetProductById(ctx.Request.Context(), productId)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, err)
+		ctx.Error(err)
+		return
 	}
 
 	if product == nil {
-		response := model.Response{
-			Message: "Produto não foi encontrado na base de dados",
-		}
-		ctx.JSON(http.StatusNotFound, response)
+		ctx.Error(usecase.ErrProductNotFound)
 		return
 	}
 
@@ -90,23 +124,20 @@ func (p *productController) GetProductById(ctx *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param product body model.Product true "Produto"
+// @Param product body model.CreateProductRequest true "Produto"
 // @Success 201 {object} model.Product
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} model.Problem
 // @Router /products [post]
 func (p *productController) CreateProduct(ctx *gin.Context) {
 
-	var product model.Product
-	err := ctx.BindJSON(&product)
-
-	if err != nil {
-		ctx.JSON(http.StatusBadGateway, err)
+	var req model.CreateProductRequest
+	if !bindJSON(ctx, &req) {
 		return
 	}
 
-	insertedProduct, err := p.productUsecase.CreateProduct(product)
+	insertedProduct, err := p.productUsecase.CreateProduct(ctx.Request.Context(), req.Product(), currentActor(ctx))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, err)
+		ctx.Error(err)
 		return
 	}
 
@@ -122,53 +153,39 @@ func (p *productController) CreateProduct(ctx *gin.Context) {
 // @Param id path int true "ID do produto"
 // @Param product body model.Product true "Campos para atualizar"
 // @Success 200 {object} model.Product
-// @Failure 400 {object} model.Response
+// @Failure 400 {object} model.Problem
 // @Router /products/{id} [put]
 func (p *productController) UpdateProductById(ctx *gin.Context) {
 
 	var product model.Product
-	err := ctx.BindJSON(&product)
-
-	if err != nil {
-		ctx.JSON(http.StatusBadGateway, err)
+	if !bindJSON(ctx, &product) {
 		return
 	}
-	if product.Name == nil && product.Price == nil {
-		response := model.Response{
-			Message: "É necessário preencher ao menos um campo para ser atualizado",
-		}
-		ctx.JSON(http.StatusBadRequest, response)
+	product.Id, product.Markup, product.Margin = 0, nil, nil
+	if product == (model.Product{}) {
+		ctx.Error(apperror.Validation("empty_update", "É necessário preencher ao menos um campo para ser atualizado"))
 		return
 	}
 
 	id := ctx.Param("id")
 	if id == "" {
-		response := model.Response{
-			Message: "Id do produto não pode ser nulo",
-		}
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(apperror.Validation("invalid_id", "Id do produto não pode ser nulo"))
 		return
 	}
 
 	productId, err := strconv.Atoi(id)
 	if err != nil {
-		response := model.Response{
-			Message: "Id do produto precisa ser um número",
-		}
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(apperror.Validation("invalid_id", "Id do produto precisa ser um número"))
 		return
 	}
 
-	updatedProduct, err := p.productUsecase.UpdateProductById(productId, product)
-	if updatedProduct == nil {
-		response := model.Response{
-			Message: "Produto não foi encontrado na base de dados",
-		}
-		ctx.JSON(http.StatusNotFound, response)
+	updatedProduct, err := p.productUsecase.UpdateProductById(ctx.Request.Context(), productId, product, currentActor(ctx))
+	if err != nil {
+		ctx.Error(err)
 		return
 	}
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, err)
+	if updatedProduct == nil {
+		ctx.Error(usecase.ErrProductNotFound)
 		return
 	}
 
@@ -182,37 +199,34 @@ func (p *productController) UpdateProductById(ctx *gin.Context) {
 // @Security BearerAuth
 // @Param id path int true "ID do produto"
 // @Success 200 {object} model.Response
-// @Failure 400 {object} model.Response
+// @Failure 400 {object} model.Problem
 // @Router /products/{id} [delete]
 func (p *productController) DeleteProductById(ctx *gin.Context) {
 
 	id := ctx.Param("id")
 	if id == "" {
-		response := model.Response{
-			Message: "Id do produto não pode ser nulo",
-		}
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(apperror.Validation("invalid_id", "Id do produto não pode ser nulo"))
 		return
 	}
 
 	productId, err := strconv.Atoi(id)
 	if err != nil {
-		response := model.Response{
-			Message: "Id do produto precisa ser um número",
-		}
-		ctx.JSON(http.StatusBadRequest, response)
+		ctx.Error(apperror.Validation("invalid_id", "Id do produto precisa ser um número"))
 		return
 	}
 
-	isSucess, err := p.productUsecase.DeleteProductById(productId)
+	isSucess, err := p.productUsecase.DeleteProductById(ctx.Request.Context(), productId, currentActor(ctx))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, err)
+		ctx.Error(err)
 		return
 	}
-	if isSucess {
-		response := model.Response{
-			Message: "O produto foi deletado com sucesso",
-		}
-		ctx.JSON(http.StatusOK, response)
+	if !isSucess {
+		ctx.Error(usecase.ErrProductNotFound)
+		return
+	}
+
+	response := model.Response{
+		Message: translate(ctx, "O produto foi deletado com sucesso"),
 	}
+	ctx.JSON(http.StatusOK, response)
 }
\ No newline at end of file
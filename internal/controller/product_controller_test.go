@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"APIGolang/internal/model"
+	"APIGolang/internal/usecase"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateProductRequiresTheMandatoryFields(t *testing.T) {
+	server := newTestServer(t)
+	controller := NewProductController(usecase.ProductUsecase{})
+	// The body is rejected before the usecase is reached
+	server.POST("/products", controller.CreateProduct)
+
+	body := `{"product_code": "CAF-1", "cost_price": 0, "barcode": "7891000315508"}`
+	request := httptest.NewRequest(http.MethodPost, "/products", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+
+	if response.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d, want %d", response.Code, http.StatusBadRequest)
+	}
+	var problem model.Problem
+	if err := json.Unmarshal(response.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("invalid problem: %v", err)
+	}
+	for _, field := range []string{"product_name", "category_id", "product_price", "barcode"} {
+		if _, ok := problem.Errors[field]; !ok {
+			t.Errorf("expected %s reported, got %v", field, problem.Errors)
+		}
+	}
+	if len(problem.Errors) != 4 {
+		t.Errorf("expected only the missing and invalid fields, got %v", problem.Errors)
+	}
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/spreadsheet"
+	"APIGolang/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type productImportController struct {
+	importUsecase usecase.ProductImportUseCase
+}
+
+func NewProductImportController(usecase usecase.ProductImportUseCase) productImportController {
+	return productImportController{
+		importUsecase: usecase,
+	}
+}
+
+// ImportProducts godoc
+// @Summary Importar produtos
+// @Description Importa produtos de um arquivo CSV ou XLSX, atualizando pelo código do produto. Com dry_run=true apenas valida e retorna o relatório
+// @Tags Products
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "Planilha CSV ou XLSX"
+// @Param dry_run query bool false "Somente validar, sem gravar"
+// @Success 200 {object} model.ImportReport
+// @Failure 400 {object} model.Problem
+// @Failure 422 {object} model.ImportReport
+// @Router /product/import [post]
+func (p *productImportController) ImportProducts(ctx *gin.Context) {
+
+	dryRun := false
+	if value := ctx.Query("dry_run"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			ctx.Error(apperror.Validation("invalid_dry_run", "dry_run precisa ser true ou false"))
+			return
+		}
+		dryRun = parsed
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.Error(apperror.Validation("file_required", "Arquivo não informado"))
+		return
+	}
+
+	format, err := spreadsheet.FormatFromFilename(fileHeader.Filename)
+	if err != nil {
+		ctx.Error(apperror.Validation("unsupported_format", "Formato de arquivo não suportado, use csv ou xlsx"))
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_file", "Não foi possível ler o arquivo"))
+		return
+	}
+	defer file.Close()
+
+	report, err := p.importUsecase.Import(ctx.Request.Context(), file, format, dryRun, currentActor(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if len(report.Errors) > 0 {
+		for i, rowErr := range report.Errors {
+			report.Errors[i].Message = translate(ctx, rowErr.Message, rowErr.Args...)
+		}
+		ctx.JSON(http.StatusUnprocessableEntity, report)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
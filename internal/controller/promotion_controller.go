@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type promotionController struct {
+	promotionUsecase usecase.PromotionUseCase
+}
+
+func NewPromotionController(usecase usecase.PromotionUseCase) promotionController {
+	return promotionController{
+		promotionUsecase: usecase,
+	}
+}
+
+// GetPromotions godoc
+// @Summary Listar promoções
+// @Tags Promotions
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} model.Promotion
+// @Router /promotion [get]
+func (p *promotionController) GetPromotions(ctx *gin.Context) {
+
+	promotions, err := p.promotionUsecase.GetPromotions(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, promotions)
+}
+
+// CreatePromotion godoc
+// @Summary Criar promoção
+// @Description Tipos: PERCENTUAL, PRECO_FIXO, LEVE_PAGUE, CATEGORIA e COMBO
+// @Tags Promotions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param promotion body model.Promotion true "Promoção"
+// @Success 201 {object} model.Promotion
+// @Failure 400 {object} model.Problem
+// @Router /promotion [post]
+func (p *promotionController) CreatePromotion(ctx *gin.Context) {
+
+	var promotion model.Promotion
+	if !bindJSON(ctx, &promotion) {
+		return
+	}
+
+	created, err := p.promotionUsecase.CreatePromotion(ctx.Request.Context(), promotion)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, created)
+}
+
+// DeactivatePromotion godoc
+// @Summary Encerrar promoção
+// @Tags Promotions
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID da promoção"
+// @Success 200 {object} model.Response
+// @Failure 404 {object} model.Problem
+// @Router /promotion/{id} [delete]
+func (p *promotionController) DeactivatePromotion(ctx *gin.Context) {
+
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_id", "Id da promoção precisa ser um número"))
+		return
+	}
+
+	deactivated, err := p.promotionUsecase.DeactivatePromotion(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	if !deactivated {
+		ctx.Error(apperror.NotFound("promotion_not_found", "Promoção ativa não encontrada"))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.Response{Message: translate(ctx, "Promoção encerrada com sucesso")})
+}
@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type purchaseController struct {
+	purchaseUsecase usecase.PurchaseUseCase
+}
+
+func NewPurchaseController(usecase usecase.PurchaseUseCase) purchaseController {
+	return purchaseController{
+		purchaseUsecase: usecase,
+	}
+}
+
+// GetSuggestions godoc
+// @Summary Sugestão de compra
+// @Description Sugere, por fornecedor, a quantidade a comprar de cada produto para cobrir o estoque mínimo mais os dias de cobertura no ritmo de vendas recente, descontando o que já está em pedidos abertos
+// @Tags Purchases
+// @Produce json
+// @Security BearerAuth
+// @Param sales_days query int false "Dias de vendas usados na média" default(30)
+// @Param coverage_days query int false "Dias de cobertura desejados" default(15)
+// @Param supplier_id query int false "ID do fornecedor"
+// @Success 200 {array} model.PurchaseSuggestion
+// @Failure 400 {object} model.Problem
+// @Router /purchase/suggestions [get]
+func (p *purchaseController) GetSuggestions(ctx *gin.Context) {
+
+	salesDays, errSales := strconv.Atoi(ctx.DefaultQuery("sales_days", "30"))
+	coverageDays, errCoverage := strconv.Atoi(ctx.DefaultQuery("coverage_days", "15"))
+	if errSales != nil || errCoverage != nil {
+		ctx.Error(apperror.Validation("invalid_period", "Os períodos precisam ser números"))
+		return
+	}
+
+	var supplierId *int
+	if value := ctx.Query("supplier_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			ctx.Error(apperror.Validation("invalid_id", "Id do fornecedor precisa ser um número"))
+			return
+		}
+		supplierId = &id
+	}
+
+	suggestions, err := p.purchaseUsecase.GetSuggestions(ctx.Request.Context(), salesDays, coverageDays, supplierId, time.Now())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, suggestions)
+}
+
+// GetPurchaseOrders godoc
+// @Summary Listar pedidos de compra
+// @Tags Purchases
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "RASCUNHO, ENVIADO, RECEBIDO_PARCIAL ou RECEBIDO"
+// @Success 200 {array} model.PurchaseOrder
+// @Router /purchase/order [get]
+func (p *purchaseController) GetPurchaseOrders(ctx *gin.Context) {
+
+	var status *string
+	if value := ctx.Query("status"); value != "" {
+		status = &value
+	}
+
+	orders, err := p.purchaseUsecase.GetPurchaseOrders(ctx.Request.Context(), status)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, orders)
+}
+
+// GetPurchaseOrderById godoc
+// @Summary Buscar pedido de compra
+// @Tags Purchases
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID do pedido"
+// @Success 200 {object} model.PurchaseOrder
+// @Failure 404 {object} model.Problem
+// @Router /purchase/order/{id} [get]
+func (p *purchaseController) GetPurchaseOrderById(ctx *gin.Context) {
+
+	id, ok := purchaseOrderId(ctx)
+	if !ok {
+		return
+	}
+
+	order, err := p.purchaseUsecase.GetPurchaseOrderById(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+// CreatePurchaseOrder godoc
+// @Summary Criar pedido de compra
+// @Description Cria um pedido em rascunho, normalmente a partir de uma sugestão de compra. Itens sem custo usam o preço de custo do produto
+// @Tags Purchases
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param order body model.PurchaseOrderRequest true "Pedido"
+// @Success 201 {object} model.PurchaseOrder
+// @Failure 400 {object} model.Problem
+// @Router /purchase/order [post]
+func (p *purchaseController) CreatePurchaseOrder(ctx *gin.Context) {
+
+	var request model.PurchaseOrderRequest
+	if !bindJSON(ctx, &request) {
+		return
+	}
+
+	order, err := p.purchaseUsecase.CreatePurchaseOrder(ctx.Request.Context(), request, currentUserId(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, order)
+}
+
+// SendPurchaseOrder godoc
+// @Summary Enviar pedido de compra
+// @Description Marca o pedido em rascunho como enviado ao fornecedor
+// @Tags Purchases
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID do pedido"
+// @Success 200 {object} model.PurchaseOrder
+// @Failure 404 {object} model.Problem
+// @Failure 409 {object} model.Problem
+// @Router /purchase/order/{id}/send [post]
+func (p *purchaseController) SendPurchaseOrder(ctx *gin.Context) {
+
+	id, ok := purchaseOrderId(ctx)
+	if !ok {
+		return
+	}
+
+	order, err := p.purchaseUsecase.SendPurchaseOrder(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+// ReceivePurchaseOrder godoc
+// @Summary Receber pedido de compra
+// @Description Registra a entrega total ou parcial do pedido, dando entrada no estoque e atualizando o preço de custo
+// @Tags Purchases
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID do pedido"
+// @Param receipt body model.PurchaseReceiptRequest true "Quantidades recebidas"
+// @Success 200 {object} model.PurchaseOrder
+// @Failure 400 {object} model.Problem
+// @Failure 404 {object} model.Problem
+// @Failure 409 {object} model.Problem
+// @Router /purchase/order/{id}/receive [post]
+func (p *purchaseController) ReceivePurchaseOrder(ctx *gin.Context) {
+
+	id, ok := purchaseOrderId(ctx)
+	if !ok {
+		return
+	}
+
+	var request model.PurchaseReceiptRequest
+	if !bindJSON(ctx, &request) {
+		return
+	}
+
+	order, err := p.purchaseUsecase.ReceivePurchaseOrder(ctx.Request.Context(), id, request, currentUserId(ctx))
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+func purchaseOrderId(ctx *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_id", "Id do pedido precisa ser um número"))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,219 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/spreadsheet"
+	"APIGolang/internal/usecase"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const reportDateLayout = "2006-01-02"
+
+type reportController struct {
+	reportUsecase usecase.ReportUseCase
+}
+
+func NewReportController(usecase usecase.ReportUseCase) reportController {
+	return reportController{
+		reportUsecase: usecase,
+	}
+}
+
+// GetSalesReport godoc
+// @Summary Relatório de vendas
+// @Description Faturamento bruto e líquido, descontos, ticket médio, quantidade de itens e margem bruta das vendas finalizadas, agrupados por período, operador, forma de pagamento ou categoria
+// @Tags Reports
+// @Produce json
+// @Security BearerAuth
+// @Param group_by query string false "day, week, month, user, payment_method ou category" default(day)
+// @Param from query string false "Data inicial (AAAA-MM-DD), padrão 30 dias atrás"
+// @Param to query string false "Data final inclusiva (AAAA-MM-DD), padrão hoje"
+// @Success 200 {object} model.SalesReport
+// @Failure 400 {object} model.Problem
+// @Router /report/sales [get]
+func (r *reportController) GetSalesReport(ctx *gin.Context) {
+
+	from, to, ok := reportPeriod(ctx)
+	if !ok {
+		return
+	}
+
+	report, err := r.reportUsecase.GetSalesReport(ctx.Request.Context(), ctx.DefaultQuery("group_by", model.ReportGroupDay), from, to)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetAbcAnalysis godoc
+// @Summary Curva ABC e cobertura de estoque
+// @Description Classifica os produtos pelo faturamento líquido do período e calcula a média de vendas diária e quantos dias o estoque atual cobre. Use ?format=csv|xlsx|pdf ou o header Accept para exportar
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param from query string false "Data inicial (AAAA-MM-DD), padrão 30 dias atrás"
+// @Param to query string false "Data final inclusiva (AAAA-MM-DD), padrão hoje"
+// @Param limit_a query number false "Percentual acumulado da classe A" default(80)
+// @Param limit_b query number false "Percentual acumulado da classe B" default(95)
+// @Param format query string false "Formato de exportação (csv, xlsx, pdf)"
+// @Success 200 {object} model.AbcAnalysis
+// @Failure 400 {object} model.Problem
+// @Router /report/abc [get]
+func (r *reportController) GetAbcAnalysis(ctx *gin.Context) {
+
+	format, ok := exportFormat(ctx)
+	if !ok {
+		return
+	}
+	from, to, ok := reportPeriod(ctx)
+	if !ok {
+		return
+	}
+
+	limitA, errA := strconv.ParseFloat(ctx.DefaultQuery("limit_a", "80"), 64)
+	limitB, errB := strconv.ParseFloat(ctx.DefaultQuery("limit_b", "95"), 64)
+	if errA != nil || errB != nil {
+		ctx.Error(apperror.Validation("invalid_abc_limits", "Os limites precisam ser números"))
+		return
+	}
+
+	abc, err := r.reportUsecase.GetAbcAnalysis(ctx.Request.Context(), from, to, limitA, limitB)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if format == spreadsheet.FormatJSON {
+		ctx.JSON(http.StatusOK, abc)
+		return
+	}
+
+	header := []string{"classe", "codigo_produto", "nome", "categoria", "quantidade_vendida", "faturamento",
+		"participacao", "participacao_acumulada", "estoque_atual", "media_diaria", "cobertura_dias"}
+	title := "Curva ABC " + from.Format("02/01/2006") + " a " + to.Format("02/01/2006")
+
+	exportTable(ctx, format, "curva_abc", title, header, func(write func([]string) error) error {
+		for _, item := range abc.Items {
+			err := write([]string{
+				item.Class,
+				item.Code,
+				item.Name,
+				item.Category,
+				strconv.FormatFloat(item.QuantitySold, 'f', -1, 64),
+				formatMoney(&item.Revenue),
+				formatMoney(&item.Share),
+				formatMoney(&item.CumulativeShare),
+				strconv.Itoa(item.CurrentStock),
+				formatMoney(&item.AverageDaily),
+				formatMoney(item.CoverageDays),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetIdleProducts godoc
+// @Summary Produtos sem venda
+// @Description Lista os produtos ativos sem vendas nos últimos N dias, com o valor parado em estoque. Use ?format=csv|xlsx|pdf ou o header Accept para exportar
+// @Tags Reports
+// @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param days query int false "Dias sem venda" default(90)
+// @Param format query string false "Formato de exportação (csv, xlsx, pdf)"
+// @Success 200 {array} model.IdleProduct
+// @Failure 400 {object} model.Problem
+// @Router /report/idle-products [get]
+func (r *reportController) GetIdleProducts(ctx *gin.Context) {
+
+	format, ok := exportFormat(ctx)
+	if !ok {
+		return
+	}
+
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", "90"))
+	if err != nil {
+		ctx.Error(apperror.Validation("invalid_days", "A quantidade de dias precisa ser um número"))
+		return
+	}
+
+	products, err := r.reportUsecase.GetIdleProducts(ctx.Request.Context(), days, time.Now())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if format == spreadsheet.FormatJSON {
+		ctx.JSON(http.StatusOK, products)
+		return
+	}
+
+	header := []string{"codigo_produto", "nome", "categoria", "estoque_atual", "preco_custo", "valor_estoque",
+		"ultima_venda"}
+	title := "Produtos sem venda há " + strconv.Itoa(days) + " dias"
+
+	exportTable(ctx, format, "produtos_sem_venda", title, header, func(write func([]string) error) error {
+		for _, product := range products {
+			lastSale := ""
+			if product.LastSaleAt != nil {
+				lastSale = product.LastSaleAt.Format("02/01/2006")
+			}
+			err := write([]string{
+				product.Code,
+				product.Name,
+				product.Category,
+				strconv.Itoa(product.CurrentStock),
+				formatMoney(&product.CostPrice),
+				formatMoney(&product.StockValue),
+				lastSale,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// reportPeriod reads the from and to query parameters, defaulting to the last 30 days
+// Writes the 400 response and returns false when a date is invalid
+func reportPeriod(ctx *gin.Context) (time.Time, time.Time, bool) {
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	from, to := today.AddDate(0, 0, -30), today
+
+	if value := ctx.Query("from"); value != "" {
+		parsed, err := time.ParseInLocation(reportDateLayout, value, time.Local)
+		if err != nil {
+			ctx.Error(apperror.Validation("invalid_date", "Data inicial inválida, use o formato AAAA-MM-DD"))
+			return from, to, false
+		}
+		from = parsed
+	}
+	if value := ctx.Query("to"); value != "" {
+		parsed, err := time.ParseInLocation(reportDateLayout, value, time.Local)
+		if err != nil {
+			ctx.Error(apperror.Validation("invalid_date", "Data final inválida, use o formato AAAA-MM-DD"))
+			return from, to, false
+		}
+		to = parsed
+	}
+
+	return from, to, true
+}
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/usecase"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type saleController struct {
+	saleUsecase usecase.SaleUseCase
+}
+
+func NewSaleController(usecase usecase.SaleUseCase) saleController {
+	return saleController{
+		saleUsecase: usecase,
+	}
+}
+
+// QuoteSale godoc
+// @Summary Calcular venda
+// @Description Calcula os totais e os descontos das promoções vigentes sem registrar a venda
+// @Tags Sales
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param sale body model.SaleRequest true "Itens da venda"
+// @Success 200 {object} model.Sale
+// @Failure 400 {object} model.Problem
+// @Router /sale/quote [post]
+func (s *saleController) QuoteSale(ctx *gin.Context) {
+
+	var request model.SaleRequest
+	if !bindJSON(ctx, &request) {
+		return
+	}
+
+	sale, err := s.saleUsecase.Quote(ctx.Request.Context(), request)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sale)
+}
+
+// CreateSale godoc
+// @Summary Registrar venda
+// @Description Registra a venda com as promoções vigentes, os pagamentos e a baixa do estoque
+// @Tags Sales
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param sale body model.SaleRequest true "Venda"
+// @Success 201 {object} model.Sale
+// @Failure 400 {object} model.Problem
+// @Failure 403 {object} model.Problem
+// @Failure 409 {object} model.Problem
+// @Router /sale [post]
+func (s *saleController) CreateSale(ctx *gin.Context) {
+
+	var request model.SaleRequest
+	if !bindJSON(ctx, &request) {
+		return
+	}
+
+	actor := currentActor(ctx)
+	if actor.UserId == nil {
+		ctx.Error(apperror.Unauthorized("user_not_identified", "Usuário não identificado"))
+		return
+	}
+
+	sale, err := s.saleUsecase.CreateSale(ctx.Request.Context(), request, actor)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, sale)
+}
@@ -0,0 +1,76 @@
+package controller
+
+import (
+	"APIGolang/internal/model"
+	"APIGolang/internal/spreadsheet"
+	"APIGolang/internal/usecase"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type stockController struct {
+	stockUsecase usecase.StockUseCase
+}
+
+func NewStockController(usecase usecase.StockUseCase) stockController {
+	return stockController{
+		stockUsecase: usecase,
+	}
+}
+
+// GetStockPositions godoc
+// @Summary Posição de estoque
+// @Description Retorna o estoque atual e o valor em estoque de cada produto. Use ?format=csv|xlsx|pdf ou o header Accept para exportar
+// @Tags Stock
+// @Produce json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/pdf
+// @Security BearerAuth
+// @Param format query string false "Formato de exportação (csv, xlsx, pdf)"
+// @Success 200 {array} model.StockPosition
+// @Failure 401 {object} model.Problem
+// @Router /stock [get]
+func (s *stockController) GetStockPositions(ctx *gin.Context) {
+
+	format, ok := exportFormat(ctx)
+	if !ok {
+		return
+	}
+	if format != spreadsheet.FormatJSON {
+		s.exportStockPositions(ctx, format)
+		return
+	}
+
+	positions, err := s.stockUsecase.GetStockPositions(ctx.Request.Context())
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, positions)
+}
+
+func (s *stockController) exportStockPositions(ctx *gin.Context, format string) {
+
+	header := []string{"codigo_produto", "nome", "categoria", "unidade", "estoque_atual", "estoque_minimo",
+		"preco_custo", "valor_estoque", "abaixo_minimo"}
+
+	exportTable(ctx, format, "posicao_estoque", "Posição de estoque", header, func(write func([]string) error) error {
+		return s.stockUsecase.EachStockPosition(ctx.Request.Context(), func(position model.StockPosition) error {
+			return write([]string{
+				position.Code,
+				position.Name,
+				position.Category,
+				position.Unit,
+				strconv.Itoa(position.CurrentStock),
+				strconv.Itoa(position.MinimumStock),
+				formatMoney(&position.CostPrice),
+				formatMoney(&position.StockValue),
+				formatBool(position.BelowMinimum),
+			})
+		})
+	})
+}
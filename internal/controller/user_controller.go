@@ -1,12 +1,14 @@
 package controller
 
 import (
+	"APIGolang/internal/apperror"
 	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"APIGolang/internal/spreadsheet"
 	"APIGolang/internal/usecase"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
-	"github.com/go-playground/validator/v10"
 )
 
 type UserController struct {
@@ -19,60 +21,31 @@ func NewUserController(uc *usecase.UserUseCase) *UserController {
 
 // CreateUser godoc
 // @Summary Criar usuário
-// @Description Cria um novo usuário
+// @Description Cria um novo usuário. Restrito a administradores; o papel segue o perfil
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param credentials body model.CreateUserRequest true "Dados do usuário"
 // @Success 201 {object} map[string]string
-// @Failure 400 {object} map[string]interface{}
+// @Failure 400 {object} model.Problem
+// @Failure 403 {object} model.Problem
 // @Router /user/create [post]
 func (userCtrl *UserController) CreateUser(c *gin.Context) {
 
 	var req model.CreateUserRequest
 
-	if err := c.ShouldBindJSON(&req); err != nil {
-
-		// if req.Role != "ADM" {
-		// 	c.JSON(403, gin.H{"error": "acesso negado"})
-		// }
-
-		validationErrors, ok := err.(validator.ValidationErrors)
-		if ok {
-			errors := make(map[string]string)
-
-			for _, filedErr := range validationErrors {
-				switch filedErr.Field() {
-				case "Nome":
-					errors["name"] = "Nome é obrigatório"
-				case "NomeUsuario":
-					errors["username"] = "Nome de usuário é obrigatório"
-				case "Email":
-					errors["email"] = "Email é obrigatório"
-				case "Senha":
-					errors["password"] = "Senha é obrigatório"
-				}
-			}
-
-			c.JSON(400, gin.H{
-				"errors": err.Error() + " aqui ",
-			})
-			return
-		}
-		c.JSON(400, gin.H{"error": "Dados inválidos"})
+	if !bindJSON(c, &req) {
 		return
 	}
 
-	err := userCtrl.usecase.CreateUser(req)
+	err := userCtrl.usecase.CreateUser(c.Request.Context(), req, currentActor(c))
 	if err != nil {
-		c.JSON(400, gin.H{
-			"error": err.Error(),
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(201, gin.H{
-		"message": "Usuário criado com sucesso",
+		"message": translate(c, "Usuário criado com sucesso"),
 	})
 }
 
@@ -81,13 +54,14 @@ func (userCtrl *UserController) CreateUser(c *gin.Context) {
 
 // GetAllUsers godoc
 // @Summary Listar todos usuário
-// @Description Lista todos os usuários que existem
+// @Description Lista todos os usuários que existem. Use ?format=csv|xlsx|pdf ou o header Accept para exportar
 // @Tags User
 // @Accept json
 // @Produce json
+// @Param format query string false "Formato de exportação (csv, xlsx, pdf)"
 // @Success 201 {object} map[string]string
-// @Failure 400 {object} map[string]interface{}
-// @Router /user/getall [post]
+// @Failure 400 {object} model.Problem
+// @Router /user/getAll [get]
 func (userCtrl *UserController) GetAllUsers(c *gin.Context) {
 
 	
@@ -95,15 +69,44 @@ func (userCtrl *UserController) GetAllUsers(c *gin.Context) {
 	// 	c.JSON(403, gin.H{"error": "acesso negado"})
 	// }
 
-	users, err := userCtrl.usecase.GetAllUsers()
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	if format != spreadsheet.FormatJSON {
+		userCtrl.exportUsers(c, format)
+		return
+	}
+
+	users, err := userCtrl.usecase.GetAllUsers(c.Request.Context())
 	if err != nil {
-		c.JSON(400, err)
+		c.Error(err)
+		return
 	}
 
 	c.JSON(200, users)
 
 }
 
+func (userCtrl *UserController) exportUsers(c *gin.Context, format string) {
+
+	header := []string{"id", "nome", "nome_usuario", "email", "perfil", "role", "ativo"}
+
+	exportTable(c, format, "usuarios", "Usuários", header, func(write func([]string) error) error {
+		return userCtrl.usecase.EachUser(c.Request.Context(), func(user model.User) error {
+			return write([]string{
+				strconv.Itoa(user.Id),
+				user.Name,
+				user.Username,
+				user.Email,
+				user.Profile,
+				user.Role,
+				formatBool(user.Active),
+			})
+		})
+	})
+}
+
 // func (userCtrl *UserController) GetUserById(c *gin.Context) {
 
 // 	id := c.Param("id")
@@ -114,27 +117,22 @@ func (userCtrl *UserController) DeleteUserById(c *gin.Context) {
 
 	id := c.Param("id")
 	if id == "" {
-		response := model.Response{
-			Message: "Id do usuário não pode ser nulo",
-		}
-		c.JSON(400, response)
+		c.Error(apperror.Validation("invalid_id", "Id do usuário não pode ser nulo"))
 		return
 	}
 	userId, err := strconv.Atoi(id)
 	if err != nil {
-		response := model.Response{
-			Message: "Id do produto precisa ser um número",
-		}
-		c.JSON(400, response)
+		c.Error(apperror.Validation("invalid_id", "Id do usuário precisa ser um número"))
 		return
 	}
 
-	isSucess, err := userCtrl.usecase.DeleteUserById(userId)
+	isSucess, err := userCtrl.usecase.DeleteUserById(c.Request.Context(), userId, currentActor(c))
 	if err != nil {
-		response := model.Response{
-			Message: "Houve um erro",
-		}
-		c.JSON(400, response)
+		c.Error(err)
+		return
+	}
+	if !isSucess {
+		c.Error(repository.ErrUserNotFound)
 		return
 	}
 
@@ -144,37 +142,30 @@ func (userCtrl *UserController) DeleteUserById(c *gin.Context) {
 func (userCtrl *UserController) UpdateUserById(c *gin.Context) {
 
 	var user model.UpdateUserRequest
-	if err := c.ShouldBindJSON(&user); err != nil {
-		c.JSON(400, gin.H{"error": "Dados inválidos"})
+	if !bindJSON(c, &user) {
 		return
 	}
 
 	id := c.Param("id")
 	if id == "" {
-		response := model.Response{
-			Message: "Id do usuário não pode ser nulo",
-		}
-		c.JSON(400, response)
+		c.Error(apperror.Validation("invalid_id", "Id do usuário não pode ser nulo"))
 		return
 	}
 	userId, err := strconv.Atoi(id)
 	if err != nil {
-		response := model.Response{
-			Message: "Id do produto precisa ser um número",
-		}
-		c.JSON(400, response)
+		c.Error(apperror.Validation("invalid_id", "Id do usuário precisa ser um número"))
 		return
 	}
 
-	isSucess, err := userCtrl.usecase.UpdateUserById(user, userId)
+	isSucess, err := userCtrl.usecase.UpdateUserById(c.Request.Context(), user, userId, currentActor(c))
 	if err != nil {
-		c.JSON(500, err)
+		c.Error(err)
 		return
 	}
 	if !isSucess {
-	c.JSON(404, gin.H{"error": "Usuário não encontrado"})
-	return
-}
+		c.Error(repository.ErrUserNotFound)
+		return
+	}
 
 	c.JSON(200, isSucess)
 }
\ No newline at end of file
@@ -1,12 +1,15 @@
 package db
 
 import (
+	"APIGolang/internal/config"
 	"database/sql"
 	"fmt"
-	"os"
+	"log/slog"
 	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
 )
 
 // DBConnector interface allows dependency injection for testing
@@ -15,11 +18,18 @@ type DBConnector interface {
 	Ping(db *sql.DB) error
 }
 
-// DefaultConnector is the real implementation using sql package
+// DefaultConnector is the real implementation using sql package. Queries are traced with
+// the global tracer provider, as children of the span found in the context they run with
 type DefaultConnector struct{}
 
 func (c *DefaultConnector) Open(driverName, dataSourceName string) (*sql.DB, error) {
-	return sql.Open(driverName, dataSourceName)
+	return otelsql.Open(driverName, dataSourceName,
+		otelsql.WithAttributes(semconv.DBSystemNamePostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{
+			OmitConnResetSession: true,
+			OmitRows:             true,
+		}),
+	)
 }
 
 func (c *DefaultConnector) Ping(db *sql.DB) error {
@@ -27,32 +37,24 @@ func (c *DefaultConnector) Ping(db *sql.DB) error {
 }
 
 // ConnectDB establishes database connection with retry logic and runs migrations
-// Maintains backward compatibility with existing code
-func ConnectDB() (*sql.DB, error) {
-	return ConnectDBWithConnector(&DefaultConnector{})
+func ConnectDB(cfg config.Database) (*sql.DB, error) {
+	return ConnectDBWithConnector(cfg, &DefaultConnector{})
 }
 
 // ConnectDBWithConnector allows dependency injection for testing
-func ConnectDBWithConnector(connector DBConnector) (*sql.DB, error) {
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbname := os.Getenv("DB_NAME")
-
-	// Validate required environment variables
-	if host == "" || port == "" || user == "" || dbname == "" {
-		return nil, fmt.Errorf("missing required environment variables (DB_HOST, DB_PORT, DB_USER, DB_NAME)")
+func ConnectDBWithConnector(cfg config.Database, connector DBConnector) (*sql.DB, error) {
+	// config.Load already validates these, the check protects callers building cfg by hand
+	if cfg.Host == "" || cfg.Port == 0 || cfg.User == "" || cfg.Name == "" {
+		return nil, fmt.Errorf("missing required database settings (host, port, user, name)")
 	}
 
-	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		host, port, user, password, dbname)
-
-	db, err := connector.Open("postgres", psqlInfo)
+	db, err := connector.Open("postgres", cfg.DataSourceName())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
+	configurePool(db, cfg)
+
 	// Retry logic with exponential backoff
 	maxRetries := 3
 	var lastErr error
@@ -64,7 +66,7 @@ func ConnectDBWithConnector(connector DBConnector) (*sql.DB, error) {
 
 		if i < maxRetries-1 {
 			waitTime := time.Duration(1<<uint(i)) * time.Second
-			fmt.Printf("Connection attempt %d failed, retrying in %v...\n", i+1, waitTime)
+			slog.Warn("database connection attempt failed, retrying", "attempt", i+1, "wait", waitTime.String(), "error", lastErr)
 			time.Sleep(waitTime)
 		}
 	}
@@ -74,7 +76,7 @@ func ConnectDBWithConnector(connector DBConnector) (*sql.DB, error) {
 		return nil, fmt.Errorf("failed to ping database after %d attempts: %w", maxRetries, lastErr)
 	}
 
-	fmt.Println("Connected to " + dbname)
+	slog.Info("connected to database", "database", cfg.Name, "host", cfg.Host, "sslmode", cfg.SSLMode)
 
 	// Execute database migrations
 	if err := RunMigrations(db); err != nil {
@@ -84,3 +86,33 @@ func ConnectDBWithConnector(connector DBConnector) (*sql.DB, error) {
 
 	return db, nil
 }
+
+// ConnectReplica opens the pool of the read replica. It does not wait for the replica:
+// while it is unreachable the Resolver keeps reads on the primary
+func ConnectReplica(cfg config.Database) (*sql.DB, error) {
+	return ConnectReplicaWithConnector(cfg, &DefaultConnector{})
+}
+
+// ConnectReplicaWithConnector allows dependency injection for testing
+func ConnectReplicaWithConnector(cfg config.Database, connector DBConnector) (*sql.DB, error) {
+	replica, err := cfg.Replica()
+	if err != nil {
+		return nil, fmt.Errorf("invalid replica settings: %w", err)
+	}
+
+	db, err := connector.Open("postgres", replica.DataSourceName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replica: %w", err)
+	}
+	configurePool(db, replica)
+
+	slog.Info("using read replica", "database", replica.Name, "host", replica.Host, "sslmode", replica.SSLMode)
+	return db, nil
+}
+
+func configurePool(db *sql.DB, cfg config.Database) {
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+}
@@ -1,9 +1,9 @@
 package db
 
 import (
+	"APIGolang/internal/config"
 	"database/sql"
 	"errors"
-	"os"
 	"strings"
 	"testing"
 
@@ -49,12 +49,18 @@ func (m *MockConnector) Ping(db *sql.DB) error {
 	return m.pingErr
 }
 
+func testDatabaseConfig() config.Database {
+	return config.Database{
+		Host:     "localhost",
+		Port:     5432,
+		User:     "testuser",
+		Password: "testpass",
+		Name:     "testdb",
+	}
+}
+
 func TestConnectDB_Success(t *testing.T) {
-	t.Setenv("DB_HOST", "localhost")
-	t.Setenv("DB_PORT", "5432")
-	t.Setenv("DB_USER", "testuser")
-	t.Setenv("DB_PASSWORD", "testpass")
-	t.Setenv("DB_NAME", "testdb")
+	cfg := testDatabaseConfig()
 
 	mock := NewMockConnector()
 	defer mock.db.Close()
@@ -63,7 +69,7 @@ func TestConnectDB_Success(t *testing.T) {
 	mock.mock.ExpectQuery(`SELECT version, dirty FROM "schema_migrations" LIMIT 1`).
 		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, false))
 
-	db, err := ConnectDBWithConnector(mock)
+	db, err := ConnectDBWithConnector(cfg, mock)
 
 	// We expect an error because migration files won't be found
 	// But we verify the connection logic worked
@@ -76,93 +82,58 @@ func TestConnectDB_Success(t *testing.T) {
 	}
 }
 
-func TestConnectDB_MissingEnvironmentVariables(t *testing.T) {
+func TestConnectDB_MissingSettings(t *testing.T) {
 	tests := []struct {
 		name        string
-		envVars     map[string]string
+		change      func(cfg *config.Database)
 		expectError bool
 	}{
 		{
-			name: "missing DB_HOST",
-			envVars: map[string]string{
-				"DB_PORT":     "5432",
-				"DB_USER":     "testuser",
-				"DB_PASSWORD": "testpass",
-				"DB_NAME":     "testdb",
-			},
+			name:        "missing host",
+			change:      func(cfg *config.Database) { cfg.Host = "" },
 			expectError: true,
 		},
 		{
-			name: "missing DB_PORT",
-			envVars: map[string]string{
-				"DB_HOST":     "localhost",
-				"DB_USER":     "testuser",
-				"DB_PASSWORD": "testpass",
-				"DB_NAME":     "testdb",
-			},
+			name:        "missing port",
+			change:      func(cfg *config.Database) { cfg.Port = 0 },
 			expectError: true,
 		},
 		{
-			name: "missing DB_USER",
-			envVars: map[string]string{
-				"DB_HOST":     "localhost",
-				"DB_PORT":     "5432",
-				"DB_PASSWORD": "testpass",
-				"DB_NAME":     "testdb",
-			},
+			name:        "missing user",
+			change:      func(cfg *config.Database) { cfg.User = "" },
 			expectError: true,
 		},
 		{
-			name: "missing DB_NAME",
-			envVars: map[string]string{
-				"DB_HOST":     "localhost",
-				"DB_PORT":     "5432",
-				"DB_USER":     "testuser",
-				"DB_PASSWORD": "testpass",
-			},
+			name:        "missing name",
+			change:      func(cfg *config.Database) { cfg.Name = "" },
 			expectError: true,
 		},
 		{
-			name: "all variables present",
-			envVars: map[string]string{
-				"DB_HOST":     "localhost",
-				"DB_PORT":     "5432",
-				"DB_USER":     "testuser",
-				"DB_PASSWORD": "testpass",
-				"DB_NAME":     "testdb",
-			},
+			name:        "all settings present",
+			change:      func(cfg *config.Database) {},
 			expectError: false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Clear all env vars first
-			os.Unsetenv("DB_HOST")
-			os.Unsetenv("DB_PORT")
-			os.Unsetenv("DB_USER")
-			os.Unsetenv("DB_PASSWORD")
-			os.Unsetenv("DB_NAME")
-
-			// Set test env vars
-			for key, value := range tt.envVars {
-				t.Setenv(key, value)
-			}
+			cfg := testDatabaseConfig()
+			tt.change(&cfg)
 
 			mock := NewMockConnector()
 			defer mock.db.Close()
 
-			db, err := ConnectDBWithConnector(mock)
+			db, err := ConnectDBWithConnector(cfg, mock)
 
 			if tt.expectError {
 				if err == nil {
-					t.Error("expected error for missing env vars, got nil")
+					t.Error("expected error for missing settings, got nil")
 				}
-				if err != nil && !strings.Contains(err.Error(), "missing required environment variables") {
-					t.Errorf("expected missing env vars error, got: %v", err)
+				if err != nil && !strings.Contains(err.Error(), "missing required database settings") {
+					t.Errorf("expected missing settings error, got: %v", err)
 				}
 			} else {
-				// Even with all vars, we expect migration error
+				// Even with all settings, we expect migration error
 				if err != nil && !strings.Contains(err.Error(), "migrate") {
 					t.Errorf("unexpected error: %v", err)
 				}
@@ -176,17 +147,13 @@ func TestConnectDB_MissingEnvironmentVariables(t *testing.T) {
 }
 
 func TestConnectDB_OpenError(t *testing.T) {
-	t.Setenv("DB_HOST", "localhost")
-	t.Setenv("DB_PORT", "5432")
-	t.Setenv("DB_USER", "testuser")
-	t.Setenv("DB_PASSWORD", "testpass")
-	t.Setenv("DB_NAME", "testdb")
+	cfg := testDatabaseConfig()
 
 	mock := NewMockConnector()
 	mock.openErr = errors.New("connection refused")
 	defer mock.db.Close()
 
-	db, err := ConnectDBWithConnector(mock)
+	db, err := ConnectDBWithConnector(cfg, mock)
 
 	if err == nil {
 		t.Error("expected error for open failure, got nil")
@@ -202,18 +169,14 @@ func TestConnectDB_OpenError(t *testing.T) {
 }
 
 func TestConnectDB_PingError(t *testing.T) {
-	t.Setenv("DB_HOST", "localhost")
-	t.Setenv("DB_PORT", "5432")
-	t.Setenv("DB_USER", "testuser")
-	t.Setenv("DB_PASSWORD", "testpass")
-	t.Setenv("DB_NAME", "testdb")
+	cfg := testDatabaseConfig()
 
 	mock := NewMockConnector()
 	mock.pingErr = errors.New("connection timeout")
 	mock.maxRetries = 0 // Don't allow retries to succeed
 	defer mock.db.Close()
 
-	db, err := ConnectDBWithConnector(mock)
+	db, err := ConnectDBWithConnector(cfg, mock)
 
 	if err == nil {
 		t.Error("expected error for ping failure, got nil")
@@ -229,11 +192,7 @@ func TestConnectDB_PingError(t *testing.T) {
 }
 
 func TestConnectDB_RetryLogic(t *testing.T) {
-	t.Setenv("DB_HOST", "localhost")
-	t.Setenv("DB_PORT", "5432")
-	t.Setenv("DB_USER", "testuser")
-	t.Setenv("DB_PASSWORD", "testpass")
-	t.Setenv("DB_NAME", "testdb")
+	cfg := testDatabaseConfig()
 
 	mock := NewMockConnector()
 	mock.pingErr = errors.New("temporary failure")
@@ -244,7 +203,7 @@ func TestConnectDB_RetryLogic(t *testing.T) {
 	mock.mock.ExpectQuery(`SELECT version, dirty FROM "schema_migrations" LIMIT 1`).
 		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(1, false))
 
-	db, err := ConnectDBWithConnector(mock)
+	db, err := ConnectDBWithConnector(cfg, mock)
 
 	// Should succeed after retries (but fail on migrations)
 	if err != nil && !strings.Contains(err.Error(), "migrate") {
@@ -261,11 +220,7 @@ func TestConnectDB_RetryLogic(t *testing.T) {
 }
 
 func TestConnectDB_MigrationError(t *testing.T) {
-	t.Setenv("DB_HOST", "localhost")
-	t.Setenv("DB_PORT", "5432")
-	t.Setenv("DB_USER", "testuser")
-	t.Setenv("DB_PASSWORD", "testpass")
-	t.Setenv("DB_NAME", "testdb")
+	cfg := testDatabaseConfig()
 
 	mock := NewMockConnector()
 	defer mock.db.Close()
@@ -274,7 +229,7 @@ func TestConnectDB_MigrationError(t *testing.T) {
 	mock.mock.ExpectQuery(`SELECT version, dirty FROM "schema_migrations" LIMIT 1`).
 		WillReturnRows(sqlmock.NewRows([]string{"version", "dirty"}).AddRow(2, true))
 
-	db, err := ConnectDBWithConnector(mock)
+	db, err := ConnectDBWithConnector(cfg, mock)
 
 	if err == nil {
 		t.Error("expected migration error, got nil")
@@ -289,46 +244,3 @@ func TestConnectDB_MigrationError(t *testing.T) {
 		db.Close()
 	}
 }
-
-func TestConnectDB_EnvironmentVariables(t *testing.T) {
-	tests := []struct {
-		name    string
-		envVars map[string]string
-	}{
-		{
-			name: "standard configuration",
-			envVars: map[string]string{
-				"DB_HOST":     "localhost",
-				"DB_PORT":     "5432",
-				"DB_USER":     "testuser",
-				"DB_PASSWORD": "testpass",
-				"DB_NAME":     "testdb",
-			},
-		},
-		{
-			name: "docker configuration",
-			envVars: map[string]string{
-				"DB_HOST":     "go_db",
-				"DB_PORT":     "5432",
-				"DB_USER":     "postgres",
-				"DB_PASSWORD": "secret123",
-				"DB_NAME":     "mercado",
-			},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			for key, value := range tt.envVars {
-				t.Setenv(key, value)
-			}
-
-			for key, expectedValue := range tt.envVars {
-				actualValue := os.Getenv(key)
-				if actualValue != expectedValue {
-					t.Errorf("expected %s=%s, got %s", key, expectedValue, actualValue)
-				}
-			}
-		})
-	}
-}
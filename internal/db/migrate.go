@@ -1,32 +1,30 @@
 package db
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"log"
-	"os"
+	"log/slog"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
-// buildDatabaseURL constructs PostgreSQL connection string from environment variables
-func buildDatabaseURL() string {
-	host := os.Getenv("DB_HOST")
-	port := os.Getenv("DB_PORT")
-	user := os.Getenv("DB_USER")
-	password := os.Getenv("DB_PASSWORD")
-	dbname := os.Getenv("DB_NAME")
-
-	return fmt.Sprintf("postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		user, password, host, port, dbname)
-}
-
 // createMigrateInstance creates and configures a migrate instance
+// It runs on a dedicated connection: postgres.WithInstance would make m.Close()
+// close the whole pool the application keeps using
 func createMigrateInstance(db *sql.DB) (*migrate.Migrate, error) {
-	driver, err := postgres.WithInstance(db, &postgres.Config{})
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database connection: %w", err)
+	}
+
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
 	if err != nil {
+		conn.Close()
 		return nil, fmt.Errorf("failed to create postgres driver: %w", err)
 	}
 
@@ -36,6 +34,7 @@ func createMigrateInstance(db *sql.DB) (*migrate.Migrate, error) {
 		driver,
 	)
 	if err != nil {
+		driver.Close()
 		return nil, fmt.Errorf("failed to create migrate instance: %w", err)
 	}
 
@@ -47,20 +46,20 @@ func logMigrationStatus(m *migrate.Migrate) {
 	version, dirty, err := m.Version()
 	if err != nil {
 		if err == migrate.ErrNilVersion {
-			log.Println("[MIGRATE] Current version: none (fresh database)")
+			slog.Info("migration status", "version", "none (fresh database)")
 		} else {
-			log.Printf("[MIGRATE] Failed to get version: %v", err)
+			slog.Warn("failed to get migration version", "error", err)
 		}
 		return
 	}
 
-	log.Printf("[MIGRATE] Current version: %d, Dirty: %t", version, dirty)
+	slog.Info("migration status", "version", version, "dirty", dirty)
 }
 
 // RunMigrations executes all pending database migrations
 // Returns error if migrations fail or database is in dirty state
 func RunMigrations(db *sql.DB) error {
-	log.Println("[MIGRATE] Starting database migrations")
+	slog.Info("starting database migrations")
 
 	// Create migrate instance
 	m, err := createMigrateInstance(db)
@@ -83,10 +82,10 @@ func RunMigrations(db *sql.DB) error {
 	}
 
 	// Execute pending migrations
-	log.Println("[MIGRATE] Executing pending migrations...")
+	slog.Info("executing pending migrations")
 	if err := m.Up(); err != nil {
 		if err == migrate.ErrNoChange {
-			log.Println("[MIGRATE] No pending migrations found")
+			slog.Info("no pending migrations found")
 			return nil
 		}
 		return fmt.Errorf("migration failed: %w", err)
@@ -95,9 +94,9 @@ func RunMigrations(db *sql.DB) error {
 	// Log completion with final version
 	finalVersion, _, err := m.Version()
 	if err != nil {
-		log.Println("[MIGRATE] Database migrations completed successfully")
+		slog.Info("database migrations completed")
 	} else {
-		log.Printf("[MIGRATE] Database migrations completed successfully (version: %d)", finalVersion)
+		slog.Info("database migrations completed", "version", finalVersion)
 	}
 
 	return nil
@@ -110,7 +109,7 @@ func RollbackMigrations(db *sql.DB, steps int) error {
 		return fmt.Errorf("steps must be a positive integer, got: %d", steps)
 	}
 
-	log.Printf("[MIGRATE] Starting rollback of %d migration(s)", steps)
+	slog.Info("starting migration rollback", "steps", steps)
 
 	// Create migrate instance
 	m, err := createMigrateInstance(db)
@@ -132,10 +131,10 @@ func RollbackMigrations(db *sql.DB, steps int) error {
 	}
 
 	// Execute rollback using Steps (negative value for down migrations)
-	log.Printf("[MIGRATE] Executing rollback from version %d...", versionBefore)
+	slog.Info("executing migration rollback", "from_version", versionBefore)
 	if err := m.Steps(-steps); err != nil {
 		if err == migrate.ErrNoChange {
-			log.Println("[MIGRATE] No migrations to rollback")
+			slog.Info("no migrations to rollback")
 			return nil
 		}
 		return fmt.Errorf("rollback failed: %w", err)
@@ -145,12 +144,12 @@ func RollbackMigrations(db *sql.DB, steps int) error {
 	versionAfter, _, err := m.Version()
 	if err != nil {
 		if err == migrate.ErrNilVersion {
-			log.Println("[MIGRATE] Rollback completed successfully (database at version 0)")
+			slog.Info("migration rollback completed", "version", 0)
 		} else {
-			log.Println("[MIGRATE] Rollback completed successfully")
+			slog.Info("migration rollback completed")
 		}
 	} else {
-		log.Printf("[MIGRATE] Rollback completed successfully (version: %d -> %d)", versionBefore, versionAfter)
+		slog.Info("migration rollback completed", "from_version", versionBefore, "version", versionAfter)
 	}
 
 	return nil
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync/atomic"
+	"time"
+)
+
+// replicaLagQuery tells whether the replica streams WAL from the primary and measures in
+// seconds how far behind it is. A streaming replica that replayed everything it received is up
+// to date, however old its last transaction: that is the case while the primary has no writes.
+// One whose receiver is disconnected has nothing left to replay but is not up to date. The
+// receiver status is only visible to superusers and members of pg_read_all_stats; for other
+// users the replica never counts as streaming and reads stay on the primary
+const replicaLagQuery = "SELECT NOT pg_is_in_recovery()" +
+	" OR EXISTS (SELECT 1 FROM pg_stat_wal_receiver WHERE status = 'streaming'), CASE" +
+	" WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0" +
+	" ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) END"
+
+// Resolver routes read-only queries to a read replica while it answers its periodic check
+// and lags at most maxLag behind, falling back to the primary otherwise
+type Resolver struct {
+	primary  *sql.DB
+	replica  *sql.DB
+	maxLag   time.Duration
+	interval time.Duration
+	healthy  atomic.Bool
+	// checked is only used by Run, to report a replica that is down from the start
+	checked bool
+}
+
+// NewResolver builds a resolver that reads from the primary until the first check of the
+// replica passes. Without a replica every read goes to the primary
+func NewResolver(primary, replica *sql.DB, maxLag, interval time.Duration) *Resolver {
+	return &Resolver{
+		primary:  primary,
+		replica:  replica,
+		maxLag:   maxLag,
+		interval: interval,
+	}
+}
+
+// Reader returns the pool for queries that tolerate replication lag
+func (r *Resolver) Reader() *sql.DB {
+	if r.healthy.Load() {
+		return r.replica
+	}
+	return r.primary
+}
+
+// Run blocks checking the replica every interval until ctx is cancelled
+func (r *Resolver) Run(ctx context.Context) {
+	if r.replica == nil {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.check(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.check(ctx)
+		}
+	}
+}
+
+// check logs the first result and then only the transitions, not every failed check
+func (r *Resolver) check(ctx context.Context) {
+	checkCtx, cancel := context.WithTimeout(ctx, r.interval)
+	defer cancel()
+
+	var streaming bool
+	var seconds float64
+	err := r.replica.QueryRowContext(checkCtx, replicaLagQuery).Scan(&streaming, &seconds)
+	if ctx.Err() != nil {
+		// Shutting down, the replica is not to blame
+		return
+	}
+	lag := time.Duration(seconds * float64(time.Second))
+	healthy := err == nil && streaming && lag <= r.maxLag
+
+	wasHealthy := r.healthy.Swap(healthy)
+	first := !r.checked
+	r.checked = true
+	if healthy == wasHealthy && !first {
+		return
+	}
+
+	switch {
+	case healthy:
+		slog.InfoContext(ctx, "reading from the replica", "lag", lag.String())
+	case err != nil:
+		slog.WarnContext(ctx, "read replica unavailable, reading from the primary", "error", err)
+	case !streaming:
+		slog.WarnContext(ctx, "read replica disconnected from the primary, reading from the primary")
+	default:
+		slog.WarnContext(ctx, "read replica lagging, reading from the primary", "lag", lag.String(), "max_lag", r.maxLag.String())
+	}
+}
@@ -0,0 +1,76 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestResolver_WithoutReplica(t *testing.T) {
+	primary, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock database: %v", err)
+	}
+	defer primary.Close()
+
+	resolver := NewResolver(primary, nil, time.Second, time.Second)
+	resolver.Run(context.Background())
+
+	if resolver.Reader() != primary {
+		t.Error("expected reads on the primary without a replica")
+	}
+}
+
+func TestResolver_Check(t *testing.T) {
+	primary, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock database: %v", err)
+	}
+	defer primary.Close()
+	replica, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock database: %v", err)
+	}
+	defer replica.Close()
+
+	resolver := NewResolver(primary, replica, 10*time.Second, time.Second)
+	if resolver.Reader() != primary {
+		t.Fatal("expected reads on the primary before the first check")
+	}
+
+	steps := []struct {
+		name      string
+		streaming bool
+		lag       float64
+		err       error
+		replica   bool
+	}{
+		{name: "up to date", streaming: true, lag: 0.5, replica: true},
+		{name: "lagging", streaming: true, lag: 42, replica: false},
+		{name: "caught up", streaming: true, lag: 3, replica: true},
+		{name: "disconnected", streaming: false, lag: 0, replica: false},
+		{name: "reconnected", streaming: true, lag: 0, replica: true},
+		{name: "unreachable", err: errors.New("connection refused"), replica: false},
+	}
+
+	for _, step := range steps {
+		query := mock.ExpectQuery(regexp.QuoteMeta(replicaLagQuery))
+		if step.err != nil {
+			query.WillReturnError(step.err)
+		} else {
+			query.WillReturnRows(sqlmock.NewRows([]string{"streaming", "lag"}).AddRow(step.streaming, step.lag))
+		}
+		resolver.check(context.Background())
+
+		if got := resolver.Reader() == replica; got != step.replica {
+			t.Errorf("%s: expected reads on the replica to be %v", step.name, step.replica)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"APIGolang/internal/model"
+	"context"
+	"sync"
+	"time"
+)
+
+// Check probes one dependency, returning details to include in the report
+// A non-nil error marks the dependency as down
+type Check func(ctx context.Context) (map[string]any, error)
+
+// Checker runs the registered checks concurrently, each one bounded by timeout
+type Checker struct {
+	readiness *Readiness
+	timeout   time.Duration
+	names     []string
+	checks    map[string]Check
+}
+
+func NewChecker(readiness *Readiness, timeout time.Duration) *Checker {
+	return &Checker{
+		readiness: readiness,
+		timeout:   timeout,
+		checks:    make(map[string]Check),
+	}
+}
+
+func (c *Checker) Register(name string, check Check) {
+	c.names = append(c.names, name)
+	c.checks[name] = check
+}
+
+// Liveness only tells the process is serving requests. It never looks at dependencies,
+// otherwise a database outage would make the orchestrator restart every instance
+func (c *Checker) Liveness() model.HealthReport {
+	return model.HealthReport{Status: model.HealthUp}
+}
+
+// Readiness reports every dependency and whether the instance should receive traffic
+// During shutdown the checks are skipped, the instance is leaving anyway
+func (c *Checker) Readiness(ctx context.Context) (model.HealthReport, bool) {
+
+	if !c.readiness.Ready() {
+		return model.HealthReport{Status: model.HealthShuttingDown}, false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	results := make([]model.HealthCheck, len(c.names))
+	var wg sync.WaitGroup
+	for i, name := range c.names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = run(ctx, c.checks[name])
+		}()
+	}
+	wg.Wait()
+
+	report := model.HealthReport{Status: model.HealthUp, Checks: make(map[string]model.HealthCheck, len(c.names))}
+	for i, name := range c.names {
+		report.Checks[name] = results[i]
+		if results[i].Status != model.HealthUp {
+			report.Status = model.HealthDown
+		}
+	}
+	return report, report.Status == model.HealthUp
+}
+
+// run gives up on checks that ignore ctx once it expires, so a stuck dependency
+// can't hold the readiness probe beyond the timeout
+func run(ctx context.Context, check Check) model.HealthCheck {
+
+	type outcome struct {
+		details map[string]any
+		err     error
+	}
+
+	start := time.Now()
+	done := make(chan outcome, 1)
+	go func() {
+		details, err := check(ctx)
+		done <- outcome{details, err}
+	}()
+
+	var result outcome
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result.err = ctx.Err()
+	}
+
+	status := model.HealthCheck{
+		Status:    model.HealthUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Details:   result.details,
+	}
+	if result.err != nil {
+		status.Status = model.HealthDown
+		status.Error = result.err.Error()
+	}
+	return status
+}
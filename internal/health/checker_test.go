@@ -0,0 +1,74 @@
+package health
+
+import (
+	"APIGolang/internal/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func up(ctx context.Context) (map[string]any, error) {
+	return map[string]any{"version": 11}, nil
+}
+
+func TestReadiness(t *testing.T) {
+	readiness := &Readiness{}
+	readiness.SetReady(true)
+
+	checker := NewChecker(readiness, 50*time.Millisecond)
+	checker.Register("database", up)
+
+	report, ready := checker.Readiness(context.Background())
+	if !ready || report.Status != model.HealthUp {
+		t.Fatalf("expected ready, got %+v", report)
+	}
+	if report.Checks["database"].Details["version"] != 11 {
+		t.Errorf("details must be reported: %+v", report.Checks["database"])
+	}
+
+	checker.Register("cache", func(ctx context.Context) (map[string]any, error) {
+		return nil, errors.New("connection refused")
+	})
+	report, ready = checker.Readiness(context.Background())
+	if ready || report.Status != model.HealthDown {
+		t.Fatalf("expected not ready, got %+v", report)
+	}
+	if report.Checks["database"].Status != model.HealthUp || report.Checks["cache"].Error != "connection refused" {
+		t.Errorf("each dependency must be reported on its own: %+v", report.Checks)
+	}
+}
+
+func TestReadiness_Timeout(t *testing.T) {
+	readiness := &Readiness{}
+	readiness.SetReady(true)
+
+	checker := NewChecker(readiness, 20*time.Millisecond)
+	// Ignores ctx on purpose, the checker must not wait for it
+	checker.Register("database", func(ctx context.Context) (map[string]any, error) {
+		time.Sleep(time.Second)
+		return nil, nil
+	})
+
+	start := time.Now()
+	report, ready := checker.Readiness(context.Background())
+	if ready || report.Checks["database"].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("expected a timeout, got %+v", report)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("readiness took %s, the timeout was not enforced", elapsed)
+	}
+}
+
+func TestReadiness_ShuttingDown(t *testing.T) {
+	checker := NewChecker(&Readiness{}, time.Second)
+	checker.Register("database", func(ctx context.Context) (map[string]any, error) {
+		t.Error("checks must not run during shutdown")
+		return nil, nil
+	})
+
+	report, ready := checker.Readiness(context.Background())
+	if ready || report.Status != model.HealthShuttingDown {
+		t.Errorf("expected shutting down, got %+v", report)
+	}
+}
@@ -0,0 +1,42 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// DatabaseCheck pings the database and reports the pool usage
+func DatabaseCheck(conn *sql.DB) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		if err := conn.PingContext(ctx); err != nil {
+			return nil, err
+		}
+		stats := conn.Stats()
+		return map[string]any{
+			"open_connections": stats.OpenConnections,
+			"in_use":           stats.InUse,
+			"idle":             stats.Idle,
+		}, nil
+	}
+}
+
+// MigrationCheck reports the schema version, a dirty migration means a failed
+// run that needs manual intervention, so the instance is not ready. The version is
+// read straight from the migrate table, a migrate instance would take its lock
+func MigrationCheck(conn *sql.DB) Check {
+	return func(ctx context.Context) (map[string]any, error) {
+		var version int64
+		var dirty bool
+		err := conn.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations").Scan(&version, &dirty)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		details := map[string]any{"version": version, "dirty": dirty}
+		if dirty {
+			return details, fmt.Errorf("migration %d is dirty", version)
+		}
+		return details, nil
+	}
+}
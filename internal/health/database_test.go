@@ -0,0 +1,43 @@
+package health
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestMigrationCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		rows    *sqlmock.Rows
+		want    int64
+		wantErr bool
+	}{
+		{"clean", sqlmock.NewRows([]string{"version", "dirty"}).AddRow(12, false), 12, false},
+		{"dirty", sqlmock.NewRows([]string{"version", "dirty"}).AddRow(12, true), 12, true},
+		{"no migration applied", sqlmock.NewRows([]string{"version", "dirty"}), 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+			if err != nil {
+				t.Fatalf("failed to create mock database: %v", err)
+			}
+			defer db.Close()
+			mock.ExpectQuery("SELECT version, dirty FROM schema_migrations").WillReturnRows(tt.rows)
+
+			details, err := MigrationCheck(db)(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got error %v, want error %v", err, tt.wantErr)
+			}
+			if details["version"] != tt.want {
+				t.Errorf("got version %v, want %d", details["version"], tt.want)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
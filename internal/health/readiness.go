@@ -0,0 +1,18 @@
+// Package health tracks whether this instance can receive traffic
+package health
+
+import "sync/atomic"
+
+// Readiness starts false, becomes true once the server is listening and goes back
+// to false as soon as shutdown begins, so the orchestrator stops routing to it
+type Readiness struct {
+	ready atomic.Bool
+}
+
+func (r *Readiness) SetReady(ready bool) {
+	r.ready.Store(ready)
+}
+
+func (r *Readiness) Ready() bool {
+	return r.ready.Load()
+}
@@ -0,0 +1,179 @@
+package i18n
+
+// enUS translates the pt-BR source messages, which are also the catalog keys.
+// Format verbs must appear in the same order as in the key
+var enUS = map[string]string{
+	// Generic
+	"Erro interno do servidor":                                     "Internal server error",
+	"Dados inválidos":                                              "Invalid data",
+	"Dados inválidos nos campos informados":                        "Invalid data in the fields informed",
+	"É necessário preencher ao menos um campo para ser atualizado": "At least one field must be filled in to be updated",
+	"dry_run precisa ser true ou false":                            "dry_run must be true or false",
+	"Arquivo não informado":                                        "File not informed",
+	"Não foi possível ler o arquivo":                               "The file could not be read",
+	"Não foi possível ler a planilha: %s":                          "The spreadsheet could not be read: %s",
+	"O arquivo está vazio":                                         "The file is empty",
+	"Formato não suportado, use csv, xlsx ou pdf":                  "Unsupported format, use csv, xlsx or pdf",
+	"Formato de arquivo não suportado, use csv ou xlsx":            "Unsupported file format, use csv or xlsx",
+	"a coluna %s foi informada mais de uma vez":                    "the column %s was informed more than once",
+	"colunas obrigatórias ausentes: %s":                            "missing required columns: %s",
+
+	// Identifiers and query parameters
+	"Id do produto não pode ser nulo":                         "Product id cannot be empty",
+	"Id do produto precisa ser um número":                     "Product id must be a number",
+	"Id do usuário não pode ser nulo":                         "User id cannot be empty",
+	"Id do usuário precisa ser um número":                     "User id must be a number",
+	"Id da categoria precisa ser um número":                   "Category id must be a number",
+	"Id da promoção precisa ser um número":                    "Promotion id must be a number",
+	"Id do agendamento precisa ser um número":                 "Schedule id must be a number",
+	"Id do fornecedor precisa ser um número":                  "Supplier id must be a number",
+	"Id do inventário precisa ser um número":                  "Inventory id must be a number",
+	"Id do lote precisa ser um número":                        "Lot id must be a number",
+	"Id do pedido precisa ser um número":                      "Order id must be a number",
+	"Data inicial inválida, use o formato AAAA-MM-DD":         "Invalid start date, use the YYYY-MM-DD format",
+	"Data final inválida, use o formato AAAA-MM-DD":           "Invalid end date, use the YYYY-MM-DD format",
+	"A data final deve ser posterior à data inicial":          "The end date must be after the start date",
+	"A data final deve ser igual ou posterior à data inicial": "The end date must be equal to or after the start date",
+	"A quantidade de dias precisa ser um número":              "The number of days must be a number",
+	"A quantidade de dias deve ser maior que zero":            "The number of days must be greater than zero",
+	"A quantidade de dias não pode ser negativa":              "The number of days cannot be negative",
+	"O limite precisa ser um número":                          "The limit must be a number",
+	"O limite deve estar entre 1 e %d":                        "The limit must be between 1 and %d",
+	"O deslocamento precisa ser um número":                    "The offset must be a number",
+	"O deslocamento não pode ser negativo":                    "The offset cannot be negative",
+
+	// Authentication and users
+	"token não informado":          "token not informed",
+	"token inválido":               "invalid token",
+	"claims inválidas":             "invalid claims",
+	"acesso negado":                "access denied",
+	"credenciais inválidas":        "invalid credentials",
+	"refresh token não encontrado": "refresh token not found",
+	"refresh token inválido":       "invalid refresh token",
+	"usuário não encontrado":       "user not found",
+	"Usuário não identificado":     "User not identified",
+	"O usuário está inativo":       "The user is inactive",
+	"Login bloqueado por excesso de tentativas, tente novamente mais tarde": "Login locked after too many attempts, try again later",
+	"Nome de usuário já cadastrado":                                         "Username already registered",
+	"Esse email já está cadastrado":                                         "This email is already registered",
+	"Esse email não existe":                                                 "This email does not exist",
+	"Usuário criado com sucesso":                                            "User created successfully",
+	"Senha alterada com sucesso":                                            "Password changed successfully",
+
+	// Products and prices
+	"Produto não foi encontrado na base de dados":       "Product not found in the database",
+	"O produto foi deletado com sucesso":                "The product was deleted successfully",
+	"Produto %d não encontrado":                         "Product %d not found",
+	"O produto %s está inativo":                         "The product %s is inactive",
+	"Categoria não encontrada":                          "Category not found",
+	"Categoria sem regra de preço":                      "Category without a pricing rule",
+	"Arredondamento inválido, use NENHUM, X_99 ou X_49": "Invalid rounding, use NENHUM, X_99 or X_49",
+	"O markup alvo não pode ser negativo":               "The target markup cannot be negative",
+	"A margem mínima deve estar entre 0 e 100":          "The minimum margin must be between 0 and 100",
+	"O preço de venda deve ser maior que zero":          "The sale price must be greater than zero",
+	"O preço de custo não pode ser negativo":            "The cost price cannot be negative",
+	"A data de vigência deve estar no futuro":           "The effective date must be in the future",
+	"Preço agendado abaixo da margem mínima":            "Scheduled price below the minimum margin",
+	"Agendamento pendente não encontrado":               "Pending schedule not found",
+	"Agendamento cancelado com sucesso":                 "Schedule cancelled successfully",
+
+	// Product import rows
+	"código repetido, já informado na linha %d":              "repeated code, already informed on row %d",
+	"código de barras repetido, já informado na linha %d":    "repeated barcode, already informed on row %d",
+	"código de barras já pertence ao produto %s":             "barcode already belongs to product %s",
+	"código do produto é obrigatório":                        "product code is required",
+	"código do produto deve ter no máximo 30 caracteres":     "product code must have at most 30 characters",
+	"código de barras inválido":                              "invalid barcode",
+	"nome é obrigatório":                                     "name is required",
+	"nome deve ter no máximo 100 caracteres":                 "name must have at most 100 characters",
+	"categoria é obrigatória":                                "category is required",
+	"categoria %q não encontrada":                            "category %q not found",
+	"fornecedor %q não encontrado":                           "supplier %q not found",
+	"preço de custo inválido":                                "invalid cost price",
+	"preço de custo não pode ser negativo":                   "cost price cannot be negative",
+	"preço de venda inválido":                                "invalid sale price",
+	"preço de venda deve ser maior que zero":                 "sale price must be greater than zero",
+	"unidade de medida deve ter no máximo 10 caracteres":     "unit of measure must have at most 10 characters",
+	"estoque atual deve ser um número inteiro não negativo":  "current stock must be a non-negative integer",
+	"estoque mínimo deve ser um número inteiro não negativo": "minimum stock must be a non-negative integer",
+	"valor deve ser sim ou não":                              "value must be sim or não",
+
+	// Promotions
+	"Informe os produtos da promoção":                          "Inform the products of the promotion",
+	"Informe a categoria da promoção":                          "Inform the category of the promotion",
+	"Informe o preço fixo da promoção":                         "Inform the fixed price of the promotion",
+	"Informe o preço do combo":                                 "Inform the combo price",
+	"O combo precisa de ao menos dois produtos":                "The combo needs at least two products",
+	"O percentual de desconto deve estar entre 0 e 100":        "The discount percentage must be between 0 and 100",
+	"A quantidade levada deve ser maior que a quantidade paga": "The quantity taken must be greater than the quantity paid",
+	"Produto %d informado mais de uma vez":                     "Product %d informed more than once",
+	"Tipo de promoção inválido: %s":                            "Invalid promotion type: %s",
+	"Promoção ativa não encontrada":                            "Active promotion not found",
+	"Promoção encerrada com sucesso":                           "Promotion ended successfully",
+
+	// Sales
+	"Caixa não encontrado":                          "Cash register not found",
+	"O caixa não está aberto":                       "The cash register is not open",
+	"A venda precisa ter ao menos um item":          "The sale must have at least one item",
+	"Informe ao menos um pagamento":                 "Inform at least one payment",
+	"O valor do pagamento deve ser maior que zero":  "The payment amount must be greater than zero",
+	"Pagamento insuficiente: total %.2f, pago %.2f": "Insufficient payment: total %.2f, paid %.2f",
+	"Quantidade inválida para o produto %d":         "Invalid quantity for product %d",
+	"Estoque insuficiente":                          "Insufficient stock",
+	"Estoque insuficiente para o produto %s":        "Insufficient stock for product %s",
+	"Existem itens abaixo da margem mínima, é necessária a autorização de um gerente": "There are items below the minimum margin, a manager authorization is required",
+	"O usuário informado não tem permissão para autorizar a venda":                    "The user informed is not allowed to authorize the sale",
+
+	// Reports
+	"Agrupamento inválido, use day, week, month, user, payment_method ou category": "Invalid grouping, use day, week, month, user, payment_method or category",
+	"Os limites precisam ser números":                                              "The limits must be numbers",
+	"Os limites devem respeitar 0 < limite A < limite B < 100":                     "The limits must satisfy 0 < limit A < limit B < 100",
+
+	// Purchases and invoices
+	"Os períodos precisam ser números":                                     "The periods must be numbers",
+	"Os períodos de vendas e de cobertura devem ser maiores que zero":      "The sales and coverage periods must be greater than zero",
+	"Fornecedor não encontrado":                                            "Supplier not found",
+	"O fornecedor está inativo":                                            "The supplier is inactive",
+	"Fornecedor com CNPJ %s (%s) não está cadastrado":                      "Supplier with CNPJ %s (%s) is not registered",
+	"Informe ao menos um item":                                             "Inform at least one item",
+	"Custo inválido para o produto %d":                                     "Invalid cost for product %d",
+	"Número do lote inválido para o produto %d":                            "Invalid lot number for product %d",
+	"O produto %d controla lotes, informe o lote do recebimento":           "Product %d controls lots, inform the lot of the receipt",
+	"Pedido de compra não encontrado":                                      "Purchase order not found",
+	"O status do pedido não permite essa operação":                         "The order status does not allow this operation",
+	"Quantidade recebida maior que a pendente no pedido":                   "Quantity received greater than the pending quantity of the order",
+	"Quantidade recebida maior que a pendente no pedido para o produto %d": "Quantity received greater than the pending quantity of the order for product %d",
+	"NF-e já importada":                                                    "NF-e already imported",
+	"XML da NF-e inválido: %s":                                             "Invalid NF-e XML: %s",
+	"Existem itens da NF-e pendentes de associação":                        "There are NF-e items pending association",
+	"Item %d: código %s ou código de barras já cadastrado":                 "Item %d: code %s or barcode already registered",
+
+	// Pending invoice items
+	"quantidade %v não é um número inteiro positivo":               "quantity %v is not a positive integer",
+	"produto não encontrado, informe a categoria para cadastrá-lo": "product not found, inform the category to register it",
+	"unidade %s difere da unidade %s do produto":                   "unit %s differs from the unit %s of the product",
+	"o produto controla lotes e os lotes somam %d de %v unidades":  "the product controls lots and the lots add up to %d of %v units",
+
+	// Stock, lots and inventory
+	"A quantidade deve ser maior que zero":                        "The quantity must be greater than zero",
+	"O custo não pode ser negativo":                               "The cost cannot be negative",
+	"O produto não controla estoque":                              "The product does not control stock",
+	"O produto não controla lotes":                                "The product does not control lots",
+	"O produto controla lotes, informe o lote da entrada":         "The product controls lots, inform the lot of the entry",
+	"Informe o número do lote com até 30 caracteres":              "Inform the lot number with up to 30 characters",
+	"A quantidade do lote deve ser igual à quantidade da entrada": "The lot quantity must equal the entry quantity",
+	"A validade não pode ser anterior à fabricação":               "The expiry date cannot be before the manufacturing date",
+	"Informe o motivo da baixa":                                   "Inform the reason of the write-off",
+	"Lote não encontrado":                                         "Lot not found",
+	"Quantidade maior que a disponível no lote":                   "Quantity greater than available in the lot",
+	"Entrada registrada":                                          "Entry registered",
+	"Inventário não encontrado":                                   "Inventory not found",
+	"O inventário não está aberto":                                "The inventory is not open",
+	"Já existe um inventário aberto que abrange esses produtos":   "There is already an open inventory covering these products",
+	"Informe o dispositivo com até 50 caracteres":                 "Inform the device with up to 50 characters",
+	"A quantidade contada não pode ser negativa":                  "The counted quantity cannot be negative",
+	"Informe o id ou o código de barras do produto":               "Inform the product id or barcode",
+	"Código de barras %s não encontrado":                          "Barcode %s not found",
+	"Produto fora do escopo do inventário":                        "Product outside the inventory scope",
+	"Produto %d fora do escopo do inventário":                     "Product %d outside the inventory scope",
+}
@@ -0,0 +1,56 @@
+// Package i18n translates user-facing messages. The pt-BR text written in the code is the
+// source and the catalog key, other locales map it to their own text
+package i18n
+
+import (
+	"fmt"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	PtBR = "pt-BR"
+	EnUS = "en-US"
+
+	// Default is used when Accept-Language is missing or has no supported language
+	Default = PtBR
+)
+
+// ContextKey is the gin context key holding the negotiated locale
+const ContextKey = "locale"
+
+// supported must stay in the same order as locales, the matcher returns an index into both
+var (
+	supported = []language.Tag{language.BrazilianPortuguese, language.AmericanEnglish}
+	locales   = []string{PtBR, EnUS}
+	matcher   = language.NewMatcher(supported)
+)
+
+var catalogs = map[string]map[string]string{
+	EnUS: enUS,
+}
+
+// Negotiate picks the best supported locale for an Accept-Language header
+func Negotiate(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return Default
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return Default
+	}
+	return locales[index]
+}
+
+// T translates message to locale and formats it with args. Messages missing from the
+// catalog are returned in pt-BR
+func T(locale, message string, args ...any) string {
+	if translated, ok := catalogs[locale][message]; ok {
+		message = translated
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
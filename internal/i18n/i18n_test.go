@@ -0,0 +1,94 @@
+package i18n
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+)
+
+func TestNegotiate(t *testing.T) {
+	cases := map[string]string{
+		"":                         PtBR,
+		"en-US,en;q=0.9":           EnUS,
+		"en":                       EnUS,
+		"pt-PT":                    PtBR,
+		"fr-FR":                    Default,
+		"fr;q=0.9, en-GB;q=0.8":    EnUS,
+		"pt-BR;q=0.5, en-US;q=0.9": EnUS,
+		"invalid;;;":               Default,
+	}
+	for header, expected := range cases {
+		if got := Negotiate(header); got != expected {
+			t.Errorf("Negotiate(%q) = %s, expected %s", header, got, expected)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	if got := T(EnUS, "Produto %d não encontrado", 7); got != "Product 7 not found" {
+		t.Errorf("unexpected translation: %s", got)
+	}
+	if got := T(PtBR, "Produto %d não encontrado", 7); got != "Produto 7 não encontrado" {
+		t.Errorf("unexpected source message: %s", got)
+	}
+	if got := T(EnUS, "Mensagem sem tradução"); got != "Mensagem sem tradução" {
+		t.Errorf("missing entries must fall back to pt-BR: %s", got)
+	}
+}
+
+var verb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+// Every translation must consume the same arguments as its source message
+func TestCatalogVerbs(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for source, translated := range catalog {
+			expected, got := verb.FindAllString(source, -1), verb.FindAllString(translated, -1)
+			if len(expected) != len(got) {
+				t.Errorf("%s: %q has verbs %v, expected %v", locale, translated, got, expected)
+				continue
+			}
+			for i := range expected {
+				if expected[i] != got[i] {
+					t.Errorf("%s: %q has verbs %v, expected %v", locale, translated, got, expected)
+					break
+				}
+			}
+		}
+	}
+}
+
+type address struct {
+	ZipCode string `json:"zip_code" validate:"required"`
+}
+
+type customer struct {
+	Name    string    `json:"name" validate:"required"`
+	Address []address `json:"addresses" validate:"dive"`
+}
+
+func TestValidationMessages(t *testing.T) {
+	v := validator.New()
+	if err := RegisterValidator(v); err != nil {
+		t.Fatalf("RegisterValidator: %v", err)
+	}
+
+	err := v.Struct(customer{Address: []address{{}}})
+	errs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+
+	messages := ValidationMessages(EnUS, errs)
+	if messages["name"] != "name is a required field" {
+		t.Errorf("unexpected en-US message: %v", messages)
+	}
+	if _, ok := messages["addresses[0].zip_code"]; !ok {
+		t.Errorf("nested fields must use the JSON path: %v", messages)
+	}
+
+	messages = ValidationMessages(PtBR, errs)
+	if messages["name"] != "name é um campo obrigatório" {
+		t.Errorf("unexpected pt-BR message: %v", messages)
+	}
+}
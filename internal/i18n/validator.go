@@ -0,0 +1,65 @@
+package i18n
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en_US"
+	"github.com/go-playground/locales/pt_BR"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	enTranslations "github.com/go-playground/validator/v10/translations/en"
+	ptBRTranslations "github.com/go-playground/validator/v10/translations/pt_BR"
+)
+
+var universal = ut.New(pt_BR.New(), pt_BR.New(), en_US.New())
+
+// validatorLocales maps our locales to the universal translator ones
+var validatorLocales = map[string]string{
+	PtBR: "pt_BR",
+	EnUS: "en_US",
+}
+
+// RegisterValidator makes v report fields by their JSON name and registers the
+// translations of the built-in tags for every supported locale
+func RegisterValidator(v *validator.Validate) error {
+
+	v.RegisterTagNameFunc(jsonFieldName)
+
+	ptBR, _ := universal.GetTranslator(validatorLocales[PtBR])
+	if err := ptBRTranslations.RegisterDefaultTranslations(v, ptBR); err != nil {
+		return err
+	}
+	en, _ := universal.GetTranslator(validatorLocales[EnUS])
+	return enTranslations.RegisterDefaultTranslations(v, en)
+}
+
+// Translator returns the validator translator of locale, used to register custom tags
+func Translator(locale string) ut.Translator {
+	translator, _ := universal.GetTranslator(validatorLocales[locale])
+	return translator
+}
+
+// ValidationMessages returns one translated message per invalid field, keyed by the JSON
+// path of the field without the root struct (e.g. "items[0].quantity")
+func ValidationMessages(locale string, errs validator.ValidationErrors) map[string]string {
+
+	translator := Translator(locale)
+	messages := make(map[string]string, len(errs))
+	for _, fieldErr := range errs {
+		field := fieldErr.Namespace()
+		if _, path, ok := strings.Cut(field, "."); ok {
+			field = path
+		}
+		messages[field] = fieldErr.Translate(translator)
+	}
+	return messages
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"APIGolang/internal/config"
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew_AddsContextIDs(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(config.Log{Level: slog.LevelInfo, Format: config.LogFormatJSON}, &out)
+
+	ctx := WithUserID(WithRequestID(context.Background(), "abc123"), 7)
+	logger.With("repository", "user").InfoContext(ctx, "query failed")
+
+	var line map[string]any
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatalf("expected a JSON line, got %q: %v", out.String(), err)
+	}
+	if line["request_id"] != "abc123" {
+		t.Errorf("expected request_id abc123, got %v", line["request_id"])
+	}
+	if line["user_id"] != float64(7) {
+		t.Errorf("expected user_id 7, got %v", line["user_id"])
+	}
+	if line["repository"] != "user" {
+		t.Errorf("expected attributes added with With to be kept, got %v", line["repository"])
+	}
+}
+
+func TestNew_RedactsSensitiveAttributes(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(config.Log{Level: slog.LevelInfo, Format: config.LogFormatJSON}, &out)
+
+	logger.Info("login", "username", "maria", "password", "s3nha", "refresh_token", "eyJ")
+
+	var line map[string]any
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line["password"] != Redacted || line["refresh_token"] != Redacted {
+		t.Errorf("expected credentials to be redacted, got %v", line)
+	}
+	if line["username"] != "maria" {
+		t.Errorf("expected username to be kept, got %v", line["username"])
+	}
+}
+
+func TestNew_Level(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(config.Log{Level: slog.LevelWarn, Format: config.LogFormatText}, &out)
+
+	logger.Info("ignored")
+	if out.Len() != 0 {
+		t.Errorf("expected info to be filtered at warn level, got %q", out.String())
+	}
+}
+
+func TestNew_AddsTraceIDs(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(config.Log{Level: slog.LevelInfo, Format: config.LogFormatJSON}, &out)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	logger.InfoContext(ctx, "request")
+
+	var line map[string]any
+	if err := json.Unmarshal(out.Bytes(), &line); err != nil {
+		t.Fatal(err)
+	}
+	if line["trace_id"] != traceID.String() || line["span_id"] != spanID.String() {
+		t.Errorf("expected the span ids, got %v", line)
+	}
+}
@@ -0,0 +1,132 @@
+// Package metrics keeps the Prometheus collectors of the application: HTTP traffic recorded
+// by the middleware, the database pool and the business counters incremented by the usecases
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "mercado"
+
+// Login results
+const (
+	LoginSuccess            = "success"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginUserInactive       = "user_inactive"
+	LoginLocked             = "locked"
+)
+
+// Sources of stock movements
+const (
+	SourceSale      = "sale"
+	SourcePurchase  = "purchase"
+	SourceInvoice   = "invoice"
+	SourceEntry     = "entry"
+	SourceWriteOff  = "write_off"
+	SourceInventory = "inventory"
+)
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	logins         *prometheus.CounterVec
+	sales          prometheus.Counter
+	revenue        prometheus.Counter
+	stockMovements *prometheus.CounterVec
+	stockUnits     *prometheus.CounterVec
+}
+
+// New creates the collectors on a registry of their own, along with the Go runtime and process ones
+func New() *Metrics {
+
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests answered, by route template and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Time to answer HTTP requests, by route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_logins_total",
+			Help:      "Login attempts, manager authorizations included, by result.",
+		}, []string{"result"}),
+		sales: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sales_total",
+			Help:      "Sales completed.",
+		}),
+		revenue: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "sales_revenue_reais_total",
+			Help:      "Net value of the completed sales, in reais.",
+		}),
+		stockMovements: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "stock_movements_total",
+			Help:      "Stock movements registered, by type and source.",
+		}, []string{"type", "source"}),
+		stockUnits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "stock_movement_units_total",
+			Help:      "Units moved in or out of the stock, by type and source.",
+		}, []string{"type", "source"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration,
+		m.logins, m.sales, m.revenue, m.stockMovements, m.stockUnits,
+	)
+	return m
+}
+
+// RegisterDB exposes the pool statistics of db, read from sql.DB.Stats on every scrape
+func (m *Metrics) RegisterDB(db *sql.DB, name string) error {
+	return m.registry.Register(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the collected metrics in the Prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// ObserveRequest records one answered request. route must be the template, e.g. /product/:id,
+// so that ids in the path don't create a series per value
+func (m *Metrics) ObserveRequest(method, route string, status int, elapsed time.Duration) {
+	code := strconv.Itoa(status)
+	m.httpRequests.WithLabelValues(method, route, code).Inc()
+	m.httpDuration.WithLabelValues(method, route, code).Observe(elapsed.Seconds())
+}
+
+func (m *Metrics) Login(result string) {
+	m.logins.WithLabelValues(result).Inc()
+}
+
+func (m *Metrics) SaleCompleted(total float64) {
+	m.sales.Inc()
+	m.revenue.Add(total)
+}
+
+// StockMoved records one movement of movementType (ENTRADA, SAIDA, AJUSTE_ENTRADA or AJUSTE_SAIDA)
+func (m *Metrics) StockMoved(movementType, source string, quantity int) {
+	m.stockMovements.WithLabelValues(movementType, source).Inc()
+	m.stockUnits.WithLabelValues(movementType, source).Add(float64(quantity))
+}
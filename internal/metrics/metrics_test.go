@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", recorder.Code)
+	}
+	body, _ := io.ReadAll(recorder.Body)
+	return string(body)
+}
+
+func TestMetrics_Exposition(t *testing.T) {
+	m := New()
+
+	m.ObserveRequest(http.MethodGet, "/product/:id", http.StatusOK, 30*time.Millisecond)
+	m.ObserveRequest(http.MethodGet, "/product/:id", http.StatusOK, 2*time.Second)
+	m.Login(LoginSuccess)
+	m.Login(LoginInvalidCredentials)
+	m.Login(LoginInvalidCredentials)
+	m.SaleCompleted(10.5)
+	m.SaleCompleted(4.5)
+	m.StockMoved("SAIDA", SourceSale, 3)
+	m.StockMoved("SAIDA", SourceSale, 2)
+
+	body := scrape(t, m)
+	expected := []string{
+		`mercado_http_requests_total{method="GET",route="/product/:id",status="200"} 2`,
+		`mercado_http_request_duration_seconds_bucket{method="GET",route="/product/:id",status="200",le="0.05"} 1`,
+		`mercado_auth_logins_total{result="invalid_credentials"} 2`,
+		`mercado_auth_logins_total{result="success"} 1`,
+		`mercado_sales_total 2`,
+		`mercado_sales_revenue_reais_total 15`,
+		`mercado_stock_movements_total{source="sale",type="SAIDA"} 2`,
+		`mercado_stock_movement_units_total{source="sale",type="SAIDA"} 5`,
+		`go_goroutines`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(body, line) {
+			t.Errorf("expected %q in:\n%s", line, body)
+		}
+	}
+}
+
+func TestMetrics_RegisterDB(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	m := New()
+	if err := m.RegisterDB(db, "mercado"); err != nil {
+		t.Fatalf("RegisterDB: %v", err)
+	}
+	if err := m.RegisterDB(db, "mercado"); err == nil {
+		t.Error("expected an error when registering the same database twice")
+	}
+
+	body := scrape(t, m)
+	for _, name := range []string{"go_sql_open_connections", "go_sql_in_use_connections", "go_sql_wait_count_total"} {
+		if !strings.Contains(body, name+`{db_name="mercado"}`) {
+			t.Errorf("expected %s in:\n%s", name, body)
+		}
+	}
+}
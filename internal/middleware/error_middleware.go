@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/i18n"
+	"APIGolang/internal/model"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const problemContentType = "application/problem+json"
+
+var statusByKind = map[apperror.Kind]int{
+	apperror.KindValidation:   http.StatusBadRequest,
+	apperror.KindNotFound:     http.StatusNotFound,
+	apperror.KindConflict:     http.StatusConflict,
+	apperror.KindForbidden:    http.StatusForbidden,
+	apperror.KindUnauthorized: http.StatusUnauthorized,
+}
+
+// ErrorHandler writes the last error added with ctx.Error as an RFC 7807 problem in the
+// negotiated locale. Domain errors are mapped by kind, validator errors list the invalid
+// fields and any other error is logged and answered with 500 without exposing its message.
+// Errors raised after the response started, e.g. in the middle of an export, are only logged
+func ErrorHandler(logger *slog.Logger) gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		c.Next()
+
+		if len(c.Errors) == 0 {
+			return
+		}
+		err := c.Errors.Last().Err
+		if c.Writer.Written() {
+			logger.ErrorContext(c.Request.Context(), "request failed after the response started",
+				"method", c.Request.Method, "route", c.FullPath(), "error", err)
+			return
+		}
+		locale := c.GetString(i18n.ContextKey)
+
+		problem := model.Problem{
+			Type:     "about:blank",
+			Status:   http.StatusInternalServerError,
+			Detail:   i18n.T(locale, "Erro interno do servidor"),
+			Instance: c.Request.URL.Path,
+			Code:     "internal_error",
+		}
+		var validationErrs validator.ValidationErrors
+		if appErr, ok := apperror.As(err); ok && appErr.Kind != apperror.KindInternal {
+			problem.Status = statusByKind[appErr.Kind]
+			problem.Detail = i18n.T(locale, appErr.Message, appErr.Args...)
+			problem.Code = appErr.Code
+		} else if errors.As(err, &validationErrs) {
+			problem.Status = http.StatusBadRequest
+			problem.Detail = i18n.T(locale, "Dados inválidos nos campos informados")
+			problem.Code = "validation_failed"
+			problem.Errors = i18n.ValidationMessages(locale, validationErrs)
+		} else {
+			logger.ErrorContext(c.Request.Context(), "request failed",
+				"method", c.Request.Method, "route", c.FullPath(), "error", err)
+		}
+		problem.Title = http.StatusText(problem.Status)
+
+		c.Header("Content-Type", problemContentType)
+		c.JSON(problem.Status, problem)
+	}
+}
@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serve runs handler behind the middleware chain of main and returns the response
+func serve(t *testing.T, acceptLanguage string, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	server := gin.New()
+	server.Use(Recovery(logger), Locale(), ErrorHandler(logger))
+	server.GET("/produtos/7", handler)
+
+	request := httptest.NewRequest(http.MethodGet, "/produtos/7", nil)
+	if acceptLanguage != "" {
+		request.Header.Set("Accept-Language", acceptLanguage)
+	}
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, request)
+	return response
+}
+
+func decodeProblem(t *testing.T, response *httptest.ResponseRecorder) model.Problem {
+	t.Helper()
+
+	if contentType := response.Header().Get("Content-Type"); contentType != problemContentType {
+		t.Errorf("got content type %q, want %q", contentType, problemContentType)
+	}
+	var problem model.Problem
+	if err := json.Unmarshal(response.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("invalid problem %q: %v", response.Body.String(), err)
+	}
+	return problem
+}
+
+func TestErrorHandlerStatusByKind(t *testing.T) {
+	tests := []struct {
+		err        error
+		wantStatus int
+	}{
+		{apperror.Validation("invalid_id", "Id inválido"), http.StatusBadRequest},
+		{apperror.NotFound("product_not_found", "Produto não encontrado"), http.StatusNotFound},
+		{apperror.Conflict("username_taken", "Nome de usuário já cadastrado"), http.StatusConflict},
+		{apperror.Forbidden("user_inactive", "O usuário está inativo"), http.StatusForbidden},
+		{apperror.Unauthorized("invalid_credentials", "credenciais inválidas"), http.StatusUnauthorized},
+		// Wrapped errors keep their kind
+		{fmt.Errorf("update: %w", apperror.Conflict("email_taken", "Esse email já está cadastrado")), http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			response := serve(t, "", func(c *gin.Context) { c.Error(tt.err) })
+
+			appErr, _ := apperror.As(tt.err)
+			problem := decodeProblem(t, response)
+			if response.Code != tt.wantStatus || problem.Status != tt.wantStatus {
+				t.Errorf("got status %d and problem status %d, want %d", response.Code, problem.Status, tt.wantStatus)
+			}
+			if problem.Code != appErr.Code || problem.Title != http.StatusText(tt.wantStatus) || problem.Instance != "/produtos/7" {
+				t.Errorf("unexpected problem: %+v", problem)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerLocalizesTheDetail(t *testing.T) {
+	err := apperror.NotFound("product_not_found", "Produto %d não encontrado", 7)
+
+	tests := []struct {
+		acceptLanguage string
+		wantDetail     string
+	}{
+		{"", "Produto 7 não encontrado"},
+		{"pt-BR", "Produto 7 não encontrado"},
+		{"en-US,en;q=0.9", "Product 7 not found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			problem := decodeProblem(t, serve(t, tt.acceptLanguage, func(c *gin.Context) { c.Error(err) }))
+			if problem.Detail != tt.wantDetail {
+				t.Errorf("got detail %q, want %q", problem.Detail, tt.wantDetail)
+			}
+			// The title is the status text, which clients match on regardless of the locale
+			if problem.Title != "Not Found" {
+				t.Errorf("got title %q, want %q", problem.Title, "Not Found")
+			}
+		})
+	}
+}
+
+func TestErrorHandlerHidesUnknownErrors(t *testing.T) {
+	secret := "pq: relation \"usuario\" does not exist"
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"plain error", errors.New(secret)},
+		{"internal domain error", &apperror.Error{Kind: apperror.KindInternal, Code: "db", Message: secret}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := serve(t, "en-US", func(c *gin.Context) { c.Error(tt.err) })
+
+			problem := decodeProblem(t, response)
+			if response.Code != http.StatusInternalServerError || problem.Code != "internal_error" ||
+				problem.Detail != "Internal server error" {
+				t.Errorf("unexpected problem: %d %+v", response.Code, problem)
+			}
+			if strings.Contains(response.Body.String(), "usuario") {
+				t.Errorf("the error message leaked: %s", response.Body.String())
+			}
+		})
+	}
+}
+
+func TestErrorHandlerAnswersTheLastError(t *testing.T) {
+	response := serve(t, "", func(c *gin.Context) {
+		c.Error(errors.New("first"))
+		c.Error(apperror.Validation("invalid_id", "Id inválido"))
+	})
+
+	if problem := decodeProblem(t, response); response.Code != http.StatusBadRequest || problem.Code != "invalid_id" {
+		t.Errorf("expected the last error answered, got %d %+v", response.Code, problem)
+	}
+}
+
+func TestErrorHandlerLeavesStartedResponses(t *testing.T) {
+	response := serve(t, "", func(c *gin.Context) {
+		c.String(http.StatusOK, "codigo;nome\n")
+		c.Error(errors.New("export failed"))
+	})
+
+	if response.Code != http.StatusOK || response.Body.String() != "codigo;nome\n" {
+		t.Errorf("the started response was changed: %d %q", response.Code, response.Body.String())
+	}
+}
+
+func TestErrorHandlerWithoutErrors(t *testing.T) {
+	response := serve(t, "", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	if response.Code != http.StatusNoContent || response.Body.Len() != 0 {
+		t.Errorf("got %d %q, want an empty 204", response.Code, response.Body.String())
+	}
+}
+
+func TestPanicsAreAnsweredByRecovery(t *testing.T) {
+	response := serve(t, "", func(c *gin.Context) { panic("segredo") })
+
+	// The panic skips ErrorHandler, Recovery answers it without a body
+	if response.Code != http.StatusInternalServerError {
+		t.Errorf("got status %d, want %d", response.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(response.Body.String(), "segredo") {
+		t.Errorf("the panic leaked: %s", response.Body.String())
+	}
+}
@@ -1,47 +1,69 @@
 package middleware
 
 import (
+	"APIGolang/internal/apperror"
 	"APIGolang/internal/auth"
-	"net/http"
+	"APIGolang/internal/logging"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func JWTAuth() gin.HandlerFunc {
+func JWTAuth(tokens *auth.Tokens) gin.HandlerFunc {
 
 	return func(c *gin.Context) {
 
 		authHeader := c.GetHeader("Authorization")
 
 		if authHeader == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "token não informado",
-			})
+			c.Error(apperror.Unauthorized("token_missing", "token não informado"))
+			c.Abort()
 			return
 		}
 
 		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
 
-		token, err := auth.ValidateToken(tokenString)
+		token, err := tokens.ValidateToken(tokenString)
 		if err != nil || !token.Valid {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "token inválido",
-			})
+			c.Error(apperror.Unauthorized("token_invalid", "token inválido"))
+			c.Abort()
 			return
 		}
 
 		claims, ok := token.Claims.(jwt.MapClaims)
 		if !ok {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-				"error": "claims inválidas",
-			})
+			c.Error(apperror.Unauthorized("token_claims_invalid", "claims inválidas"))
+			c.Abort()
 			return
 		}
 
-		c.Set("userId", claims["id_usuario"])
+		if id, ok := claims["userId"].(float64); ok {
+			c.Set("userId", int(id))
+			c.Request = c.Request.WithContext(logging.WithUserID(c.Request.Context(), int(id)))
+		}
+		if username, ok := claims["username"].(string); ok {
+			c.Set("username", username)
+		}
+		if role, ok := claims["role"].(string); ok {
+			c.Set("role", role)
+		}
+
+		c.Next()
+	}
+}
+
+// RequireRole must run after JWTAuth and rejects users whose token has a different role
+func RequireRole(role string) gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		if c.GetString("role") != role {
+			c.Error(apperror.Forbidden("access_denied", "acesso negado"))
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,21 @@
+package middleware
+
+import (
+	"APIGolang/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Locale negotiates the response language from Accept-Language and stores it under i18n.ContextKey
+func Locale() gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		locale := i18n.Negotiate(c.GetHeader("Accept-Language"))
+		c.Set(i18n.ContextKey, locale)
+		c.Header("Content-Language", locale)
+		c.Header("Vary", "Accept-Language")
+
+		c.Next()
+	}
+}
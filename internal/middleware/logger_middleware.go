@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Logger writes one line per request, replacing Gin's default text logger
+// Server errors are logged as errors and client errors as warnings
+func Logger(logger *slog.Logger) gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		// The route template keeps ids out of the message, the raw path is still logged
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "request",
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("latency_ms", time.Since(start).Milliseconds()),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+			slog.String("user_agent", c.Request.UserAgent()),
+		)
+	}
+}
+
+// Recovery answers 500 on panics and logs them with the stack trace
+func Recovery(logger *slog.Logger) gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logger.ErrorContext(c.Request.Context(), "panic recovered",
+					"method", c.Request.Method,
+					"route", c.FullPath(),
+					"panic", recovered,
+					"stack", string(debug.Stack()),
+				)
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"APIGolang/internal/metrics"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics records the count and latency of every request by route template and status
+func Metrics(m *metrics.Metrics) gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		m.ObserveRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"APIGolang/internal/logging"
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds ids coming from clients, they end up in every log line
+const maxRequestIDLength = 128
+
+// RequestID keeps the X-Request-ID sent by the client or a proxy, or generates one,
+// echoes it in the response and stores it in the request context for the logger
+func RequestID() gin.HandlerFunc {
+
+	return func(c *gin.Context) {
+
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+
+		c.Next()
+	}
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// validRequestID accepts only printable ASCII so ids can't forge log lines
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
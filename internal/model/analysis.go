@@ -0,0 +1,41 @@
+package model
+
+import "time"
+
+// AbcItem is one product of the ABC curve with its stock coverage
+// Share and CumulativeShare are percentages of the net revenue of the period
+type AbcItem struct {
+	ProductId       int      `json:"product_id"`
+	Code            string   `json:"product_code"`
+	Name            string   `json:"product_name"`
+	Category        string   `json:"category"`
+	QuantitySold    float64  `json:"quantity_sold"`
+	Revenue         float64  `json:"revenue"`
+	Share           float64  `json:"share"`
+	CumulativeShare float64  `json:"cumulative_share"`
+	Class           string   `json:"class"`
+	CurrentStock    int      `json:"current_stock"`
+	AverageDaily    float64  `json:"average_daily_sales"`
+	CoverageDays    *float64 `json:"coverage_days"`
+}
+
+type AbcAnalysis struct {
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Days   int       `json:"days"`
+	LimitA float64   `json:"limit_a"`
+	LimitB float64   `json:"limit_b"`
+	Items  []AbcItem `json:"items"`
+}
+
+// IdleProduct is an active product without completed sales since a given date
+type IdleProduct struct {
+	ProductId    int        `json:"product_id"`
+	Code         string     `json:"product_code"`
+	Name         string     `json:"product_name"`
+	Category     string     `json:"category"`
+	CurrentStock int        `json:"current_stock"`
+	CostPrice    float64    `json:"cost_price"`
+	StockValue   float64    `json:"stock_value"`
+	LastSaleAt   *time.Time `json:"last_sale_at"`
+}
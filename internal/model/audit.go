@@ -0,0 +1,53 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditActionCreate = "CRIACAO"
+	AuditActionUpdate = "ALTERACAO"
+	AuditActionDelete = "EXCLUSAO"
+	// A manager authorization that was refused, nothing was changed
+	AuditActionDenied = "NEGACAO"
+)
+
+const (
+	AuditEntityUser    = "usuario"
+	AuditEntityProduct = "produto"
+	// Identified by the username informed as the authorizing manager
+	AuditEntitySaleOverride = "autorizacao_venda"
+)
+
+// Actor identifies who performed an operation, UserId is nil for unauthenticated requests
+type Actor struct {
+	UserId    *int
+	Username  *string
+	IP        string
+	UserAgent string
+}
+
+type AuditEntry struct {
+	Id        int64           `json:"audit_id"`
+	At        time.Time       `json:"at"`
+	UserId    *int            `json:"user_id"`
+	Username  *string         `json:"username"`
+	IP        string          `json:"ip"`
+	UserAgent string          `json:"user_agent"`
+	Entity    string          `json:"entity"`
+	EntityId  string          `json:"entity_id"`
+	Action    string          `json:"action"`
+	Changes   json.RawMessage `json:"changes"`
+}
+
+type AuditFilter struct {
+	Entity   *string
+	EntityId *string
+	UserId   *int
+	Action   *string
+	From     time.Time
+	To       time.Time
+	Limit    int
+	Offset   int
+}
@@ -0,0 +1,8 @@
+package model
+
+type Category struct {
+	Id          int     `json:"category_id"`
+	Name        string  `json:"name"`
+	Description *string `json:"description"`
+	Active      bool    `json:"active"`
+}
@@ -1,6 +1,7 @@
 package model
 
 type ChangePassword struct {
-	Email       string `json:"email" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required"`
-}
\ No newline at end of file
+	Email           string `json:"email" binding:"required,max=50,email"`
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,password"`
+}
@@ -1,10 +1,9 @@
 package model
 
 type CreateUserRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Username string `json:"username" binding:"required"`
-	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
-	Profile  string `json:"profile"`
-	Role     string `json:"role"`
-}
\ No newline at end of file
+	Name     string `json:"name" binding:"required,max=30"`
+	Username string `json:"username" binding:"required,max=20,username"`
+	Email    string `json:"email" binding:"required,max=50,email"`
+	Password string `json:"password" binding:"required,password"`
+	Profile  string `json:"profile" binding:"omitempty,oneof=Administrador OPERADOR"`
+}
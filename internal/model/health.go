@@ -0,0 +1,20 @@
+package model
+
+// Health statuses, a report is up only when every check is up
+const (
+	HealthUp           = "up"
+	HealthDown         = "down"
+	HealthShuttingDown = "shutting_down"
+)
+
+type HealthCheck struct {
+	Status    string         `json:"status"`
+	LatencyMs int64          `json:"latency_ms"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+type HealthReport struct {
+	Status string                 `json:"status"`
+	Checks map[string]HealthCheck `json:"checks,omitempty"`
+}
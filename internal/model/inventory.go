@@ -0,0 +1,68 @@
+package model
+
+import "time"
+
+// Status of an inventory count session stored in inventario.status
+const (
+	InventoryStatusOpen   = "ABERTO"
+	InventoryStatusClosed = "FECHADO"
+)
+
+type InventorySessionRequest struct {
+	CategoryId  *int    `json:"category_id"`
+	Observation *string `json:"observation"`
+}
+
+type InventorySession struct {
+	Id              int        `json:"inventory_id"`
+	CategoryId      *int       `json:"category_id"`
+	Status          string     `json:"status"`
+	Observation     *string    `json:"observation"`
+	OpenedBy        *int       `json:"opened_by"`
+	ClosedBy        *int       `json:"closed_by"`
+	OpenedAt        time.Time  `json:"opened_at"`
+	ClosedAt        *time.Time `json:"closed_at"`
+	Products        int        `json:"products"`
+	CountedProducts int        `json:"counted_products"`
+}
+
+// InventoryCountItem identifies the product by id or barcode
+type InventoryCountItem struct {
+	ProductId *int    `json:"product_id"`
+	Barcode   *string `json:"barcode"`
+	Quantity  int     `json:"quantity"`
+}
+
+// InventoryCountRequest replaces the previous counts of the same device for the informed products
+// Counts from different devices are summed
+type InventoryCountRequest struct {
+	Device string               `json:"device" binding:"required"`
+	Items  []InventoryCountItem `json:"items" binding:"required"`
+}
+
+type InventoryCloseRequest struct {
+	// When true, products nobody counted are adjusted to zero; otherwise they are left untouched
+	ZeroUncounted bool `json:"zero_uncounted"`
+}
+
+type InventoryReportItem struct {
+	ProductId       int      `json:"product_id"`
+	Code            string   `json:"product_code"`
+	Name            string   `json:"product_name"`
+	Category        string   `json:"category"`
+	FrozenStock     int      `json:"frozen_stock"`
+	CountedQuantity *int     `json:"counted_quantity"`
+	Variance        *int     `json:"variance"`
+	UnitCost        float64  `json:"unit_cost"`
+	VarianceValue   *float64 `json:"variance_value"`
+}
+
+type InventoryReport struct {
+	Session           InventorySession      `json:"session"`
+	CountedItems      int                   `json:"counted_items"`
+	ItemsWithVariance int                   `json:"items_with_variance"`
+	SurplusValue      float64               `json:"surplus_value"`
+	ShortageValue     float64               `json:"shortage_value"`
+	NetVarianceValue  float64               `json:"net_variance_value"`
+	Items             []InventoryReportItem `json:"items"`
+}
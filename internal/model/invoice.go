@@ -0,0 +1,45 @@
+package model
+
+import "time"
+
+type InvoiceItem struct {
+	ItemNumber      int      `json:"item_number"`
+	SupplierCode    string   `json:"supplier_code"`
+	Barcode         *string  `json:"barcode"`
+	Description     string   `json:"description"`
+	Unit            string   `json:"unit"`
+	Quantity        float64  `json:"quantity"`
+	UnitCost        float64  `json:"unit_cost"`
+	TotalCost       float64  `json:"total_cost"`
+	ProductId       *int     `json:"product_id"`
+	MatchedBy       string   `json:"matched_by,omitempty"`
+	ProposedProduct *Product `json:"proposed_product,omitempty"`
+	// Sale price suggested by the category rule when the cost changes, it is not applied automatically
+	SuggestedPrice *float64   `json:"suggested_price,omitempty"`
+	Lots           []LotEntry `json:"lots,omitempty"`
+	// Why the item is pending: a stable code and a pt-BR catalog key formatted with ErrorArgs,
+	// translated by the controller
+	ErrorCode string `json:"error_code,omitempty"`
+	Error     string `json:"error,omitempty"`
+	ErrorArgs []any  `json:"-"`
+}
+
+type Invoice struct {
+	Id           int           `json:"invoice_id,omitempty"`
+	AccessKey    string        `json:"access_key"`
+	Number       string        `json:"number"`
+	Series       string        `json:"series"`
+	IssuedAt     time.Time     `json:"issued_at"`
+	SupplierId   int           `json:"supplier_id"`
+	SupplierName string        `json:"supplier_name"`
+	TotalValue   float64       `json:"total_value"`
+	Items        []InvoiceItem `json:"items"`
+}
+
+type InvoiceImportResult struct {
+	DryRun          bool    `json:"dry_run"`
+	Invoice         Invoice `json:"invoice"`
+	UnmatchedItems  int     `json:"unmatched_items"`
+	CreatedProducts int     `json:"created_products"`
+	StockEntries    int     `json:"stock_entries"`
+}
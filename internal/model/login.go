@@ -1,7 +1,7 @@
 package model
 
 type TokenRequest struct {
-	Username string `json:"username" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Username string `json:"username" binding:"required,max=20"`
+	Password string `json:"password" binding:"required,max=72"`
 	Remember *bool  `json:"remember" binding:"required"`
-}
\ No newline at end of file
+}
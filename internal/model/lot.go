@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// LotEntry describes the lot of units entering the stock
+type LotEntry struct {
+	Number         string     `json:"lot_number" binding:"required"`
+	Quantity       int        `json:"quantity"`
+	ManufacturedAt *time.Time `json:"manufactured_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+}
+
+type Lot struct {
+	Id              int        `json:"lot_id"`
+	ProductId       int        `json:"product_id"`
+	ProductCode     string     `json:"product_code,omitempty"`
+	ProductName     string     `json:"product_name,omitempty"`
+	Number          string     `json:"lot_number"`
+	ManufacturedAt  *time.Time `json:"manufactured_at"`
+	ExpiresAt       *time.Time `json:"expires_at"`
+	Quantity        int        `json:"quantity"`
+	InitialQuantity int        `json:"initial_quantity"`
+	EnteredAt       time.Time  `json:"entered_at"`
+	DaysToExpire    *int       `json:"days_to_expire"`
+	StockValue      float64    `json:"stock_value"`
+}
+
+// StockEntryRequest is a manual stock entry. Lot is required for products that control lots
+type StockEntryRequest struct {
+	ProductId   int       `json:"product_id" binding:"required"`
+	Quantity    int       `json:"quantity" binding:"required"`
+	UnitCost    *float64  `json:"unit_cost"`
+	Observation *string   `json:"observation"`
+	Lot         *LotEntry `json:"lot"`
+}
+
+type LotWriteOffRequest struct {
+	Quantity int    `json:"quantity" binding:"required"`
+	Reason   string `json:"reason" binding:"required"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+// Origins of a price change stored in historico_preco
+const (
+	PriceOriginManual    = "MANUAL"
+	PriceOriginImport    = "IMPORTACAO"
+	PriceOriginInvoice   = "NFE"
+	PriceOriginScheduled = "AGENDAMENTO"
+	PriceOriginPurchase  = "COMPRA"
+)
+
+// Status of a scheduled price change
+const (
+	ScheduleStatusPending   = "PENDENTE"
+	ScheduleStatusApplied   = "APLICADA"
+	ScheduleStatusCancelled = "CANCELADA"
+	// ScheduleStatusRejected marks a change that was due but fell below the minimum margin
+	// with the cost of the moment
+	ScheduleStatusRejected = "REJEITADA"
+)
+
+type PriceHistoryEntry struct {
+	Id           int       `json:"history_id"`
+	ProductId    int       `json:"product_id"`
+	OldSalePrice *float64  `json:"old_sale_price"`
+	NewSalePrice float64   `json:"new_sale_price"`
+	OldCostPrice *float64  `json:"old_cost_price"`
+	NewCostPrice float64   `json:"new_cost_price"`
+	Origin       string    `json:"origin"`
+	UserId       *int      `json:"user_id"`
+	ChangedAt    time.Time `json:"changed_at"`
+}
+
+type ScheduledPriceChange struct {
+	Id          int        `json:"schedule_id"`
+	ProductId   int        `json:"product_id"`
+	SalePrice   float64    `json:"sale_price"`
+	CostPrice   *float64   `json:"cost_price"`
+	EffectiveAt time.Time  `json:"effective_at"`
+	Status      string     `json:"status"`
+	UserId      *int       `json:"user_id"`
+	CreatedAt   time.Time  `json:"created_at"`
+	AppliedAt   *time.Time `json:"applied_at"`
+}
+
+type SchedulePriceRequest struct {
+	SalePrice   float64   `json:"sale_price" binding:"required"`
+	CostPrice   *float64  `json:"cost_price"`
+	EffectiveAt time.Time `json:"effective_at" binding:"required"`
+}
+
+type PriceTimeline struct {
+	ProductId        int                    `json:"product_id"`
+	CurrentSalePrice float64                `json:"current_sale_price"`
+	CurrentCostPrice float64                `json:"current_cost_price"`
+	History          []PriceHistoryEntry    `json:"history"`
+	Scheduled        []ScheduledPriceChange `json:"scheduled"`
+}
@@ -0,0 +1,24 @@
+package model
+
+type PricingRule struct {
+	CategoryId    int      `json:"category_id"`
+	TargetMarkup  *float64 `json:"target_markup"`
+	MinimumMargin *float64 `json:"minimum_margin"`
+	Rounding      string   `json:"rounding"`
+}
+
+type ProductPricing struct {
+	ProductId      int          `json:"product_id"`
+	CostPrice      float64      `json:"cost_price"`
+	SalePrice      float64      `json:"sale_price"`
+	Markup         *float64     `json:"markup"`
+	Margin         *float64     `json:"margin"`
+	Rule           *PricingRule `json:"rule"`
+	SuggestedPrice *float64     `json:"suggested_price"`
+}
+
+// ManagerOverride carries the credentials of the manager authorizing a sale below the minimum margin
+type ManagerOverride struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
@@ -0,0 +1,14 @@
+package model
+
+// Problem is the RFC 7807 body of every error response
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Code is a stable, machine-readable identifier of the error
+	Code string `json:"code"`
+	// Errors has one message per invalid field, keyed by its JSON path
+	Errors map[string]string `json:"errors,omitempty"`
+}
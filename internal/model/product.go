@@ -1,7 +1,61 @@
 package model
 
 type Product struct {
-	Id    int      `json:"product_id"`
-	Name  *string  `json:"product_name"`
-	Price *float64 `json:"product_price"`
-}
\ No newline at end of file
+	Id            int      `json:"product_id"`
+	Code          *string  `json:"product_code"`
+	Barcode       *string  `json:"barcode" binding:"omitempty,gtin"`
+	Name          *string  `json:"product_name"`
+	Description   *string  `json:"description"`
+	CategoryId    *int     `json:"category_id"`
+	SupplierId    *int     `json:"supplier_id"`
+	CostPrice     *float64 `json:"cost_price"`
+	Price         *float64 `json:"product_price"`
+	Unit          *string  `json:"unit"`
+	CurrentStock  *int     `json:"current_stock"`
+	MinimumStock  *int     `json:"minimum_stock"`
+	ControlsStock *bool    `json:"controls_stock"`
+	ControlsLots  *bool    `json:"controls_lots"`
+	Active        *bool    `json:"active"`
+
+	// Computed on reads from the cost and sale prices, ignored on writes
+	Markup *float64 `json:"markup,omitempty"`
+	Margin *float64 `json:"margin,omitempty"`
+}
+
+// CreateProductRequest is the body of a product creation, the columns without a default are required
+type CreateProductRequest struct {
+	Code          string   `json:"product_code" binding:"required,max=30"`
+	Barcode       *string  `json:"barcode" binding:"omitempty,gtin"`
+	Name          string   `json:"product_name" binding:"required,max=100"`
+	Description   *string  `json:"description"`
+	CategoryId    int      `json:"category_id" binding:"required"`
+	SupplierId    *int     `json:"supplier_id"`
+	CostPrice     *float64 `json:"cost_price" binding:"required,gte=0"`
+	Price         *float64 `json:"product_price" binding:"required,gt=0"`
+	Unit          *string  `json:"unit" binding:"omitempty,max=10"`
+	CurrentStock  *int     `json:"current_stock" binding:"omitempty,gte=0"`
+	MinimumStock  *int     `json:"minimum_stock" binding:"omitempty,gte=0"`
+	ControlsStock *bool    `json:"controls_stock"`
+	ControlsLots  *bool    `json:"controls_lots"`
+	Active        *bool    `json:"active"`
+}
+
+// Product returns the product to be inserted
+func (r CreateProductRequest) Product() Product {
+	return Product{
+		Code:          &r.Code,
+		Barcode:       r.Barcode,
+		Name:          &r.Name,
+		Description:   r.Description,
+		CategoryId:    &r.CategoryId,
+		SupplierId:    r.SupplierId,
+		CostPrice:     r.CostPrice,
+		Price:         r.Price,
+		Unit:          r.Unit,
+		CurrentStock:  r.CurrentStock,
+		MinimumStock:  r.MinimumStock,
+		ControlsStock: r.ControlsStock,
+		ControlsLots:  r.ControlsLots,
+		Active:        r.Active,
+	}
+}
@@ -0,0 +1,26 @@
+package model
+
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Field string `json:"field,omitempty"`
+	// Code is a stable, machine-readable identifier of the problem
+	Code string `json:"code"`
+	// Message is a pt-BR catalog key formatted with Args, translated by the controller
+	Message string `json:"message"`
+	Args    []any  `json:"-"`
+}
+
+type ImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	TotalRows int              `json:"total_rows"`
+	ValidRows int              `json:"valid_rows"`
+	Created   int              `json:"created"`
+	Updated   int              `json:"updated"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
+// ImportedProduct is a product written by the import, Before is nil when it was created
+type ImportedProduct struct {
+	Before *Product
+	After  Product
+}
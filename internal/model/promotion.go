@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// Promotion types stored in promocao.tipo
+const (
+	PromotionPercent  = "PERCENTUAL"
+	PromotionFixed    = "PRECO_FIXO"
+	PromotionBuyXPayY = "LEVE_PAGUE"
+	PromotionCategory = "CATEGORIA"
+	PromotionCombo    = "COMBO"
+)
+
+type PromotionProduct struct {
+	ProductId int `json:"product_id" binding:"required"`
+	Quantity  int `json:"quantity"`
+}
+
+type Promotion struct {
+	Id              int                `json:"promotion_id"`
+	Name            string             `json:"name" binding:"required"`
+	Type            string             `json:"type" binding:"required"`
+	DiscountPercent *float64           `json:"discount_percent"`
+	FixedPrice      *float64           `json:"fixed_price"`
+	BuyQuantity     *int               `json:"buy_quantity"`
+	PayQuantity     *int               `json:"pay_quantity"`
+	CategoryId      *int               `json:"category_id"`
+	StartsAt        time.Time          `json:"starts_at" binding:"required"`
+	EndsAt          time.Time          `json:"ends_at" binding:"required"`
+	Active          bool               `json:"active"`
+	Products        []PromotionProduct `json:"products"`
+}
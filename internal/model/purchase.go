@@ -0,0 +1,74 @@
+package model
+
+import "time"
+
+// Status of a purchase order stored in pedido_compra.status
+const (
+	PurchaseStatusDraft             = "RASCUNHO"
+	PurchaseStatusSent              = "ENVIADO"
+	PurchaseStatusPartiallyReceived = "RECEBIDO_PARCIAL"
+	PurchaseStatusReceived          = "RECEBIDO"
+)
+
+type PurchaseSuggestionItem struct {
+	ProductId         int     `json:"product_id"`
+	Code              string  `json:"product_code"`
+	Name              string  `json:"product_name"`
+	CurrentStock      int     `json:"current_stock"`
+	MinimumStock      int     `json:"minimum_stock"`
+	PendingQuantity   int     `json:"pending_quantity"`
+	QuantitySold      float64 `json:"quantity_sold"`
+	AverageDaily      float64 `json:"average_daily_sales"`
+	SuggestedQuantity int     `json:"suggested_quantity"`
+	UnitCost          float64 `json:"unit_cost"`
+	TotalCost         float64 `json:"total_cost"`
+}
+
+type PurchaseSuggestion struct {
+	SupplierId   int                      `json:"supplier_id"`
+	SupplierName string                   `json:"supplier_name"`
+	TotalCost    float64                  `json:"total_cost"`
+	Items        []PurchaseSuggestionItem `json:"items"`
+}
+
+type PurchaseOrderItemRequest struct {
+	ProductId int      `json:"product_id" binding:"required"`
+	Quantity  int      `json:"quantity" binding:"required"`
+	UnitCost  *float64 `json:"unit_cost"`
+	// Only used when receiving; kept for products that control lots
+	Lot *LotEntry `json:"lot"`
+}
+
+type PurchaseOrderRequest struct {
+	SupplierId  int                        `json:"supplier_id" binding:"required"`
+	Observation *string                    `json:"observation"`
+	Items       []PurchaseOrderItemRequest `json:"items" binding:"required,dive"`
+}
+
+// PurchaseReceiptRequest lists the quantities delivered; UnitCost overrides the ordered cost
+type PurchaseReceiptRequest struct {
+	Items []PurchaseOrderItemRequest `json:"items" binding:"required,dive"`
+}
+
+type PurchaseOrderItem struct {
+	Id               int     `json:"order_item_id"`
+	ProductId        int     `json:"product_id"`
+	ProductName      string  `json:"product_name"`
+	Quantity         int     `json:"quantity"`
+	ReceivedQuantity int     `json:"received_quantity"`
+	UnitCost         float64 `json:"unit_cost"`
+}
+
+type PurchaseOrder struct {
+	Id           int                 `json:"order_id"`
+	SupplierId   int                 `json:"supplier_id"`
+	SupplierName string              `json:"supplier_name"`
+	Status       string              `json:"status"`
+	Observation  *string             `json:"observation"`
+	UserId       *int                `json:"user_id"`
+	CreatedAt    time.Time           `json:"created_at"`
+	SentAt       *time.Time          `json:"sent_at"`
+	ReceivedAt   *time.Time          `json:"received_at"`
+	TotalCost    float64             `json:"total_cost"`
+	Items        []PurchaseOrderItem `json:"items"`
+}
@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Groupings accepted by the sales report
+const (
+	ReportGroupDay           = "day"
+	ReportGroupWeek          = "week"
+	ReportGroupMonth         = "month"
+	ReportGroupUser          = "user"
+	ReportGroupPaymentMethod = "payment_method"
+	ReportGroupCategory      = "category"
+)
+
+// SalesSummary holds the aggregates of the completed sales in a group
+// When grouping by payment method, sales paid with several methods are split
+// proportionally to the amount paid with each one
+type SalesSummary struct {
+	Sales              int      `json:"sales"`
+	ItemCount          float64  `json:"item_count"`
+	GrossRevenue       float64  `json:"gross_revenue"`
+	Discounts          float64  `json:"discounts"`
+	NetRevenue         float64  `json:"net_revenue"`
+	AverageTicket      float64  `json:"average_ticket"`
+	Cost               float64  `json:"cost"`
+	GrossMargin        float64  `json:"gross_margin"`
+	GrossMarginPercent *float64 `json:"gross_margin_percent"`
+}
+
+type SalesReportGroup struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+	SalesSummary
+}
+
+type SalesReport struct {
+	From    time.Time          `json:"from"`
+	To      time.Time          `json:"to"`
+	GroupBy string             `json:"group_by"`
+	Totals  SalesSummary       `json:"totals"`
+	Groups  []SalesReportGroup `json:"groups"`
+}
@@ -0,0 +1,60 @@
+package model
+
+import "time"
+
+type SaleItemRequest struct {
+	ProductId int `json:"product_id" binding:"required"`
+	Quantity  int `json:"quantity" binding:"required"`
+}
+
+type PaymentRequest struct {
+	PaymentMethodId int     `json:"payment_method_id" binding:"required"`
+	Amount          float64 `json:"amount" binding:"required"`
+}
+
+type SaleRequest struct {
+	CashRegisterId int               `json:"cash_register_id" binding:"required"`
+	CustomerId     *int              `json:"customer_id"`
+	Items          []SaleItemRequest `json:"items" binding:"required,dive"`
+	Payments       []PaymentRequest  `json:"payments" binding:"dive"`
+	// Required only when some item is sold below cost or below the category minimum margin
+	ManagerOverride *ManagerOverride `json:"manager_override"`
+}
+
+type SaleItem struct {
+	Id          int     `json:"sale_item_id,omitempty"`
+	ProductId   int     `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	CategoryId  int     `json:"category_id"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+	UnitCost    float64 `json:"unit_cost"`
+	Subtotal    float64 `json:"subtotal"`
+	Discount    float64 `json:"discount"`
+	PromotionId *int    `json:"promotion_id"`
+	Total       float64 `json:"total"`
+	// Margin of the net unit price (after discounts) over the unit cost
+	Margin             *float64 `json:"margin"`
+	BelowMinimumMargin bool     `json:"below_minimum_margin"`
+}
+
+type Sale struct {
+	Id             int              `json:"sale_id,omitempty"`
+	Date           time.Time        `json:"date"`
+	GrossValue     float64          `json:"gross_value"`
+	Discount       float64          `json:"discount"`
+	TotalValue     float64          `json:"total_value"`
+	Status         string           `json:"status"`
+	CustomerId     *int             `json:"customer_id"`
+	UserId         int              `json:"user_id"`
+	CashRegisterId int              `json:"cash_register_id"`
+	Items          []SaleItem       `json:"items"`
+	Payments       []PaymentRequest `json:"payments"`
+	Change         float64          `json:"change"`
+	AuthorizedBy   *int             `json:"authorized_by,omitempty"`
+}
+
+// Sale status stored in venda.status
+const (
+	SaleStatusCompleted = "FINALIZADA"
+)
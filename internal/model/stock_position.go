@@ -0,0 +1,22 @@
+package model
+
+// Types of movimentacao_estoque
+const (
+	StockMovementIn            = "ENTRADA"
+	StockMovementOut           = "SAIDA"
+	StockMovementAdjustmentIn  = "AJUSTE_ENTRADA"
+	StockMovementAdjustmentOut = "AJUSTE_SAIDA"
+)
+
+type StockPosition struct {
+	ProductId    int     `json:"product_id"`
+	Code         string  `json:"product_code"`
+	Name         string  `json:"product_name"`
+	Category     string  `json:"category"`
+	Unit         string  `json:"unit"`
+	CurrentStock int     `json:"current_stock"`
+	MinimumStock int     `json:"minimum_stock"`
+	CostPrice    float64 `json:"cost_price"`
+	StockValue   float64 `json:"stock_value"`
+	BelowMinimum bool    `json:"below_minimum"`
+}
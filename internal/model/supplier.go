@@ -0,0 +1,8 @@
+package model
+
+type Supplier struct {
+	Id     int    `json:"supplier_id"`
+	Name   string `json:"name" binding:"required,max=100"`
+	Cnpj   string `json:"cnpj" binding:"required,cnpj"`
+	Active bool   `json:"active"`
+}
@@ -1,9 +1,10 @@
 package model
 
 type UpdateUserRequest struct {
-	Name     string `json:"name" binding:"required"`
-	Username string `json:"username" binding:"required"`
-	Email    string `json:"email" binding:"required"`
-	Profile  string `json:"profile"`
-	Role     string `json:"role"`
-}
\ No newline at end of file
+	Name     string `json:"name" binding:"required,max=30"`
+	Username string `json:"username" binding:"required,max=20,username"`
+	Email    string `json:"email" binding:"required,max=50,email"`
+	Profile  string `json:"profile" binding:"omitempty,oneof=Administrador OPERADOR"`
+	// Role follows the profile, it is never taken from the request
+	Role     string `json:"-"`
+}
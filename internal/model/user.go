@@ -1,5 +1,13 @@
 package model
 
+import "time"
+
+// Profiles accepted for users, Administrador also grants the ADM role
+const (
+	ProfileAdmin    = "Administrador"
+	ProfileOperator = "OPERADOR"
+)
+
 type User struct {
 	Id       int
 	Name     string
@@ -9,4 +17,7 @@ type User struct {
 	Profile  string
 	Role     string
 	Active   bool
-}
\ No newline at end of file
+	// Failed logins since the last success and until when the login is refused, read by the login only
+	LoginFailures int
+	LockedUntil   *time.Time
+}
@@ -0,0 +1,232 @@
+package nfe
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SupportedVersion is the NF-e layout accepted by Parse
+const SupportedVersion = "4.00"
+
+// withoutGTIN is the value SEFAZ requires in cEAN when the product has no barcode
+const withoutGTIN = "SEM GTIN"
+
+// Invoice holds the fields of the NF-e needed to register a stock entry
+type Invoice struct {
+	AccessKey   string
+	Number      string
+	Series      string
+	IssuedAt    time.Time
+	EmitterCnpj string
+	EmitterName string
+	TotalValue  float64
+	Items       []Item
+}
+
+type Item struct {
+	Number       int
+	SupplierCode string
+	Barcode      string
+	Description  string
+	Unit         string
+	Quantity     float64
+	UnitCost     float64
+	TotalCost    float64
+	// TaxUnit, TaxQuantity and TaxBarcode express the item in the taxable unit, e.g. 24 UN for 2 CX
+	// with the GTIN of the unit; TaxQuantity is 0 when the NF-e leaves qTrib out
+	TaxUnit     string
+	TaxQuantity float64
+	TaxBarcode  string
+	Lots        []Lot
+}
+
+// Lot comes from the <rastro> group, informed for products subject to traceability
+type Lot struct {
+	Number         string
+	Quantity       float64
+	ManufacturedAt time.Time
+	ExpiresAt      time.Time
+}
+
+type infNFe struct {
+	Id     string `xml:"Id,attr"`
+	Versao string `xml:"versao,attr"`
+	Ide    struct {
+		Serie string `xml:"serie"`
+		NNF   string `xml:"nNF"`
+		DhEmi string `xml:"dhEmi"`
+	} `xml:"ide"`
+	Emit struct {
+		CNPJ  string `xml:"CNPJ"`
+		XNome string `xml:"xNome"`
+	} `xml:"emit"`
+	Det []struct {
+		NItem string `xml:"nItem,attr"`
+		Prod  struct {
+			CProd    string `xml:"cProd"`
+			CEAN     string `xml:"cEAN"`
+			XProd    string `xml:"xProd"`
+			UCom     string `xml:"uCom"`
+			QCom     string `xml:"qCom"`
+			VUnCom   string `xml:"vUnCom"`
+			VProd    string `xml:"vProd"`
+			CEANTrib string `xml:"cEANTrib"`
+			UTrib    string `xml:"uTrib"`
+			QTrib    string `xml:"qTrib"`
+			Rastro   []struct {
+				NLote string `xml:"nLote"`
+				QLote string `xml:"qLote"`
+				DFab  string `xml:"dFab"`
+				DVal  string `xml:"dVal"`
+			} `xml:"rastro"`
+		} `xml:"prod"`
+	} `xml:"det"`
+	Total struct {
+		ICMSTot struct {
+			VNF string `xml:"vNF"`
+		} `xml:"ICMSTot"`
+	} `xml:"total"`
+}
+
+// Parse reads an NF-e 4.00 XML, either the signed <NFe> or the authorized <nfeProc>
+func Parse(r io.Reader) (*Invoice, error) {
+
+	decoder := xml.NewDecoder(r)
+
+	var inf *infNFe
+	for inf == nil {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, errors.New("XML não contém o grupo infNFe")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("XML inválido: %w", err)
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "infNFe" {
+			continue
+		}
+
+		inf = &infNFe{}
+		if err := decoder.DecodeElement(inf, &start); err != nil {
+			return nil, fmt.Errorf("XML inválido: %w", err)
+		}
+	}
+
+	if inf.Versao != SupportedVersion {
+		return nil, fmt.Errorf("versão da NF-e não suportada: %s (esperado %s)", inf.Versao, SupportedVersion)
+	}
+
+	return buildInvoice(inf)
+}
+
+func buildInvoice(inf *infNFe) (*Invoice, error) {
+
+	accessKey := strings.TrimPrefix(inf.Id, "NFe")
+	if len(accessKey) != 44 {
+		return nil, fmt.Errorf("chave de acesso inválida: %s", inf.Id)
+	}
+
+	issuedAt, err := parseDateTime(inf.Ide.DhEmi)
+	if err != nil {
+		return nil, fmt.Errorf("data de emissão inválida: %s", inf.Ide.DhEmi)
+	}
+
+	total, err := parseNumber(inf.Total.ICMSTot.VNF)
+	if err != nil {
+		return nil, fmt.Errorf("valor total inválido: %s", inf.Total.ICMSTot.VNF)
+	}
+
+	if len(inf.Det) == 0 {
+		return nil, errors.New("a NF-e não possui itens")
+	}
+
+	invoice := &Invoice{
+		AccessKey:   accessKey,
+		Number:      inf.Ide.NNF,
+		Series:      inf.Ide.Serie,
+		IssuedAt:    issuedAt,
+		EmitterCnpj: inf.Emit.CNPJ,
+		EmitterName: strings.TrimSpace(inf.Emit.XNome),
+		TotalValue:  total,
+	}
+
+	for _, det := range inf.Det {
+		number, err := strconv.Atoi(det.NItem)
+		if err != nil {
+			return nil, fmt.Errorf("número do item inválido: %s", det.NItem)
+		}
+
+		item := Item{
+			Number:       number,
+			SupplierCode: strings.TrimSpace(det.Prod.CProd),
+			Barcode:      barcode(det.Prod.CEAN, det.Prod.CEANTrib),
+			TaxBarcode:   barcode(det.Prod.CEANTrib),
+			Description:  strings.TrimSpace(det.Prod.XProd),
+			Unit:         strings.ToUpper(strings.TrimSpace(det.Prod.UCom)),
+			TaxUnit:      strings.ToUpper(strings.TrimSpace(det.Prod.UTrib)),
+		}
+
+		if item.Quantity, err = parseNumber(det.Prod.QCom); err != nil {
+			return nil, fmt.Errorf("item %d: quantidade inválida", number)
+		}
+		if strings.TrimSpace(det.Prod.QTrib) != "" {
+			if item.TaxQuantity, err = parseNumber(det.Prod.QTrib); err != nil {
+				return nil, fmt.Errorf("item %d: quantidade tributável inválida", number)
+			}
+		}
+		if item.UnitCost, err = parseNumber(det.Prod.VUnCom); err != nil {
+			return nil, fmt.Errorf("item %d: valor unitário inválido", number)
+		}
+		if item.TotalCost, err = parseNumber(det.Prod.VProd); err != nil {
+			return nil, fmt.Errorf("item %d: valor do produto inválido", number)
+		}
+
+		for _, rastro := range det.Prod.Rastro {
+			lot := Lot{Number: strings.TrimSpace(rastro.NLote)}
+			if lot.Quantity, err = parseNumber(rastro.QLote); err != nil {
+				return nil, fmt.Errorf("item %d: quantidade do lote inválida", number)
+			}
+			if lot.ManufacturedAt, err = parseDateTime(rastro.DFab); err != nil {
+				return nil, fmt.Errorf("item %d: data de fabricação do lote inválida", number)
+			}
+			if lot.ExpiresAt, err = parseDateTime(rastro.DVal); err != nil {
+				return nil, fmt.Errorf("item %d: data de validade do lote inválida", number)
+			}
+			item.Lots = append(item.Lots, lot)
+		}
+
+		invoice.Items = append(invoice.Items, item)
+	}
+
+	return invoice, nil
+}
+
+// barcode prefers the commercial GTIN and falls back to the taxable unit GTIN
+func barcode(values ...string) string {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value != "" && !strings.EqualFold(value, withoutGTIN) {
+			return value
+		}
+	}
+	return ""
+}
+
+func parseNumber(value string) (float64, error) {
+	return strconv.ParseFloat(strings.TrimSpace(value), 64)
+}
+
+func parseDateTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
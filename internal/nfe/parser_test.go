@@ -0,0 +1,92 @@
+package nfe
+
+import (
+	"strings"
+	"testing"
+)
+
+const sampleProc = `<?xml version="1.0" encoding="UTF-8"?>
+<nfeProc xmlns="http://www.portalfiscal.inf.br/nfe" versao="4.00">
+  <NFe>
+    <infNFe Id="NFe35240111222333000181550010000012341000012345" versao="4.00">
+      <ide><serie>1</serie><nNF>1234</nNF><dhEmi>2024-01-15T10:30:00-03:00</dhEmi></ide>
+      <emit><CNPJ>11222333000181</CNPJ><xNome>Distribuidora Exemplo LTDA</xNome></emit>
+      <det nItem="1">
+        <prod>
+          <cProd>A-100</cProd><cEAN>7891000315507</cEAN><xProd>CAFE TORRADO 500G</xProd>
+          <uCom>un</uCom><qCom>24.0000</qCom><vUnCom>12.5000000000</vUnCom><vProd>300.00</vProd>
+          <cEANTrib>7891000315507</cEANTrib><uTrib>un</uTrib><qTrib>24.0000</qTrib>
+          <rastro><nLote>L2401</nLote><qLote>24.000</qLote><dFab>2024-01-02</dFab><dVal>2024-12-31</dVal></rastro>
+        </prod>
+      </det>
+      <det nItem="2">
+        <prod>
+          <cProd>B-200</cProd><cEAN>SEM GTIN</cEAN><xProd>PAO FRANCES</xProd>
+          <uCom>KG</uCom><qCom>10.0000</qCom><vUnCom>9.9000000000</vUnCom><vProd>99.00</vProd>
+          <cEANTrib>SEM GTIN</cEANTrib>
+        </prod>
+      </det>
+      <total><ICMSTot><vNF>399.00</vNF></ICMSTot></total>
+    </infNFe>
+  </NFe>
+  <protNFe versao="4.00"><infProt><cStat>100</cStat></infProt></protNFe>
+</nfeProc>`
+
+func TestParse_NfeProc(t *testing.T) {
+	invoice, err := Parse(strings.NewReader(sampleProc))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if invoice.AccessKey != "35240111222333000181550010000012341000012345" {
+		t.Errorf("unexpected access key: %s", invoice.AccessKey)
+	}
+	if invoice.EmitterCnpj != "11222333000181" || invoice.Number != "1234" || invoice.Series != "1" {
+		t.Errorf("unexpected header: %+v", invoice)
+	}
+	if invoice.TotalValue != 399 {
+		t.Errorf("unexpected total: %v", invoice.TotalValue)
+	}
+	if len(invoice.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(invoice.Items))
+	}
+
+	first := invoice.Items[0]
+	if first.Barcode != "7891000315507" || first.Quantity != 24 || first.UnitCost != 12.5 || first.Unit != "UN" {
+		t.Errorf("unexpected first item: %+v", first)
+	}
+	if len(first.Lots) != 1 || first.Lots[0].Number != "L2401" || first.Lots[0].Quantity != 24 ||
+		first.Lots[0].ExpiresAt.Format("2006-01-02") != "2024-12-31" {
+		t.Errorf("unexpected lots: %+v", first.Lots)
+	}
+	if first.TaxUnit != "UN" || first.TaxQuantity != 24 || first.TaxBarcode != "7891000315507" {
+		t.Errorf("unexpected taxable unit: %s %v %s", first.TaxUnit, first.TaxQuantity, first.TaxBarcode)
+	}
+	if invoice.Items[1].TaxQuantity != 0 {
+		t.Errorf("expected no taxable quantity without qTrib, got %v", invoice.Items[1].TaxQuantity)
+	}
+	if invoice.Items[1].Barcode != "" || invoice.Items[1].TaxBarcode != "" || len(invoice.Items[1].Lots) != 0 {
+		t.Errorf("SEM GTIN should produce empty barcode and no lots, got %+v", invoice.Items[1])
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		xml     string
+		wantErr string
+	}{
+		{"not xml", "isto não é xml", "infNFe"},
+		{"wrong version", strings.Replace(sampleProc, `versao="4.00">`+"\n      <ide>", `versao="3.10">`+"\n      <ide>", 1), "versão"},
+		{"bad access key", strings.Replace(sampleProc, "NFe3524", "NFe24", 1), "chave de acesso"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.xml))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
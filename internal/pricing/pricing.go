@@ -0,0 +1,80 @@
+package pricing
+
+import "math"
+
+// Rounding rules applied to suggested sale prices
+const (
+	RoundingNone = "NENHUM"
+	Rounding99   = "X_99"
+	Rounding49   = "X_49"
+)
+
+// ValidRounding reports whether the rounding rule is known
+func ValidRounding(rule string) bool {
+	switch rule {
+	case RoundingNone, Rounding99, Rounding49:
+		return true
+	}
+	return false
+}
+
+// Markup is the profit over the cost, in percent. Returns nil when the cost is zero
+func Markup(cost, price float64) *float64 {
+	if cost <= 0 {
+		return nil
+	}
+	markup := round2((price - cost) / cost * 100)
+	return &markup
+}
+
+// Margin is the profit over the sale price, in percent. Returns nil when the price is zero
+func Margin(cost, price float64) *float64 {
+	if price <= 0 {
+		return nil
+	}
+	margin := round2((price - cost) / price * 100)
+	return &margin
+}
+
+// BelowMinimumMargin tells whether selling at price loses money or, when the category sets a
+// minimum margin, earns less than it
+func BelowMinimumMargin(cost, price float64, minimumMargin *float64) bool {
+	if price < cost {
+		return true
+	}
+	if minimumMargin == nil {
+		return false
+	}
+	margin := Margin(cost, price)
+	return margin != nil && *margin < *minimumMargin
+}
+
+// SuggestPrice applies the target markup to the cost and rounds the result with the rule
+func SuggestPrice(cost, targetMarkup float64, rounding string) float64 {
+	return RoundPrice(cost*(1+targetMarkup/100), rounding)
+}
+
+// RoundPrice rounds the price up to the closest value allowed by the rule:
+// X_99 ends in .99 and X_49 ends in .49 or .99
+func RoundPrice(price float64, rounding string) float64 {
+
+	cents := int64(math.Ceil(math.Round(price*10000) / 100))
+	reais, fraction := cents/100, cents%100
+
+	switch rounding {
+	case Rounding99:
+		fraction = 99
+	case Rounding49:
+		if fraction <= 49 {
+			fraction = 49
+		} else {
+			fraction = 99
+		}
+	}
+
+	return float64(reais*100+fraction) / 100
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
@@ -0,0 +1,65 @@
+package pricing
+
+import "testing"
+
+func TestRoundPrice(t *testing.T) {
+	tests := []struct {
+		price    float64
+		rounding string
+		want     float64
+	}{
+		{10.20, Rounding99, 10.99},
+		{10.99, Rounding99, 10.99},
+		{11.00, Rounding99, 11.99},
+		{10.20, Rounding49, 10.49},
+		{10.49, Rounding49, 10.49},
+		{10.50, Rounding49, 10.99},
+		{10.123, RoundingNone, 10.13},
+		{7.5, RoundingNone, 7.5},
+	}
+
+	for _, tt := range tests {
+		if got := RoundPrice(tt.price, tt.rounding); got != tt.want {
+			t.Errorf("RoundPrice(%v, %s) = %v, want %v", tt.price, tt.rounding, got, tt.want)
+		}
+	}
+}
+
+func TestMarkupAndMargin(t *testing.T) {
+	if got := *Markup(8, 10); got != 25 {
+		t.Errorf("Markup(8, 10) = %v, want 25", got)
+	}
+	if got := *Margin(8, 10); got != 20 {
+		t.Errorf("Margin(8, 10) = %v, want 20", got)
+	}
+	if Markup(0, 10) != nil || Margin(8, 0) != nil {
+		t.Errorf("expected nil for zero cost or price")
+	}
+}
+
+func TestBelowMinimumMargin(t *testing.T) {
+	twenty := 20.0
+	tests := []struct {
+		cost, price   float64
+		minimumMargin *float64
+		want          bool
+	}{
+		{8, 10, nil, false},
+		{10, 9.99, nil, true},
+		{8, 10, &twenty, false},
+		{8.5, 10, &twenty, true},
+		{0, 0, &twenty, false},
+	}
+
+	for _, tt := range tests {
+		if got := BelowMinimumMargin(tt.cost, tt.price, tt.minimumMargin); got != tt.want {
+			t.Errorf("BelowMinimumMargin(%v, %v, %v) = %v, want %v", tt.cost, tt.price, tt.minimumMargin, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestPrice(t *testing.T) {
+	if got := SuggestPrice(6.40, 50, Rounding99); got != 9.99 {
+		t.Errorf("SuggestPrice(6.40, 50, X_99) = %v, want 9.99", got)
+	}
+}
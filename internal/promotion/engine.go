@@ -0,0 +1,166 @@
+package promotion
+
+import (
+	"APIGolang/internal/model"
+	"math"
+)
+
+// Line is one product of the cart, with the quantities of the same product already merged
+type Line struct {
+	ProductId  int
+	CategoryId int
+	Quantity   int
+	UnitPrice  float64
+}
+
+// Discount is the amount taken off a line and the promotion responsible for it
+type Discount struct {
+	Amount      float64
+	PromotionId *int
+}
+
+// Apply returns the discount of each line, in the same order
+// Each line gets at most one promotion, the one that gives the customer the largest discount.
+// A combo replaces the promotions of the lines it covers only when its total discount is larger
+func Apply(lines []Line, promotions []model.Promotion) []Discount {
+
+	discounts := make([]Discount, len(lines))
+
+	for i, line := range lines {
+		for p := range promotions {
+			promo := &promotions[p]
+			if promo.Type == model.PromotionCombo {
+				continue
+			}
+			amount := lineDiscount(line, promo)
+			if amount > discounts[i].Amount {
+				discounts[i] = Discount{Amount: amount, PromotionId: &promo.Id}
+			}
+		}
+	}
+
+	for p := range promotions {
+		promo := &promotions[p]
+		if promo.Type == model.PromotionCombo {
+			applyCombo(lines, discounts, promo)
+		}
+	}
+
+	return discounts
+}
+
+func lineDiscount(line Line, promo *model.Promotion) float64 {
+
+	gross := line.UnitPrice * float64(line.Quantity)
+	var amount float64
+
+	switch promo.Type {
+	case model.PromotionPercent:
+		if covers(promo, line.ProductId) && promo.DiscountPercent != nil {
+			amount = gross * *promo.DiscountPercent / 100
+		}
+	case model.PromotionFixed:
+		if covers(promo, line.ProductId) && promo.FixedPrice != nil {
+			amount = (line.UnitPrice - *promo.FixedPrice) * float64(line.Quantity)
+		}
+	case model.PromotionBuyXPayY:
+		if covers(promo, line.ProductId) && promo.BuyQuantity != nil && promo.PayQuantity != nil && *promo.BuyQuantity > 0 {
+			free := (line.Quantity / *promo.BuyQuantity) * (*promo.BuyQuantity - *promo.PayQuantity)
+			amount = float64(free) * line.UnitPrice
+		}
+	case model.PromotionCategory:
+		if promo.CategoryId != nil && *promo.CategoryId == line.CategoryId && promo.DiscountPercent != nil {
+			amount = gross * *promo.DiscountPercent / 100
+		}
+	}
+
+	return clamp(Round(amount), gross)
+}
+
+// applyCombo sells as many complete combos as the cart allows for the combo price,
+// spreading the discount over the lines proportionally to their regular value
+func applyCombo(lines []Line, discounts []Discount, promo *model.Promotion) {
+
+	if promo.FixedPrice == nil || len(promo.Products) == 0 {
+		return
+	}
+
+	indexes := make([]int, len(promo.Products))
+	combos := math.MaxInt
+	regular := 0.0
+
+	for i, required := range promo.Products {
+		quantity := required.Quantity
+		if quantity <= 0 {
+			quantity = 1
+		}
+
+		indexes[i] = -1
+		for l, line := range lines {
+			if line.ProductId == required.ProductId {
+				indexes[i] = l
+				break
+			}
+		}
+		if indexes[i] < 0 {
+			return
+		}
+
+		line := lines[indexes[i]]
+		combos = min(combos, line.Quantity/quantity)
+		regular += line.UnitPrice * float64(quantity)
+	}
+
+	if combos == 0 || regular <= *promo.FixedPrice {
+		return
+	}
+
+	total := Round(float64(combos) * (regular - *promo.FixedPrice))
+
+	current := 0.0
+	for _, idx := range indexes {
+		current += discounts[idx].Amount
+	}
+	if total <= current {
+		return
+	}
+
+	allocated := 0.0
+	for i, required := range promo.Products {
+		idx := indexes[i]
+		quantity := max(required.Quantity, 1)
+
+		amount := total - allocated
+		if i < len(promo.Products)-1 {
+			share := lines[idx].UnitPrice * float64(quantity) / regular
+			amount = Round(total * share)
+		}
+		allocated += amount
+
+		discounts[idx] = Discount{Amount: amount, PromotionId: &promo.Id}
+	}
+}
+
+func covers(promo *model.Promotion, productId int) bool {
+	for _, product := range promo.Products {
+		if product.ProductId == productId {
+			return true
+		}
+	}
+	return false
+}
+
+func clamp(amount, gross float64) float64 {
+	if amount < 0 {
+		return 0
+	}
+	if amount > gross {
+		return gross
+	}
+	return amount
+}
+
+// Round rounds a monetary value to cents
+func Round(value float64) float64 {
+	return math.Round(value*100) / 100
+}
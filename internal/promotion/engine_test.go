@@ -0,0 +1,90 @@
+package promotion
+
+import (
+	"APIGolang/internal/model"
+	"testing"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestApply(t *testing.T) {
+	promotions := []model.Promotion{
+		{Id: 1, Type: model.PromotionPercent, DiscountPercent: ptr(10.0), Products: []model.PromotionProduct{{ProductId: 1}}},
+		{Id: 2, Type: model.PromotionBuyXPayY, BuyQuantity: ptr(3), PayQuantity: ptr(2), Products: []model.PromotionProduct{{ProductId: 2}}},
+		{Id: 3, Type: model.PromotionFixed, FixedPrice: ptr(4.0), Products: []model.PromotionProduct{{ProductId: 3}}},
+		{Id: 4, Type: model.PromotionCategory, CategoryId: ptr(9), DiscountPercent: ptr(5.0)},
+	}
+
+	lines := []Line{
+		{ProductId: 1, CategoryId: 1, Quantity: 2, UnitPrice: 10},  // 10% of 20
+		{ProductId: 2, CategoryId: 1, Quantity: 7, UnitPrice: 3},   // 2 free units
+		{ProductId: 3, CategoryId: 9, Quantity: 1, UnitPrice: 5.5}, // fixed price beats category
+		{ProductId: 4, CategoryId: 9, Quantity: 1, UnitPrice: 8},   // category 5%
+		{ProductId: 5, CategoryId: 2, Quantity: 1, UnitPrice: 8},   // no promotion
+	}
+
+	got := Apply(lines, promotions)
+
+	want := []struct {
+		amount      float64
+		promotionId int
+	}{{2, 1}, {6, 2}, {1.5, 3}, {0.4, 4}, {0, 0}}
+
+	for i, w := range want {
+		if got[i].Amount != w.amount {
+			t.Errorf("line %d: discount = %v, want %v", i, got[i].Amount, w.amount)
+		}
+		if w.promotionId == 0 && got[i].PromotionId != nil {
+			t.Errorf("line %d: unexpected promotion %d", i, *got[i].PromotionId)
+		}
+		if w.promotionId != 0 && (got[i].PromotionId == nil || *got[i].PromotionId != w.promotionId) {
+			t.Errorf("line %d: promotion = %v, want %d", i, got[i].PromotionId, w.promotionId)
+		}
+	}
+}
+
+func TestApply_Combo(t *testing.T) {
+	combo := model.Promotion{
+		Id: 7, Type: model.PromotionCombo, FixedPrice: ptr(10.0),
+		Products: []model.PromotionProduct{{ProductId: 1, Quantity: 1}, {ProductId: 2, Quantity: 2}},
+	}
+
+	lines := []Line{
+		{ProductId: 1, Quantity: 2, UnitPrice: 6},
+		{ProductId: 2, Quantity: 3, UnitPrice: 3},
+	}
+
+	// One complete combo: regular 6 + 2*3 = 12, sold for 10
+	got := Apply(lines, []model.Promotion{combo})
+
+	if total := got[0].Amount + got[1].Amount; total != 2 {
+		t.Errorf("combo discount = %v, want 2", total)
+	}
+	if got[0].Amount != 1 || got[1].Amount != 1 {
+		t.Errorf("unexpected allocation: %v / %v", got[0].Amount, got[1].Amount)
+	}
+}
+
+func TestApply_ComboLosesToBetterItemPromotion(t *testing.T) {
+	promotions := []model.Promotion{
+		{Id: 1, Type: model.PromotionPercent, DiscountPercent: ptr(50.0), Products: []model.PromotionProduct{{ProductId: 1}}},
+		{Id: 2, Type: model.PromotionCombo, FixedPrice: ptr(11.0),
+			Products: []model.PromotionProduct{{ProductId: 1, Quantity: 1}, {ProductId: 2, Quantity: 1}}},
+	}
+
+	lines := []Line{
+		{ProductId: 1, Quantity: 1, UnitPrice: 10},
+		{ProductId: 2, Quantity: 1, UnitPrice: 2},
+	}
+
+	got := Apply(lines, promotions)
+
+	if got[0].PromotionId == nil || *got[0].PromotionId != 1 || got[0].Amount != 5 {
+		t.Errorf("expected the 50%% promotion to be kept, got %+v", got[0])
+	}
+	if got[1].Amount != 0 {
+		t.Errorf("expected no discount on second line, got %v", got[1].Amount)
+	}
+}
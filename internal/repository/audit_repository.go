@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"APIGolang/internal/model"
+	"context"
+	"database/sql"
+)
+
+type AuditRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+	reader     Reader
+}
+
+func NewAuditRepository(connection *sql.DB, timeouts Timeouts) AuditRepository {
+	return AuditRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *AuditRepository) WithTx(tx *Tx) AuditRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+// WithReader returns a copy of the repository whose listings and reports run on reader
+func (r *AuditRepository) WithReader(reader Reader) AuditRepository {
+	bound := *r
+	bound.reader = reader
+	return bound
+}
+
+// Record appends an entry, the table rejects updates and deletes
+func (r *AuditRepository) Record(ctx context.Context, entry model.AuditEntry) error {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	_, err := r.connection.ExecContext(ctx, "INSERT INTO auditoria"+
+		" (usuario_id, nome_usuario, ip, user_agent, entidade, entidade_id, acao, alteracoes)"+
+		" VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		entry.UserId, entry.Username, entry.IP, entry.UserAgent,
+		entry.Entity, entry.EntityId, entry.Action, []byte(entry.Changes))
+	return err
+}
+
+// GetEntries returns the newest entries first, the period end is exclusive
+func (r *AuditRepository) GetEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	query := "SELECT id_auditoria, data, usuario_id, nome_usuario, COALESCE(ip, ''), COALESCE(user_agent, '')," +
+		" entidade, entidade_id, acao, alteracoes FROM auditoria" +
+		" WHERE data >= $1 AND data < $2" +
+		" AND ($3::text IS NULL OR entidade = $3)" +
+		" AND ($4::text IS NULL OR entidade_id = $4)" +
+		" AND ($5::int IS NULL OR usuario_id = $5)" +
+		" AND ($6::text IS NULL OR acao = $6)" +
+		" ORDER BY data DESC, id_auditoria DESC LIMIT $7 OFFSET $8"
+
+	rows, err := readConnection(r.connection, r.reader).QueryContext(ctx, query, filter.From, filter.To, filter.Entity, filter.EntityId,
+		filter.UserId, filter.Action, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []model.AuditEntry{}
+	for rows.Next() {
+		var entry model.AuditEntry
+		var changes []byte
+		if err := rows.Scan(&entry.Id, &entry.At, &entry.UserId, &entry.Username, &entry.IP, &entry.UserAgent,
+			&entry.Entity, &entry.EntityId, &entry.Action, &changes); err != nil {
+			return nil, err
+		}
+		entry.Changes = changes
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"APIGolang/internal/model"
+	"context"
+	"database/sql"
+)
+
+type CategoryRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+}
+
+func NewCategoryRepository(connection *sql.DB, timeouts Timeouts) CategoryRepository {
+	return CategoryRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *CategoryRepository) WithTx(tx *Tx) CategoryRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+func (r *CategoryRepository) GetCategories(ctx context.Context) ([]model.Category, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	var categories []model.Category
+
+	query := "SELECT id_categoria, nome, descricao, ativo FROM categoria ORDER BY nome"
+	rows, err := r.connection.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var category model.Category
+		if err := rows.Scan(&category.Id, &category.Name, &category.Description, &category.Active); err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *CategoryRepository) GetCategoryById(ctx context.Context, id int) (*model.Category, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	var category model.Category
+
+	query := "SELECT id_categoria, nome, descricao, ativo FROM categoria WHERE id_categoria = $1"
+	err := r.connection.QueryRowContext(ctx, query, id).Scan(&category.Id, &category.Name, &category.Description, &category.Active)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &category, nil
+}
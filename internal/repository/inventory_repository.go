@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// ErrInventoryClosed is returned when counting or closing a session that is no longer open
+var ErrInventoryClosed = apperror.Conflict("inventory_closed", "O inventário não está aberto")
+
+// ErrProductOutOfScope is returned when a counted product is not part of the session
+var ErrProductOutOfScope = apperror.Validation("product_out_of_scope", "Produto fora do escopo do inventário")
+
+const inventorySessionColumns = "i.id_inventario, i.categoria_id, i.status, i.observacao, i.usuario_abertura," +
+	" i.usuario_fechamento, i.data_abertura, i.data_fechamento," +
+	" (SELECT COUNT(*) FROM inventario_item ii WHERE ii.inventario_id = i.id_inventario)," +
+	" (SELECT COUNT(*) FROM inventario_item ii WHERE ii.inventario_id = i.id_inventario" +
+	" AND (ii.quantidade_contada IS NOT NULL OR EXISTS (SELECT 1 FROM inventario_contagem c" +
+	" WHERE c.inventario_id = ii.inventario_id AND c.produto_id = ii.produto_id)))"
+
+type InventoryRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+	reader     Reader
+}
+
+func NewInventoryRepository(connection *sql.DB, timeouts Timeouts) InventoryRepository {
+	return InventoryRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *InventoryRepository) WithTx(tx *Tx) InventoryRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+// WithReader returns a copy of the repository whose listings and reports run on reader
+func (r *InventoryRepository) WithReader(reader Reader) InventoryRepository {
+	bound := *r
+	bound.reader = reader
+	return bound
+}
+
+// OpenSession creates the session and freezes estoque_atual and preco_custo of every active,
+// stock-controlled product in scope
+func (r *InventoryRepository) OpenSession(ctx context.Context, session model.InventorySession) (int, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.connection)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx, "INSERT INTO inventario (categoria_id, status, observacao, usuario_abertura)"+
+		" VALUES ($1, $2, $3, $4) RETURNING id_inventario",
+		session.CategoryId, model.InventoryStatusOpen, session.Observation, session.OpenedBy,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO inventario_item (inventario_id, produto_id, estoque_congelado, custo_unitario)"+
+		" SELECT $1, id_produto, estoque_atual, preco_custo FROM produto"+
+		" WHERE ativo AND COALESCE(controla_estoque, TRUE) AND ($2::int IS NULL OR categoria_id = $2)",
+		id, session.CategoryId)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *InventoryRepository) GetSessions(ctx context.Context, status *string) ([]model.InventorySession, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	return r.querySessions(ctx, readConnection(r.connection, r.reader), "SELECT "+inventorySessionColumns+" FROM inventario i"+
+		" WHERE ($1::text IS NULL OR i.status = $1) ORDER BY i.data_abertura DESC, i.id_inventario DESC", status)
+}
+
+// GetOpenSessions reads the open sessions from the primary, as the overlap check before opening
+// another one must not miss a session the replica has not received yet
+func (r *InventoryRepository) GetOpenSessions(ctx context.Context) ([]model.InventorySession, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	return r.querySessions(ctx, r.connection, "SELECT "+inventorySessionColumns+" FROM inventario i"+
+		" WHERE i.status = $1 ORDER BY i.data_abertura DESC, i.id_inventario DESC", model.InventoryStatusOpen)
+}
+
+func (r *InventoryRepository) GetSessionById(ctx context.Context, id int) (*model.InventorySession, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	sessions, err := r.querySessions(ctx, r.connection, "SELECT "+inventorySessionColumns+" FROM inventario i WHERE i.id_inventario = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	return &sessions[0], nil
+}
+
+// SaveCounts stores the quantities counted by one device, replacing its previous count of the same products
+// Every item must have ProductId set
+func (r *InventoryRepository) SaveCounts(ctx context.Context, id int, device string, items []model.InventoryCountItem, userId *int) error {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.connection)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, "SELECT status FROM inventario WHERE id_inventario = $1 FOR SHARE", id).Scan(&status)
+	if err != nil {
+		return err
+	}
+	if status != model.InventoryStatusOpen {
+		return ErrInventoryClosed
+	}
+
+	for _, item := range items {
+		_, err = tx.ExecContext(ctx, "INSERT INTO inventario_contagem (inventario_id, produto_id, dispositivo, quantidade, usuario_id)"+
+			" VALUES ($1, $2, $3, $4, $5)"+
+			" ON CONFLICT (inventario_id, produto_id, dispositivo) DO UPDATE"+
+			" SET quantidade = EXCLUDED.quantidade, usuario_id = EXCLUDED.usuario_id, data_contagem = NOW()",
+			id, *item.ProductId, device, item.Quantity, userId)
+		if err != nil {
+			var pqErr *pq.Error
+			if errors.As(err, &pqErr) && pqErr.Code == "23503" {
+				return ErrProductOutOfScope.With("Produto %d fora do escopo do inventário", *item.ProductId)
+			}
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CloseSession posts one adjustment per product whose count differs from the frozen stock.
+// The variance is added to the current estoque_atual, so sales made while counting are preserved.
+// Uncounted products are skipped unless zeroUncounted is set. Missing units also leave the lots
+// of the products that control them
+func (r *InventoryRepository) CloseSession(ctx context.Context, id int, zeroUncounted bool, userId *int) error {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.connection)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, "SELECT status FROM inventario WHERE id_inventario = $1 FOR UPDATE", id).Scan(&status)
+	if err != nil {
+		return err
+	}
+	if status != model.InventoryStatusOpen {
+		return ErrInventoryClosed
+	}
+
+	rows, err := tx.QueryContext(ctx, "SELECT ii.produto_id, ii.estoque_congelado, c.contado"+
+		" FROM inventario_item ii"+
+		" LEFT JOIN (SELECT produto_id, SUM(quantidade) AS contado FROM inventario_contagem"+
+		" WHERE inventario_id = $1 GROUP BY produto_id) c ON c.produto_id = ii.produto_id"+
+		" WHERE ii.inventario_id = $1 ORDER BY ii.produto_id", id)
+	if err != nil {
+		return err
+	}
+
+	type countedItem struct {
+		productId, frozen int
+		counted           *int
+	}
+	var items []countedItem
+	for rows.Next() {
+		var item countedItem
+		if err := rows.Scan(&item.productId, &item.frozen, &item.counted); err != nil {
+			rows.Close()
+			return err
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	observation := fmt.Sprintf("Inventário %d", id)
+
+	for _, item := range items {
+		if item.counted == nil {
+			if !zeroUncounted {
+				continue
+			}
+			zero := 0
+			item.counted = &zero
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE inventario_item SET quantidade_contada = $1 WHERE inventario_id = $2 AND produto_id = $3",
+			*item.counted, id, item.productId)
+		if err != nil {
+			return err
+		}
+
+		variance := *item.counted - item.frozen
+		if variance == 0 {
+			continue
+		}
+
+		var controlsLots bool
+		err = tx.QueryRowContext(ctx, "UPDATE produto SET estoque_atual = estoque_atual + $1, data_atualizacao = NOW()"+
+			" WHERE id_produto = $2 RETURNING controla_lote",
+			variance, item.productId,
+		).Scan(&controlsLots)
+		if err != nil {
+			return err
+		}
+
+		movement, quantity := model.StockMovementAdjustmentIn, variance
+		if variance < 0 {
+			movement, quantity = model.StockMovementAdjustmentOut, -variance
+		}
+
+		// Missing units leave the lots too, expired ones included, so they keep adding up to the
+		// stock; a single lot is linked to the movement
+		var lotId *int
+		if controlsLots && variance < 0 {
+			taken, err := takeLots(ctx, tx, item.productId, quantity, true)
+			if err != nil {
+				return err
+			}
+			if len(taken) == 1 {
+				lotId = &taken[0].lotId
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO movimentacao_estoque"+
+			" (produto_id, tipo_movimentacao, quantidade, observacao, usuario_id, inventario_id, lote_id)"+
+			" VALUES ($1, $2, $3, $4, $5, $6, $7)",
+			item.productId, movement, quantity, observation, userId, id, lotId)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE inventario SET status = $1, usuario_fechamento = $2, data_fechamento = NOW()"+
+		" WHERE id_inventario = $3",
+		model.InventoryStatusClosed, userId, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetReportItems returns every product of the session with the frozen stock and the quantity
+// counted so far (or the final count, once closed). Variance fields are left to the caller
+func (r *InventoryRepository) GetReportItems(ctx context.Context, id int) ([]model.InventoryReportItem, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	items := []model.InventoryReportItem{}
+
+	query := "SELECT p.id_produto, p.codigo_produto, p.nome, cat.nome, ii.estoque_congelado, ii.custo_unitario," +
+		" COALESCE(ii.quantidade_contada, c.contado)" +
+		" FROM inventario_item ii" +
+		" JOIN produto p ON p.id_produto = ii.produto_id" +
+		" JOIN categoria cat ON cat.id_categoria = p.categoria_id" +
+		" LEFT JOIN (SELECT produto_id, SUM(quantidade) AS contado FROM inventario_contagem" +
+		" WHERE inventario_id = $1 GROUP BY produto_id) c ON c.produto_id = ii.produto_id" +
+		" WHERE ii.inventario_id = $1 ORDER BY cat.nome, p.nome"
+
+	rows, err := r.connection.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.InventoryReportItem
+		err := rows.Scan(
+			&item.ProductId,
+			&item.Code,
+			&item.Name,
+			&item.Category,
+			&item.FrozenStock,
+			&item.UnitCost,
+			&item.CountedQuantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+func (r *InventoryRepository) querySessions(ctx context.Context, connection DBTX, query string, args ...any) ([]model.InventorySession, error) {
+
+	sessions := []model.InventorySession{}
+
+	rows, err := connection.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var session model.InventorySession
+		err := rows.Scan(
+			&session.Id,
+			&session.CategoryId,
+			&session.Status,
+			&session.Observation,
+			&session.OpenedBy,
+			&session.ClosedBy,
+			&session.OpenedAt,
+			&session.ClosedAt,
+			&session.Products,
+			&session.CountedProducts,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// ErrDuplicateInvoice is returned when the access key was already imported
+var ErrDuplicateInvoice = apperror.Conflict("duplicate_invoice", "NF-e já importada")
+
+type InvoiceRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+}
+
+func NewInvoiceRepository(connection *sql.DB, timeouts Timeouts) InvoiceRepository {
+	return InvoiceRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *InvoiceRepository) WithTx(tx *Tx) InvoiceRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+func (r *InvoiceRepository) AccessKeyExists(ctx context.Context, accessKey string) (bool, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	var count int
+
+	query := "SELECT Count(1) FROM nota_fiscal_entrada WHERE chave_acesso = $1"
+	err := r.connection.QueryRowContext(ctx, query, accessKey).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetProductIdBySupplierCode returns the product linked to the supplier's own product code
+func (r *InvoiceRepository) GetProductIdBySupplierCode(ctx context.Context, supplierId int, supplierCode string) (*int, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	var productId int
+
+	query := "SELECT produto_id FROM produto_fornecedor WHERE fornecedor_id = $1 AND codigo_fornecedor = $2"
+	err := r.connection.QueryRowContext(ctx, query, supplierId, supplierCode).Scan(&productId)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &productId, nil
+}
+
+// RegisterInvoice stores the invoice and, in the same transaction, creates the proposed products,
+// links the supplier codes, updates preco_custo and estoque_atual and inserts one ENTRADA
+// movement per item. Every item must have ProductId or ProposedProduct set, the id of the
+// created proposals is set on them
+// Returns the invoice id and how many products were created
+func (r *InvoiceRepository) RegisterInvoice(ctx context.Context, invoice *model.Invoice, userId *int) (int, int, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.connection)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	var invoiceId int
+	err = tx.QueryRowContext(ctx, "INSERT INTO nota_fiscal_entrada"+
+		" (chave_acesso, numero, serie, data_emissao, valor_total, fornecedor_id, usuario_id)"+
+		" VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id_nota_fiscal",
+		invoice.AccessKey, invoice.Number, invoice.Series, invoice.IssuedAt, invoice.TotalValue,
+		invoice.SupplierId, userId,
+	).Scan(&invoiceId)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return 0, 0, ErrDuplicateInvoice
+		}
+		return 0, 0, err
+	}
+
+	observation := fmt.Sprintf("NF-e %s série %s", invoice.Number, invoice.Series)
+	created := 0
+	// Lines of the same product share the proposal, which is inserted only once
+	proposed := map[*model.Product]int{}
+
+	for i := range invoice.Items {
+		item := &invoice.Items[i]
+
+		if item.ProductId == nil {
+			if item.ProposedProduct == nil {
+				return 0, 0, fmt.Errorf("item %d sem produto associado", item.ItemNumber)
+			}
+			productId, ok := proposed[item.ProposedProduct]
+			if !ok {
+				productId, err = insertProposedProduct(ctx, tx, item.ProposedProduct, userId)
+				var pqErr *pq.Error
+				if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+					return 0, 0, apperror.Conflict("product_code_taken", "Item %d: código %s ou código de barras já cadastrado",
+						item.ItemNumber, *item.ProposedProduct.Code)
+				}
+				if err != nil {
+					return 0, 0, fmt.Errorf("item %d: %w", item.ItemNumber, err)
+				}
+				proposed[item.ProposedProduct] = productId
+				created++
+			}
+			item.ProductId = &productId
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO produto_fornecedor (fornecedor_id, codigo_fornecedor, produto_id)"+
+			" VALUES ($1, $2, $3)"+
+			" ON CONFLICT (fornecedor_id, codigo_fornecedor) DO UPDATE SET produto_id = EXCLUDED.produto_id",
+			invoice.SupplierId, item.SupplierCode, *item.ProductId)
+		if err != nil {
+			return 0, 0, fmt.Errorf("item %d: %w", item.ItemNumber, err)
+		}
+
+		quantity := int(item.Quantity)
+
+		var oldCost, salePrice float64
+		var controlsLots bool
+		err = tx.QueryRowContext(ctx, "UPDATE produto p SET preco_custo = $1, estoque_atual = p.estoque_atual + $2,"+
+			" fornecedor_id = COALESCE(p.fornecedor_id, $3), data_atualizacao = NOW()"+
+			" FROM (SELECT preco_custo FROM produto WHERE id_produto = $4 FOR UPDATE) old"+
+			" WHERE p.id_produto = $4"+
+			" RETURNING old.preco_custo, p.preco_venda, p.controla_lote",
+			item.UnitCost, quantity, invoice.SupplierId, *item.ProductId,
+		).Scan(&oldCost, &salePrice, &controlsLots)
+		if err != nil {
+			return 0, 0, fmt.Errorf("item %d: %w", item.ItemNumber, err)
+		}
+
+		err = recordPriceChange(ctx, tx, *item.ProductId, &salePrice, &oldCost, salePrice, item.UnitCost,
+			model.PriceOriginInvoice, userId)
+		if err != nil {
+			return 0, 0, fmt.Errorf("item %d: %w", item.ItemNumber, err)
+		}
+
+		// Lots are only kept for products that control them; a single lot is linked to the movement
+		var lotId *int
+		if controlsLots {
+			for _, lot := range item.Lots {
+				id, err := addLot(ctx, tx, *item.ProductId, lot)
+				if err != nil {
+					return 0, 0, fmt.Errorf("item %d: %w", item.ItemNumber, err)
+				}
+				if len(item.Lots) == 1 {
+					lotId = &id
+				}
+			}
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO movimentacao_estoque"+
+			" (produto_id, tipo_movimentacao, quantidade, observacao, usuario_id, nota_fiscal_id, lote_id)"+
+			" VALUES ($1, 'ENTRADA', $2, $3, $4, $5, $6)",
+			*item.ProductId, quantity, observation, userId, invoiceId, lotId)
+		if err != nil {
+			return 0, 0, fmt.Errorf("item %d: %w", item.ItemNumber, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return invoiceId, created, nil
+}
+
+func insertProposedProduct(ctx context.Context, tx *Tx, product *model.Product, userId *int) (int, error) {
+
+	var id int
+	err := tx.QueryRowContext(ctx, "INSERT INTO produto"+
+		" (codigo_produto, codigo_barras, nome, categoria_id, fornecedor_id, preco_custo, preco_venda, unidade_medida)"+
+		" VALUES ($1, $2, $3, $4, $5, $6, $7, COALESCE($8, 'UN')) RETURNING id_produto",
+		product.Code, product.Barcode, product.Name, product.CategoryId, product.SupplierId,
+		product.CostPrice, product.Price, product.Unit,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+	product.Id = id
+
+	err = recordPriceChange(ctx, tx, id, nil, nil, *product.Price, *product.CostPrice, model.PriceOriginInvoice, userId)
+	return id, err
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// ErrLotInsufficient is returned when writing off more units than the lot has
+var ErrLotInsufficient = apperror.Conflict("lot_insufficient", "Quantidade maior que a disponível no lote")
+
+const lotColumns = "l.id_lote, l.produto_id, p.codigo_produto, p.nome, l.numero_lote, l.data_fabricacao, l.data_validade," +
+	" l.quantidade, l.quantidade_inicial, l.data_entrada, l.data_validade - CURRENT_DATE, l.quantidade * p.preco_custo"
+
+// addLot adds the units to the product lot, creating it on the first entry
+// Returns the lot id
+func addLot(ctx context.Context, tx *Tx, productId int, lot model.LotEntry) (int, error) {
+
+	var id int
+	err := tx.QueryRowContext(ctx, "INSERT INTO lote (produto_id, numero_lote, data_fabricacao, data_validade, quantidade, quantidade_inicial)"+
+		" VALUES ($1, $2, $3, $4, $5, $5)"+
+		" ON CONFLICT (produto_id, numero_lote) DO UPDATE"+
+		" SET quantidade = lote.quantidade + EXCLUDED.quantidade,"+
+		" quantidade_inicial = lote.quantidade_inicial + EXCLUDED.quantidade_inicial,"+
+		" data_fabricacao = COALESCE(EXCLUDED.data_fabricacao, lote.data_fabricacao),"+
+		" data_validade = COALESCE(EXCLUDED.data_validade, lote.data_validade)"+
+		" RETURNING id_lote",
+		productId, lot.Number, lot.ManufacturedAt, lot.ExpiresAt, lot.Quantity,
+	).Scan(&id)
+	return id, err
+}
+
+// lotTake is how many units were taken from a lot
+type lotTake struct{ lotId, quantity int }
+
+// takeLots takes quantity units from the product lots, first-expiring first (FEFO), and returns
+// what came from each lot. Expired lots are only taken from when withExpired is set. Units beyond
+// what the lots hold were entered without a lot and are not traced
+func takeLots(ctx context.Context, tx *Tx, productId, quantity int, withExpired bool) ([]lotTake, error) {
+
+	rows, err := tx.QueryContext(ctx, "SELECT id_lote, quantidade FROM lote"+
+		" WHERE produto_id = $1 AND quantidade > 0 AND ($2 OR data_validade IS NULL OR data_validade >= CURRENT_DATE)"+
+		" ORDER BY data_validade NULLS LAST, data_entrada, id_lote FOR UPDATE", productId, withExpired)
+	if err != nil {
+		return nil, err
+	}
+
+	var lots []lotTake
+	for rows.Next() {
+		var lot lotTake
+		if err := rows.Scan(&lot.lotId, &lot.quantity); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var taken []lotTake
+	for _, lot := range lots {
+		if quantity == 0 {
+			break
+		}
+		take := min(quantity, lot.quantity)
+
+		if _, err = tx.ExecContext(ctx, "UPDATE lote SET quantidade = quantidade - $1 WHERE id_lote = $2", take, lot.lotId); err != nil {
+			return nil, err
+		}
+		taken = append(taken, lotTake{lotId: lot.lotId, quantity: take})
+		quantity -= take
+	}
+
+	return taken, nil
+}
+
+// consumeLots takes the units sold from the product lots and links them to the sale item.
+// Expired lots are skipped, they must be written off; SaleRepository.CreateSale does not count
+// their units as available
+func consumeLots(ctx context.Context, tx *Tx, productId, quantity, saleItemId int) error {
+
+	taken, err := takeLots(ctx, tx, productId, quantity, false)
+	if err != nil {
+		return err
+	}
+	for _, lot := range taken {
+		_, err = tx.ExecContext(ctx, "INSERT INTO item_venda_lote (item_venda_id, lote_id, quantidade) VALUES ($1, $2, $3)",
+			saleItemId, lot.lotId, lot.quantity)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type LotRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+	reader     Reader
+}
+
+func NewLotRepository(connection *sql.DB, timeouts Timeouts) LotRepository {
+	return LotRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *LotRepository) WithTx(tx *Tx) LotRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+// WithReader returns a copy of the repository whose listings and reports run on reader
+func (r *LotRepository) WithReader(reader Reader) LotRepository {
+	bound := *r
+	bound.reader = reader
+	return bound
+}
+
+// GetLotsByProduct returns the product lots that still have units, first-expiring first
+func (r *LotRepository) GetLotsByProduct(ctx context.Context, productId int) ([]model.Lot, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	return r.queryLots(ctx, readConnection(r.connection, r.reader), "SELECT "+lotColumns+" FROM lote l JOIN produto p ON p.id_produto = l.produto_id"+
+		" WHERE l.produto_id = $1 AND l.quantidade > 0"+
+		" ORDER BY l.data_validade NULLS LAST, l.data_entrada, l.id_lote", productId)
+}
+
+// GetExpiringLots returns the lots with units left that expire until the given date, including the expired ones
+func (r *LotRepository) GetExpiringLots(ctx context.Context, until time.Time) ([]model.Lot, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	return r.queryLots(ctx, readConnection(r.connection, r.reader), "SELECT "+lotColumns+" FROM lote l JOIN produto p ON p.id_produto = l.produto_id"+
+		" WHERE l.quantidade > 0 AND l.data_validade <= $1"+
+		" ORDER BY l.data_validade, p.nome, l.id_lote", until)
+}
+
+func (r *LotRepository) GetLotById(ctx context.Context, id int) (*model.Lot, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	lots, err := r.queryLots(ctx, r.connection, "SELECT "+lotColumns+" FROM lote l JOIN produto p ON p.id_produto = l.produto_id"+
+		" WHERE l.id_lote = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(lots) == 0 {
+		return nil, nil
+	}
+	return &lots[0], nil
+}
+
+// WriteOff removes units of the lot from the stock, registering a SAIDA movement with the reason
+func (r *LotRepository) WriteOff(ctx context.Context, id int, quantity int, reason string, userId *int) error {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.connection)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var productId int
+	var number string
+	err = tx.QueryRowContext(ctx, "UPDATE lote SET quantidade = quantidade - $1 WHERE id_lote = $2 AND quantidade >= $1"+
+		" RETURNING produto_id, numero_lote", quantity, id).Scan(&productId, &number)
+	if err == sql.ErrNoRows {
+		return ErrLotInsufficient
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE produto SET estoque_atual = estoque_atual - $1, data_atualizacao = NOW() WHERE id_produto = $2",
+		quantity, productId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO movimentacao_estoque (produto_id, tipo_movimentacao, quantidade, observacao, usuario_id, lote_id)"+
+		" VALUES ($1, 'SAIDA', $2, $3, $4, $5)",
+		productId, quantity, fmt.Sprintf("Baixa do lote %s: %s", number, reason), userId, id)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *LotRepository) queryLots(ctx context.Context, connection DBTX, query string, args ...any) ([]model.Lot, error) {
+
+	lots := []model.Lot{}
+
+	rows, err := connection.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var lot model.Lot
+		err := rows.Scan(
+			&lot.Id,
+			&lot.ProductId,
+			&lot.ProductCode,
+			&lot.ProductName,
+			&lot.Number,
+			&lot.ManufacturedAt,
+			&lot.ExpiresAt,
+			&lot.Quantity,
+			&lot.InitialQuantity,
+			&lot.EnteredAt,
+			&lot.DaysToExpire,
+			&lot.StockValue,
+		)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return lots, nil
+}
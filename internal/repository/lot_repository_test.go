@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const takeLotsQuery = "SELECT id_lote, quantidade FROM lote" +
+	" WHERE produto_id = $1 AND quantidade > 0 AND ($2 OR data_validade IS NULL OR data_validade >= CURRENT_DATE)" +
+	" ORDER BY data_validade NULLS LAST, data_entrada, id_lote FOR UPDATE"
+
+func TestTakeLotsFirstExpiringFirst(t *testing.T) {
+	db, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(takeLotsQuery).WithArgs(7, true).
+		WillReturnRows(sqlmock.NewRows([]string{"id_lote", "quantidade"}).AddRow(1, 3).AddRow(2, 5).AddRow(3, 4))
+	mock.ExpectExec("UPDATE lote SET quantidade = quantidade - $1 WHERE id_lote = $2").WithArgs(3, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("UPDATE lote SET quantidade = quantidade - $1 WHERE id_lote = $2").WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	tx, err := beginTx(context.Background(), db)
+	if err != nil {
+		t.Fatalf("beginTx: %v", err)
+	}
+
+	taken, err := takeLots(context.Background(), tx, 7, 4, true)
+	if err != nil {
+		t.Fatalf("takeLots: %v", err)
+	}
+	if len(taken) != 2 || taken[0] != (lotTake{lotId: 1, quantity: 3}) || taken[1] != (lotTake{lotId: 2, quantity: 1}) {
+		t.Errorf("unexpected lots taken: %+v", taken)
+	}
+	tx.Rollback()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestTakeLotsBeyondTheLots(t *testing.T) {
+	db, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery(takeLotsQuery).WithArgs(7, false).
+		WillReturnRows(sqlmock.NewRows([]string{"id_lote", "quantidade"}).AddRow(2, 5))
+	mock.ExpectExec("UPDATE lote SET quantidade = quantidade - $1 WHERE id_lote = $2").WithArgs(5, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectRollback()
+
+	tx, err := beginTx(context.Background(), db)
+	if err != nil {
+		t.Fatalf("beginTx: %v", err)
+	}
+
+	// The 3 units left were entered without a lot
+	taken, err := takeLots(context.Background(), tx, 7, 8, false)
+	if err != nil {
+		t.Fatalf("takeLots: %v", err)
+	}
+	if len(taken) != 1 || taken[0] != (lotTake{lotId: 2, quantity: 5}) {
+		t.Errorf("unexpected lots taken: %+v", taken)
+	}
+	tx.Rollback()
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"APIGolang/internal/model"
+	"APIGolang/internal/pricing"
+	"context"
+	"database/sql"
+	"time"
+)
+
+// recordPriceChange appends a row to historico_preco when any of the prices really changed
+func recordPriceChange(ctx context.Context, exec DBTX, productId int, oldSale, oldCost *float64, newSale, newCost float64, origin string, userId *int) error {
+
+	if oldSale != nil && oldCost != nil && *oldSale == newSale && *oldCost == newCost {
+		return nil
+	}
+
+	_, err := exec.ExecContext(ctx, "INSERT INTO historico_preco"+
+		" (produto_id, preco_venda_anterior, preco_venda_novo, preco_custo_anterior, preco_custo_novo, origem, usuario_id)"+
+		" VALUES ($1, $2, $3, $4, $5, $6, $7)",
+		productId, oldSale, newSale, oldCost, newCost, origin, userId)
+	return err
+}
+
+type PriceRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+	reader     Reader
+}
+
+func NewPriceRepository(connection *sql.DB, timeouts Timeouts) PriceRepository {
+	return PriceRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *PriceRepository) WithTx(tx *Tx) PriceRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+// WithReader returns a copy of the repository whose listings and reports run on reader
+func (r *PriceRepository) WithReader(reader Reader) PriceRepository {
+	bound := *r
+	bound.reader = reader
+	return bound
+}
+
+func (r *PriceRepository) GetPriceHistory(ctx context.Context, productId int) ([]model.PriceHistoryEntry, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	history := []model.PriceHistoryEntry{}
+
+	query := "SELECT id_historico, produto_id, preco_venda_anterior, preco_venda_novo, preco_custo_anterior," +
+		" preco_custo_novo, origem, usuario_id, data_alteracao" +
+		" FROM historico_preco WHERE produto_id = $1 ORDER BY data_alteracao, id_historico"
+
+	rows, err := readConnection(r.connection, r.reader).QueryContext(ctx, query, productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var entry model.PriceHistoryEntry
+		err := rows.Scan(
+			&entry.Id,
+			&entry.ProductId,
+			&entry.OldSalePrice,
+			&entry.NewSalePrice,
+			&entry.OldCostPrice,
+			&entry.NewCostPrice,
+			&entry.Origin,
+			&entry.UserId,
+			&entry.ChangedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, entry)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (r *PriceRepository) GetScheduledChanges(ctx context.Context, productId int) ([]model.ScheduledPriceChange, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	changes := []model.ScheduledPriceChange{}
+
+	query := "SELECT id_alteracao, produto_id, preco_venda, preco_custo, data_vigencia, status, usuario_id," +
+		" data_criacao, data_aplicacao" +
+		" FROM alteracao_preco_agendada WHERE produto_id = $1 ORDER BY data_vigencia, id_alteracao"
+
+	rows, err := readConnection(r.connection, r.reader).QueryContext(ctx, query, productId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var change model.ScheduledPriceChange
+		err := rows.Scan(
+			&change.Id,
+			&change.ProductId,
+			&change.SalePrice,
+			&change.CostPrice,
+			&change.EffectiveAt,
+			&change.Status,
+			&change.UserId,
+			&change.CreatedAt,
+			&change.AppliedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, change)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func (r *PriceRepository) CreateScheduledChange(ctx context.Context, change model.ScheduledPriceChange) (int, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	var id int
+
+	query := "INSERT INTO alteracao_preco_agendada (produto_id, preco_venda, preco_custo, data_vigencia, usuario_id)" +
+		" VALUES ($1, $2, $3, $4, $5) RETURNING id_alteracao"
+	err := r.connection.QueryRowContext(ctx, query, change.ProductId, change.SalePrice, change.CostPrice, change.EffectiveAt,
+		change.UserId).Scan(&id)
+
+	return id, err
+}
+
+// CancelScheduledChange cancels a change that was not applied yet
+// Returns false when there is no pending change with that id for the product
+func (r *PriceRepository) CancelScheduledChange(ctx context.Context, productId, scheduleId int) (bool, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	query := "UPDATE alteracao_preco_agendada SET status = $1" +
+		" WHERE id_alteracao = $2 AND produto_id = $3 AND status = $4"
+
+	result, err := r.connection.ExecContext(ctx, query, model.ScheduleStatusCancelled, scheduleId, productId,
+		model.ScheduleStatusPending)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// ApplyDueChanges applies every pending change whose data_vigencia is not after now,
+// recording each one in historico_preco. Rows locked by another instance are skipped,
+// so several API replicas can run the scheduler at the same time. The cost may have changed
+// since the change was scheduled, so changes that would sell below cost or below the category
+// minimum margin are rejected instead
+// Returns how many changes were applied and how many rejected
+func (r *PriceRepository) ApplyDueChanges(ctx context.Context, now time.Time) (int, int, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.connection)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, "SELECT id_alteracao, produto_id, preco_venda, preco_custo, usuario_id"+
+		" FROM alteracao_preco_agendada"+
+		" WHERE status = $1 AND data_vigencia <= $2"+
+		" ORDER BY data_vigencia, id_alteracao"+
+		" FOR UPDATE SKIP LOCKED",
+		model.ScheduleStatusPending, now)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var due []model.ScheduledPriceChange
+	for rows.Next() {
+		var change model.ScheduledPriceChange
+		if err := rows.Scan(&change.Id, &change.ProductId, &change.SalePrice, &change.CostPrice, &change.UserId); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		due = append(due, change)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	applied, rejected := 0, 0
+	for _, change := range due {
+		var cost float64
+		var minimumMargin *float64
+		err := tx.QueryRowContext(ctx, "SELECT p.preco_custo, r.margem_minima FROM produto p"+
+			" LEFT JOIN regra_preco_categoria r ON r.categoria_id = p.categoria_id"+
+			" WHERE p.id_produto = $1 FOR UPDATE OF p",
+			change.ProductId,
+		).Scan(&cost, &minimumMargin)
+		if err != nil {
+			return 0, 0, err
+		}
+		if change.CostPrice != nil {
+			cost = *change.CostPrice
+		}
+		if pricing.BelowMinimumMargin(cost, change.SalePrice, minimumMargin) {
+			_, err = tx.ExecContext(ctx, "UPDATE alteracao_preco_agendada SET status = $1 WHERE id_alteracao = $2",
+				model.ScheduleStatusRejected, change.Id)
+			if err != nil {
+				return 0, 0, err
+			}
+			rejected++
+			continue
+		}
+
+		var oldSale, oldCost, newCost float64
+		err = tx.QueryRowContext(ctx, "UPDATE produto p SET preco_venda = $1, preco_custo = COALESCE($2, p.preco_custo),"+
+			" data_atualizacao = NOW()"+
+			" FROM (SELECT preco_venda, preco_custo FROM produto WHERE id_produto = $3 FOR UPDATE) old"+
+			" WHERE p.id_produto = $3"+
+			" RETURNING old.preco_venda, old.preco_custo, p.preco_custo",
+			change.SalePrice, change.CostPrice, change.ProductId,
+		).Scan(&oldSale, &oldCost, &newCost)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		err = recordPriceChange(ctx, tx, change.ProductId, &oldSale, &oldCost, change.SalePrice, newCost,
+			model.PriceOriginScheduled, change.UserId)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		_, err = tx.ExecContext(ctx, "UPDATE alteracao_preco_agendada SET status = $1, data_aplicacao = NOW() WHERE id_alteracao = $2",
+			model.ScheduleStatusApplied, change.Id)
+		if err != nil {
+			return 0, 0, err
+		}
+		applied++
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+	return applied, rejected, nil
+}
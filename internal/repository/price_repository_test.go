@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"APIGolang/internal/model"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+const insertPriceHistory = "INSERT INTO historico_preco" +
+	" (produto_id, preco_venda_anterior, preco_venda_novo, preco_custo_anterior, preco_custo_novo, origem, usuario_id)" +
+	" VALUES ($1, $2, $3, $4, $5, $6, $7)"
+
+func TestRecordPriceChange(t *testing.T) {
+	db, mock := newMock(t)
+	sale, cost, userId := 10.0, 8.0, 4
+
+	// Unchanged prices are not recorded
+	if err := recordPriceChange(context.Background(), db, 1, &sale, &cost, 10, 8, model.PriceOriginManual, &userId); err != nil {
+		t.Fatalf("recordPriceChange: %v", err)
+	}
+
+	mock.ExpectExec(insertPriceHistory).WithArgs(1, &sale, 12.0, &cost, 8.0, model.PriceOriginManual, &userId).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	if err := recordPriceChange(context.Background(), db, 1, &sale, &cost, 12, 8, model.PriceOriginManual, &userId); err != nil {
+		t.Fatalf("recordPriceChange: %v", err)
+	}
+
+	// A new product has no previous prices
+	mock.ExpectExec(insertPriceHistory).WithArgs(2, nil, 10.0, nil, 8.0, model.PriceOriginImport, nil).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+	if err := recordPriceChange(context.Background(), db, 2, nil, nil, 10, 8, model.PriceOriginImport, nil); err != nil {
+		t.Fatalf("recordPriceChange: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestApplyDueChanges(t *testing.T) {
+	db, mock := newMock(t)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	newCost, userId := 9.0, 4
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT id_alteracao, produto_id, preco_venda, preco_custo, usuario_id"+
+		" FROM alteracao_preco_agendada"+
+		" WHERE status = $1 AND data_vigencia <= $2"+
+		" ORDER BY data_vigencia, id_alteracao"+
+		" FOR UPDATE SKIP LOCKED").
+		WithArgs(model.ScheduleStatusPending, now).
+		WillReturnRows(sqlmock.NewRows([]string{"id_alteracao", "produto_id", "preco_venda", "preco_custo", "usuario_id"}).
+			AddRow(11, 1, 12.0, nil, userId).
+			AddRow(12, 2, 10.0, newCost, nil))
+
+	costAndMargin := "SELECT p.preco_custo, r.margem_minima FROM produto p" +
+		" LEFT JOIN regra_preco_categoria r ON r.categoria_id = p.categoria_id" +
+		" WHERE p.id_produto = $1 FOR UPDATE OF p"
+
+	// Product 1 keeps its cost of 8 and has no minimum margin: applied and recorded
+	mock.ExpectQuery(costAndMargin).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"preco_custo", "margem_minima"}).AddRow(8.0, nil))
+	mock.ExpectQuery("UPDATE produto p SET preco_venda = $1, preco_custo = COALESCE($2, p.preco_custo),"+
+		" data_atualizacao = NOW()"+
+		" FROM (SELECT preco_venda, preco_custo FROM produto WHERE id_produto = $3 FOR UPDATE) old"+
+		" WHERE p.id_produto = $3"+
+		" RETURNING old.preco_venda, old.preco_custo, p.preco_custo").
+		WithArgs(12.0, nil, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"preco_venda", "preco_custo", "preco_custo"}).AddRow(10.0, 8.0, 8.0))
+	mock.ExpectExec(insertPriceHistory).WithArgs(1, 10.0, 12.0, 8.0, 8.0, model.PriceOriginScheduled, userId).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("UPDATE alteracao_preco_agendada SET status = $1, data_aplicacao = NOW() WHERE id_alteracao = $2").
+		WithArgs(model.ScheduleStatusApplied, 11).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// Product 2 would sell at 10 with the scheduled cost of 9, a 10% margin under the minimum of 20%
+	mock.ExpectQuery(costAndMargin).WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"preco_custo", "margem_minima"}).AddRow(7.0, 20.0))
+	mock.ExpectExec("UPDATE alteracao_preco_agendada SET status = $1 WHERE id_alteracao = $2").
+		WithArgs(model.ScheduleStatusRejected, 12).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	priceRepository := NewPriceRepository(db, Timeouts{})
+	applied, rejected, err := priceRepository.ApplyDueChanges(context.Background(), now)
+	if err != nil {
+		t.Fatalf("ApplyDueChanges: %v", err)
+	}
+	if applied != 1 || rejected != 1 {
+		t.Errorf("got %d applied and %d rejected, want 1 and 1", applied, rejected)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"APIGolang/internal/model"
+	"context"
+	"database/sql"
+)
+
+type PricingRuleRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+}
+
+func NewPricingRuleRepository(connection *sql.DB, timeouts Timeouts) PricingRuleRepository {
+	return PricingRuleRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *PricingRuleRepository) WithTx(tx *Tx) PricingRuleRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+// GetRuleByCategory returns nil when the category has no pricing rule
+func (r *PricingRuleRepository) GetRuleByCategory(ctx context.Context, categoryId int) (*model.PricingRule, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	var rule model.PricingRule
+
+	query := "SELECT categoria_id, markup_alvo, margem_minima, arredondamento FROM regra_preco_categoria WHERE categoria_id = $1"
+	err := r.connection.QueryRowContext(ctx, query, categoryId).Scan(&rule.CategoryId, &rule.TargetMarkup, &rule.MinimumMargin, &rule.Rounding)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// GetRules returns every pricing rule indexed by category
+func (r *PricingRuleRepository) GetRules(ctx context.Context) (map[int]model.PricingRule, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	rules := make(map[int]model.PricingRule)
+
+	query := "SELECT categoria_id, markup_alvo, margem_minima, arredondamento FROM regra_preco_categoria"
+	rows, err := r.connection.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule model.PricingRule
+		if err := rows.Scan(&rule.CategoryId, &rule.TargetMarkup, &rule.MinimumMargin, &rule.Rounding); err != nil {
+			return nil, err
+		}
+		rules[rule.CategoryId] = rule
+	}
+
+	return rules, rows.Err()
+}
+
+func (r *PricingRuleRepository) SaveRule(ctx context.Context, rule model.PricingRule) error {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	query := "INSERT INTO regra_preco_categoria (categoria_id, markup_alvo, margem_minima, arredondamento)" +
+		" VALUES ($1, $2, $3, $4)" +
+		" ON CONFLICT (categoria_id) DO UPDATE SET markup_alvo = EXCLUDED.markup_alvo," +
+		" margem_minima = EXCLUDED.margem_minima, arredondamento = EXCLUDED.arredondamento, data_atualizacao = NOW()"
+
+	_, err := r.connection.ExecContext(ctx, query, rule.CategoryId, rule.TargetMarkup, rule.MinimumMargin, rule.Rounding)
+	return err
+}
@@ -2,62 +2,176 @@ package repository
 
 import (
 	"APIGolang/internal/model"
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+
+	"github.com/lib/pq"
 )
 
+const productColumns = "id_produto, codigo_produto, codigo_barras, nome, descricao, categoria_id, fornecedor_id," +
+	" preco_custo, preco_venda, unidade_medida, estoque_atual, estoque_minimo, controla_estoque, controla_lote, ativo"
+
 type ProductRepository struct {
-	connection *sql.DB
+	connection DBTX
+	timeouts   Timeouts
+	reader     Reader
+	logger     *slog.Logger
 }
 
-func NewProductRepository(connection *sql.DB) ProductRepository {
+func NewProductRepository(connection *sql.DB, timeouts Timeouts, logger *slog.Logger) ProductRepository {
 	return ProductRepository{
 		connection: connection,
+		timeouts:   timeouts,
+		logger:     logger.With("repository", "product"),
 	}
 }
 
-func (pr *ProductRepository) GetProducts() ([]model.Product, error) {
-	query := "SELECT product_id, product_name, product_price FROM product"
-	rows, err := pr.connection.Query(query)
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (pr *ProductRepository) WithTx(tx *Tx) ProductRepository {
+	bound := *pr
+	bound.connection = tx
+	return bound
+}
+
+// WithReader returns a copy of the repository whose listings and reports run on reader
+func (pr *ProductRepository) WithReader(reader Reader) ProductRepository {
+	bound := *pr
+	bound.reader = reader
+	return bound
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanProduct(row rowScanner, product *model.Product) error {
+	return row.Scan(
+		&product.Id,
+		&product.Code,
+		&product.Barcode,
+		&product.Name,
+		&product.Description,
+		&product.CategoryId,
+		&product.SupplierId,
+		&product.CostPrice,
+		&product.Price,
+		&product.Unit,
+		&product.CurrentStock,
+		&product.MinimumStock,
+		&product.ControlsStock,
+		&product.ControlsLots,
+		&product.Active,
+	)
+}
+
+func (pr *ProductRepository) GetProducts(ctx context.Context) ([]model.Product, error) {
+
+	var productList []model.Product
+	err := pr.EachProduct(ctx, func(product model.Product) error {
+		productList = append(productList, product)
+		return nil
+	})
 	if err != nil {
-		fmt.Println(err)
 		return []model.Product{}, err
 	}
+	return productList, nil
+}
 
-	var productList []model.Product
-	var productObj model.Product
-
-	for rows.Next(){
-		err = rows.Scan(
-			&productObj.Id,
-			&productObj.Name,
-			&productObj.Price)
-		if err != nil{
-			fmt.Println(err)
-			return []model.Product{}, err
+// EachProduct calls fn for every product, reading one row at a time so large
+// catalogs can be exported without loading them into memory
+func (pr *ProductRepository) EachProduct(ctx context.Context, fn func(model.Product) error) error {
+
+	ctx, cancel := pr.timeouts.report(ctx)
+	defer cancel()
+
+	query := "SELECT " + productColumns + " FROM produto ORDER BY id_produto"
+	rows, err := readConnection(pr.connection, pr.reader).QueryContext(ctx, query)
+	if err != nil {
+		pr.logger.ErrorContext(ctx, "query failed", "operation", "EachProduct", "error", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var productObj model.Product
+		err = scanProduct(rows, &productObj)
+		if err != nil {
+			pr.logger.ErrorContext(ctx, "query failed", "operation", "EachProduct", "error", err)
+			return err
+		}
+
+		if err = fn(productObj); err != nil {
+			return err
 		}
-		
-		productList = append(productList, productObj)
 	}
-	rows.Close()
-	return productList, nil
 
+	return rows.Err()
 }
 
-func (pr *ProductRepository) GetProductById(product_id int) (*model.Product, error) {
-	
-	query, err := pr.connection.Prepare("SELECT * FROM product WHERE product_id = $1")
+func (pr *ProductRepository) GetProductById(ctx context.Context, product_id int) (*model.Product, error) {
+
+	ctx, cancel := pr.timeouts.query(ctx)
+	defer cancel()
+
+	query, err := pr.connection.PrepareContext(ctx, "SELECT "+productColumns+" FROM produto WHERE id_produto = $1")
 	if err != nil {
-		fmt.Println(err)
+		pr.logger.ErrorContext(ctx, "query failed", "operation", "GetProductById", "error", err)
 		return nil, err
 	}
+	defer query.Close()
 
 	var produto model.Product
-	err = query.QueryRow(product_id).Scan(
-		&produto.Id,
-		&produto.Name,
-		&produto.Price,
-	)
+	err = scanProduct(query.QueryRowContext(ctx, product_id), &produto)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &produto, nil
+}
+
+// GetProductsByIds returns the products found, indexed by id
+func (pr *ProductRepository) GetProductsByIds(ctx context.Context, ids []int) (map[int]model.Product, error) {
+
+	ctx, cancel := pr.timeouts.query(ctx)
+	defer cancel()
+
+	products := make(map[int]model.Product)
+	if len(ids) == 0 {
+		return products, nil
+	}
+
+	query := "SELECT " + productColumns + " FROM produto WHERE id_produto = ANY($1)"
+	rows, err := pr.connection.QueryContext(ctx, query, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var product model.Product
+		if err := scanProduct(rows, &product); err != nil {
+			return nil, err
+		}
+		products[product.Id] = product
+	}
+
+	return products, rows.Err()
+}
+
+func (pr *ProductRepository) GetProductByCode(ctx context.Context, code string) (*model.Product, error) {
+
+	ctx, cancel := pr.timeouts.query(ctx)
+	defer cancel()
+
+	query := "SELECT " + productColumns + " FROM produto WHERE codigo_produto = $1"
+
+	var produto model.Product
+	err := scanProduct(pr.connection.QueryRowContext(ctx, query, code), &produto)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -65,74 +179,187 @@ func (pr *ProductRepository) GetProductById(product_id int) (*model.Product, err
 		return nil, err
 	}
 
-	query.Close()
 	return &produto, nil
 }
 
-func (pr *ProductRepository) CreateProduct(product model.Product) (int, error) {
+func (pr *ProductRepository) GetProductByBarcode(ctx context.Context, barcode string) (*model.Product, error) {
+
+	ctx, cancel := pr.timeouts.query(ctx)
+	defer cancel()
+
+	query := "SELECT " + productColumns + " FROM produto WHERE codigo_barras = $1"
+
+	var produto model.Product
+	err := scanProduct(pr.connection.QueryRowContext(ctx, query, barcode), &produto)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &produto, nil
+}
+
+func (pr *ProductRepository) CreateProduct(ctx context.Context, product model.Product, userId *int) (int, error) {
+
+	ctx, cancel := pr.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, pr.connection)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
 
 	var id int
-	query, err := pr.connection.Prepare("INSERT INTO product" +
-		"(product_name, product_price)" +
-		" VALUES ($1, $2) RETURNING product_id")
+	var salePrice, costPrice float64
+	query, err := tx.PrepareContext(ctx, "INSERT INTO produto"+
+		" (codigo_produto, codigo_barras, nome, descricao, categoria_id, fornecedor_id, preco_custo, preco_venda,"+
+		" unidade_medida, estoque_atual, estoque_minimo, controla_estoque, controla_lote, ativo)"+
+		" VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, 'UN'), COALESCE($10, 0), COALESCE($11, 0),"+
+		" COALESCE($12, TRUE), COALESCE($13, FALSE), COALESCE($14, TRUE)) RETURNING id_produto, preco_venda, preco_custo")
 	if err != nil {
-		fmt.Println(err)
+		pr.logger.ErrorContext(ctx, "query failed", "operation", "CreateProduct", "error", err)
 		return 0, err
 	}
+	defer query.Close()
 
-	err = query.QueryRow(product.Name, product.Price).Scan(&id)
+	err = query.QueryRowContext(ctx,
+		product.Code, product.Barcode, product.Name, product.Description, product.CategoryId, product.SupplierId,
+		product.CostPrice, product.Price, product.Unit, product.CurrentStock, product.MinimumStock,
+		product.ControlsStock, product.ControlsLots, product.Active,
+	).Scan(&id, &salePrice, &costPrice)
 	if err != nil {
-		fmt.Println(err)
+		pr.logger.ErrorContext(ctx, "query failed", "operation", "CreateProduct", "error", err)
 		return 0, err
 	}
 
-	query.Close()
+	err = recordPriceChange(ctx, tx, id, nil, nil, salePrice, costPrice, model.PriceOriginManual, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
 	return id, nil
 }
 
-func (pr *ProductRepository) UpdateProductById(product_id int, product model.Product) (*model.Product, error) {
+// UpdateProductById applies a partial update and records the price change, if any, in historico_preco
+func (pr *ProductRepository) UpdateProductById(ctx context.Context, product_id int, product model.Product, userId *int) (*model.Product, error) {
 
-	oldProduct, err := pr.GetProductById(product_id)
-	if err != nil{
-		fmt.Println(err)
+	ctx, cancel := pr.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, pr.connection)
+	if err != nil {
 		return nil, err
 	}
-	
-	if product.Name == nil {
-		product.Name = oldProduct.Name
-	}
-	if product.Price == nil {
-		product.Price = oldProduct.Price
+	defer tx.Rollback()
+
+	var oldProduct model.Product
+	err = scanProduct(tx.QueryRowContext(ctx, "SELECT "+productColumns+" FROM produto WHERE id_produto = $1 FOR UPDATE", product_id), &oldProduct)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		pr.logger.ErrorContext(ctx, "query failed", "operation", "UpdateProductById", "error", err)
+		return nil, err
 	}
-	
+
+	mergeProduct(&product, &oldProduct)
+
 	var updatedProduct model.Product
 
-	query, err := pr.connection.Prepare("UPDATE product" +
-		" SET product_name = $1, product_price = $2" + 
-		" WHERE product_id = $3 RETURNING product_id, product_name, product_price")
-	if err != nil{
-		fmt.Println(err)
+	query, err := tx.PrepareContext(ctx, "UPDATE produto"+
+		" SET codigo_produto = $1, codigo_barras = $2, nome = $3, descricao = $4, categoria_id = $5,"+
+		" fornecedor_id = $6, preco_custo = $7, preco_venda = $8, unidade_medida = $9, estoque_minimo = $10,"+
+		" controla_estoque = $11, controla_lote = $12, ativo = $13, data_atualizacao = NOW()"+
+		" WHERE id_produto = $14 RETURNING "+productColumns)
+	if err != nil {
+		pr.logger.ErrorContext(ctx, "query failed", "operation", "UpdateProductById", "error", err)
+		return nil, err
+	}
+	defer query.Close()
+
+	err = scanProduct(query.QueryRowContext(ctx,
+		product.Code, product.Barcode, product.Name, product.Description, product.CategoryId, product.SupplierId,
+		product.CostPrice, product.Price, product.Unit, product.MinimumStock, product.ControlsStock,
+		product.ControlsLots, product.Active, product_id,
+	), &updatedProduct)
+	if err != nil {
+		pr.logger.ErrorContext(ctx, "query failed", "operation", "UpdateProductById", "error", err)
 		return nil, err
 	}
 
-	err = query.QueryRow(product.Name, product.Price, product_id).Scan(&updatedProduct.Id, &updatedProduct.Name, &updatedProduct.Price)
+	err = recordPriceChange(ctx, tx, product_id, oldProduct.Price, oldProduct.CostPrice, *updatedProduct.Price,
+		*updatedProduct.CostPrice, model.PriceOriginManual, userId)
 	if err != nil {
-		fmt.Println(err)
 		return nil, err
 	}
 
-	query.Close()
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
 	return &updatedProduct, nil
 }
 
-func (pr *ProductRepository) DeleteProductById(product_id int) (bool, error) {
+// mergeProduct fills the fields left empty in product with the stored values,
+// so a partial update only touches what the client sent
+func mergeProduct(product *model.Product, old *model.Product) {
+	if product.Code == nil {
+		product.Code = old.Code
+	}
+	if product.Barcode == nil {
+		product.Barcode = old.Barcode
+	}
+	if product.Name == nil {
+		product.Name = old.Name
+	}
+	if product.Description == nil {
+		product.Description = old.Description
+	}
+	if product.CategoryId == nil {
+		product.CategoryId = old.CategoryId
+	}
+	if product.SupplierId == nil {
+		product.SupplierId = old.SupplierId
+	}
+	if product.CostPrice == nil {
+		product.CostPrice = old.CostPrice
+	}
+	if product.Price == nil {
+		product.Price = old.Price
+	}
+	if product.Unit == nil {
+		product.Unit = old.Unit
+	}
+	if product.MinimumStock == nil {
+		product.MinimumStock = old.MinimumStock
+	}
+	if product.ControlsStock == nil {
+		product.ControlsStock = old.ControlsStock
+	}
+	if product.ControlsLots == nil {
+		product.ControlsLots = old.ControlsLots
+	}
+	if product.Active == nil {
+		product.Active = old.Active
+	}
+}
+
+func (pr *ProductRepository) DeleteProductById(ctx context.Context, product_id int) (bool, error) {
+
+	ctx, cancel := pr.timeouts.query(ctx)
+	defer cancel()
 
-	query := "DELETE FROM product" + 
-		" WHERE product_id = $1"
+	query := "DELETE FROM produto" +
+		" WHERE id_produto = $1"
 
-	result, err := pr.connection.Exec(query, product_id)
-	if err != nil{
-		fmt.Println(err)
+	result, err := pr.connection.ExecContext(ctx, query, product_id)
+	if err != nil {
+		pr.logger.ErrorContext(ctx, "query failed", "operation", "DeleteProductById", "error", err)
 		return false, err
 	}
 
@@ -146,5 +373,144 @@ func (pr *ProductRepository) DeleteProductById(product_id int) (bool, error) {
 	}
 
 	return true, nil
+}
+
+// ImportProducts upserts the products by codigo_produto in a single transaction and returns each
+// one as it was before and after the import, Before is nil for the products created
+func (pr *ProductRepository) ImportProducts(ctx context.Context, products []model.Product, userId *int) ([]model.ImportedProduct, error) {
+
+	ctx, cancel := pr.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, pr.connection)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The row is locked and read before the upsert, so the price history and the audit get the previous values
+	current, err := tx.PrepareContext(ctx, "SELECT "+productColumns+" FROM produto WHERE codigo_produto = $1 FOR UPDATE")
+	if err != nil {
+		return nil, err
+	}
+	defer current.Close()
 
-}
\ No newline at end of file
+	stmt, err := tx.PrepareContext(ctx, "INSERT INTO produto"+
+		" (codigo_produto, codigo_barras, nome, descricao, categoria_id, fornecedor_id, preco_custo, preco_venda,"+
+		" unidade_medida, estoque_atual, estoque_minimo, controla_estoque, ativo)"+
+		" VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, 'UN'), COALESCE($10, 0), COALESCE($11, 0),"+
+		" COALESCE($12, TRUE), COALESCE($13, TRUE))"+
+		" ON CONFLICT (codigo_produto) DO UPDATE SET"+
+		" codigo_barras = COALESCE(EXCLUDED.codigo_barras, produto.codigo_barras),"+
+		" nome = EXCLUDED.nome,"+
+		" descricao = COALESCE(EXCLUDED.descricao, produto.descricao),"+
+		" categoria_id = EXCLUDED.categoria_id,"+
+		" fornecedor_id = COALESCE(EXCLUDED.fornecedor_id, produto.fornecedor_id),"+
+		" preco_custo = EXCLUDED.preco_custo,"+
+		" preco_venda = EXCLUDED.preco_venda,"+
+		" unidade_medida = COALESCE($9, produto.unidade_medida),"+
+		" estoque_minimo = COALESCE($11, produto.estoque_minimo),"+
+		" controla_estoque = COALESCE($12, produto.controla_estoque),"+
+		" ativo = COALESCE($13, produto.ativo),"+
+		" data_atualizacao = NOW()"+
+		" RETURNING "+productColumns)
+	if err != nil {
+		return nil, err
+	}
+	defer stmt.Close()
+
+	imported := make([]model.ImportedProduct, 0, len(products))
+	for _, product := range products {
+		var entry model.ImportedProduct
+		var before model.Product
+		err = scanProduct(current.QueryRowContext(ctx, product.Code), &before)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("produto %s: %w", *product.Code, err)
+		}
+		var oldSale, oldCost *float64
+		if err == nil {
+			entry.Before = &before
+			oldSale, oldCost = before.Price, before.CostPrice
+		}
+
+		err = scanProduct(stmt.QueryRowContext(ctx,
+			product.Code, product.Barcode, product.Name, product.Description, product.CategoryId, product.SupplierId,
+			product.CostPrice, product.Price, product.Unit, product.CurrentStock, product.MinimumStock,
+			product.ControlsStock, product.Active,
+		), &entry.After)
+		if err != nil {
+			return nil, fmt.Errorf("produto %s: %w", *product.Code, err)
+		}
+
+		err = recordPriceChange(ctx, tx, entry.After.Id, oldSale, oldCost, *entry.After.Price, *entry.After.CostPrice,
+			model.PriceOriginImport, userId)
+		if err != nil {
+			return nil, fmt.Errorf("produto %s: %w", *product.Code, err)
+		}
+
+		imported = append(imported, entry)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+	return imported, nil
+}
+
+// BarcodeOwners returns the codigo_produto that currently owns each of the given barcodes
+func (pr *ProductRepository) BarcodeOwners(ctx context.Context, barcodes []string) (map[string]string, error) {
+
+	ctx, cancel := pr.timeouts.query(ctx)
+	defer cancel()
+
+	owners := make(map[string]string)
+	if len(barcodes) == 0 {
+		return owners, nil
+	}
+
+	query := "SELECT codigo_barras, codigo_produto FROM produto WHERE codigo_barras = ANY($1)"
+	rows, err := pr.connection.QueryContext(ctx, query, pq.Array(barcodes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var barcode, code string
+		if err := rows.Scan(&barcode, &code); err != nil {
+			return nil, err
+		}
+		owners[barcode] = code
+	}
+
+	return owners, rows.Err()
+}
+
+// ExistingProductCodes returns which of the given codigo_produto are already registered
+func (pr *ProductRepository) ExistingProductCodes(ctx context.Context, codes []string) (map[string]bool, error) {
+
+	ctx, cancel := pr.timeouts.query(ctx)
+	defer cancel()
+
+	existing := make(map[string]bool)
+	if len(codes) == 0 {
+		return existing, nil
+	}
+
+	query := "SELECT codigo_produto FROM produto WHERE codigo_produto = ANY($1)"
+	rows, err := pr.connection.QueryContext(ctx, query, pq.Array(codes))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code string
+		if err := rows.Scan(&code); err != nil {
+			return nil, err
+		}
+		existing[code] = true
+	}
+
+	return existing, rows.Err()
+}
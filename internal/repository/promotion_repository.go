@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"APIGolang/internal/model"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const promotionColumns = "id_promocao, nome, tipo, percentual_desconto, preco_fixo, quantidade_leve, quantidade_pague," +
+	" categoria_id, data_inicio, data_fim, ativo"
+
+type PromotionRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+}
+
+func NewPromotionRepository(connection *sql.DB, timeouts Timeouts) PromotionRepository {
+	return PromotionRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *PromotionRepository) WithTx(tx *Tx) PromotionRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+func (r *PromotionRepository) CreatePromotion(ctx context.Context, promotion model.Promotion) (int, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.connection)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx, "INSERT INTO promocao"+
+		" (nome, tipo, percentual_desconto, preco_fixo, quantidade_leve, quantidade_pague, categoria_id, data_inicio, data_fim)"+
+		" VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id_promocao",
+		promotion.Name, promotion.Type, promotion.DiscountPercent, promotion.FixedPrice, promotion.BuyQuantity,
+		promotion.PayQuantity, promotion.CategoryId, promotion.StartsAt, promotion.EndsAt,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, product := range promotion.Products {
+		_, err = tx.ExecContext(ctx, "INSERT INTO promocao_produto (promocao_id, produto_id, quantidade) VALUES ($1, $2, $3)",
+			id, product.ProductId, max(product.Quantity, 1))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *PromotionRepository) GetPromotions(ctx context.Context) ([]model.Promotion, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	return r.queryPromotions(ctx, "SELECT "+promotionColumns+" FROM promocao ORDER BY data_inicio DESC, id_promocao")
+}
+
+// GetActivePromotions returns the active promotions valid at the given time
+func (r *PromotionRepository) GetActivePromotions(ctx context.Context, at time.Time) ([]model.Promotion, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	return r.queryPromotions(ctx, "SELECT "+promotionColumns+" FROM promocao"+
+		" WHERE ativo AND data_inicio <= $1 AND data_fim > $1 ORDER BY id_promocao", at)
+}
+
+func (r *PromotionRepository) GetPromotionById(ctx context.Context, id int) (*model.Promotion, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	promotions, err := r.queryPromotions(ctx, "SELECT "+promotionColumns+" FROM promocao WHERE id_promocao = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(promotions) == 0 {
+		return nil, nil
+	}
+	return &promotions[0], nil
+}
+
+func (r *PromotionRepository) DeactivatePromotion(ctx context.Context, id int) (bool, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	result, err := r.connection.ExecContext(ctx, "UPDATE promocao SET ativo = FALSE WHERE id_promocao = $1 AND ativo", id)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (r *PromotionRepository) queryPromotions(ctx context.Context, query string, args ...any) ([]model.Promotion, error) {
+
+	promotions := []model.Promotion{}
+
+	rows, err := r.connection.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := make(map[int]int)
+	var ids []int
+	for rows.Next() {
+		var promotion model.Promotion
+		err := rows.Scan(
+			&promotion.Id,
+			&promotion.Name,
+			&promotion.Type,
+			&promotion.DiscountPercent,
+			&promotion.FixedPrice,
+			&promotion.BuyQuantity,
+			&promotion.PayQuantity,
+			&promotion.CategoryId,
+			&promotion.StartsAt,
+			&promotion.EndsAt,
+			&promotion.Active,
+		)
+		if err != nil {
+			return nil, err
+		}
+		promotion.Products = []model.PromotionProduct{}
+		index[promotion.Id] = len(promotions)
+		ids = append(ids, promotion.Id)
+		promotions = append(promotions, promotion)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return promotions, nil
+	}
+
+	productRows, err := r.connection.QueryContext(ctx, "SELECT promocao_id, produto_id, quantidade FROM promocao_produto"+
+		" WHERE promocao_id = ANY($1) ORDER BY promocao_id, produto_id", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer productRows.Close()
+
+	for productRows.Next() {
+		var promotionId int
+		var product model.PromotionProduct
+		if err := productRows.Scan(&promotionId, &product.ProductId, &product.Quantity); err != nil {
+			return nil, err
+		}
+		p := &promotions[index[promotionId]]
+		p.Products = append(p.Products, product)
+	}
+
+	return promotions, productRows.Err()
+}
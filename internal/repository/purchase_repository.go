@@ -0,0 +1,387 @@
+package repository
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrPurchaseOrderStatus is returned when the order status does not allow the operation
+var ErrPurchaseOrderStatus = apperror.Conflict("purchase_order_status", "O status do pedido não permite essa operação")
+
+// ErrReceiptExceedsOrder is returned when more units are received than are pending or the product is not in the order
+var ErrReceiptExceedsOrder = apperror.Conflict("receipt_exceeds_order", "Quantidade recebida maior que a pendente no pedido")
+
+// openPurchaseStatus are the orders whose pending quantities are already on the way
+var openPurchaseStatus = []string{
+	model.PurchaseStatusDraft,
+	model.PurchaseStatusSent,
+	model.PurchaseStatusPartiallyReceived,
+}
+
+const purchaseOrderColumns = "pc.id_pedido, pc.fornecedor_id, f.nome, pc.status, pc.observacao, pc.usuario_id," +
+	" pc.data_criacao, pc.data_envio, pc.data_recebimento," +
+	" (SELECT COALESCE(SUM(i.quantidade * i.custo_unitario), 0) FROM item_pedido_compra i WHERE i.pedido_id = pc.id_pedido)"
+
+type PurchaseRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+	reader     Reader
+}
+
+func NewPurchaseRepository(connection *sql.DB, timeouts Timeouts) PurchaseRepository {
+	return PurchaseRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *PurchaseRepository) WithTx(tx *Tx) PurchaseRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+// WithReader returns a copy of the repository whose listings and reports run on reader
+func (r *PurchaseRepository) WithReader(reader Reader) PurchaseRepository {
+	bound := *r
+	bound.reader = reader
+	return bound
+}
+
+// GetSuggestionCandidates returns, grouped by supplier, the active stock-controlled products with
+// the quantity sold since the given date and the quantity still pending in open orders
+// SuggestedQuantity is left for the caller to compute
+func (r *PurchaseRepository) GetSuggestionCandidates(ctx context.Context, since time.Time, supplierId *int) ([]model.PurchaseSuggestion, error) {
+
+	ctx, cancel := r.timeouts.report(ctx)
+	defer cancel()
+
+	suggestions := []model.PurchaseSuggestion{}
+
+	query := "SELECT f.id_fornecedor, f.nome, p.id_produto, p.codigo_produto, p.nome, p.estoque_atual," +
+		" COALESCE(p.estoque_minimo, 0), p.preco_custo, COALESCE(s.quantidade, 0), COALESCE(pp.pendente, 0)" +
+		" FROM produto p JOIN fornecedor f ON f.id_fornecedor = p.fornecedor_id" +
+		" LEFT JOIN (SELECT iv.produto_id, SUM(iv.quantidade) AS quantidade" +
+		" FROM item_venda iv JOIN venda v ON v.id_venda = iv.venda_id" +
+		" WHERE v.status = $1 AND v.data_venda >= $2 GROUP BY iv.produto_id) s ON s.produto_id = p.id_produto" +
+		" LEFT JOIN (SELECT i.produto_id, SUM(i.quantidade - i.quantidade_recebida) AS pendente" +
+		" FROM item_pedido_compra i JOIN pedido_compra pc ON pc.id_pedido = i.pedido_id" +
+		" WHERE pc.status = ANY($3) GROUP BY i.produto_id) pp ON pp.produto_id = p.id_produto" +
+		" WHERE p.ativo AND COALESCE(p.controla_estoque, TRUE) AND f.ativo" +
+		" AND ($4::int IS NULL OR f.id_fornecedor = $4)" +
+		" ORDER BY f.nome, f.id_fornecedor, p.nome"
+
+	rows, err := readConnection(r.connection, r.reader).QueryContext(ctx, query, model.SaleStatusCompleted, since, pq.Array(openPurchaseStatus), supplierId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var supplier model.PurchaseSuggestion
+		var item model.PurchaseSuggestionItem
+		err := rows.Scan(
+			&supplier.SupplierId,
+			&supplier.SupplierName,
+			&item.ProductId,
+			&item.Code,
+			&item.Name,
+			&item.CurrentStock,
+			&item.MinimumStock,
+			&item.UnitCost,
+			&item.QuantitySold,
+			&item.PendingQuantity,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		last := len(suggestions) - 1
+		if last < 0 || suggestions[last].SupplierId != supplier.SupplierId {
+			suggestions = append(suggestions, supplier)
+			last++
+		}
+		suggestions[last].Items = append(suggestions[last].Items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return suggestions, nil
+}
+
+// CreatePurchaseOrder stores the order and its items as a draft
+func (r *PurchaseRepository) CreatePurchaseOrder(ctx context.Context, order model.PurchaseOrder) (int, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.connection)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRowContext(ctx, "INSERT INTO pedido_compra (fornecedor_id, status, observacao, usuario_id)"+
+		" VALUES ($1, $2, $3, $4) RETURNING id_pedido",
+		order.SupplierId, model.PurchaseStatusDraft, order.Observation, order.UserId,
+	).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, item := range order.Items {
+		_, err = tx.ExecContext(ctx, "INSERT INTO item_pedido_compra (pedido_id, produto_id, quantidade, custo_unitario)"+
+			" VALUES ($1, $2, $3, $4)",
+			id, item.ProductId, item.Quantity, item.UnitCost)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+func (r *PurchaseRepository) GetPurchaseOrders(ctx context.Context, status *string) ([]model.PurchaseOrder, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	return r.queryPurchaseOrders(ctx, readConnection(r.connection, r.reader), "SELECT "+purchaseOrderColumns+
+		" FROM pedido_compra pc JOIN fornecedor f ON f.id_fornecedor = pc.fornecedor_id"+
+		" WHERE ($1::text IS NULL OR pc.status = $1) ORDER BY pc.data_criacao DESC, pc.id_pedido DESC", status)
+}
+
+func (r *PurchaseRepository) GetPurchaseOrderById(ctx context.Context, id int) (*model.PurchaseOrder, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	orders, err := r.queryPurchaseOrders(ctx, r.connection, "SELECT "+purchaseOrderColumns+
+		" FROM pedido_compra pc JOIN fornecedor f ON f.id_fornecedor = pc.fornecedor_id"+
+		" WHERE pc.id_pedido = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, nil
+	}
+	return &orders[0], nil
+}
+
+// SendPurchaseOrder marks a draft as sent to the supplier
+func (r *PurchaseRepository) SendPurchaseOrder(ctx context.Context, id int) error {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	result, err := r.connection.ExecContext(ctx, "UPDATE pedido_compra SET status = $1, data_envio = NOW()"+
+		" WHERE id_pedido = $2 AND status = $3",
+		model.PurchaseStatusSent, id, model.PurchaseStatusDraft)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return ErrPurchaseOrderStatus
+	}
+	return nil
+}
+
+// ReceivePurchaseOrder registers the delivered quantities in one transaction: for every item it
+// updates quantidade_recebida, preco_custo and estoque_atual, records the cost in historico_preco
+// and inserts an ENTRADA movement. The order becomes RECEBIDO when every item is complete
+// Returns the new status of the order
+func (r *PurchaseRepository) ReceivePurchaseOrder(ctx context.Context, id int, items []model.PurchaseOrderItemRequest, userId *int) (string, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.connection)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, "SELECT status FROM pedido_compra WHERE id_pedido = $1 FOR UPDATE", id).Scan(&status)
+	if err != nil {
+		return "", err
+	}
+	if status != model.PurchaseStatusSent && status != model.PurchaseStatusPartiallyReceived {
+		return "", ErrPurchaseOrderStatus
+	}
+
+	observation := fmt.Sprintf("Pedido de compra %d", id)
+
+	for _, item := range items {
+		var unitCost float64
+		err = tx.QueryRowContext(ctx, "UPDATE item_pedido_compra SET quantidade_recebida = quantidade_recebida + $1,"+
+			" custo_unitario = COALESCE($2, custo_unitario)"+
+			" WHERE pedido_id = $3 AND produto_id = $4 AND quantidade_recebida + $1 <= quantidade"+
+			" RETURNING custo_unitario",
+			item.Quantity, item.UnitCost, id, item.ProductId,
+		).Scan(&unitCost)
+		if err == sql.ErrNoRows {
+			return "", ErrReceiptExceedsOrder.With("Quantidade recebida maior que a pendente no pedido para o produto %d", item.ProductId)
+		}
+		if err != nil {
+			return "", err
+		}
+
+		var oldCost, salePrice float64
+		var controlsLots bool
+		err = tx.QueryRowContext(ctx, "UPDATE produto p SET preco_custo = $1, estoque_atual = p.estoque_atual + $2,"+
+			" data_atualizacao = NOW()"+
+			" FROM (SELECT preco_custo FROM produto WHERE id_produto = $3 FOR UPDATE) old"+
+			" WHERE p.id_produto = $3"+
+			" RETURNING old.preco_custo, p.preco_venda, p.controla_lote",
+			unitCost, item.Quantity, item.ProductId,
+		).Scan(&oldCost, &salePrice, &controlsLots)
+		if err != nil {
+			return "", err
+		}
+
+		err = recordPriceChange(ctx, tx, item.ProductId, &salePrice, &oldCost, salePrice, unitCost,
+			model.PriceOriginPurchase, userId)
+		if err != nil {
+			return "", err
+		}
+
+		// Units of a product that controls lots cannot enter the stock without one
+		var lotId *int
+		if controlsLots && item.Lot == nil {
+			return "", apperror.Validation("lot_required", "O produto %d controla lotes, informe o lote do recebimento", item.ProductId)
+		}
+		if controlsLots {
+			lot := *item.Lot
+			lot.Quantity = item.Quantity
+			created, err := addLot(ctx, tx, item.ProductId, lot)
+			if err != nil {
+				return "", err
+			}
+			lotId = &created
+		}
+
+		_, err = tx.ExecContext(ctx, "INSERT INTO movimentacao_estoque"+
+			" (produto_id, tipo_movimentacao, quantidade, observacao, usuario_id, pedido_compra_id, lote_id)"+
+			" VALUES ($1, 'ENTRADA', $2, $3, $4, $5, $6)",
+			item.ProductId, item.Quantity, observation, userId, id, lotId)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var complete bool
+	err = tx.QueryRowContext(ctx, "SELECT bool_and(quantidade_recebida >= quantidade) FROM item_pedido_compra WHERE pedido_id = $1",
+		id).Scan(&complete)
+	if err != nil {
+		return "", err
+	}
+
+	status = model.PurchaseStatusPartiallyReceived
+	query := "UPDATE pedido_compra SET status = $1 WHERE id_pedido = $2"
+	if complete {
+		status = model.PurchaseStatusReceived
+		query = "UPDATE pedido_compra SET status = $1, data_recebimento = NOW() WHERE id_pedido = $2"
+	}
+	if _, err = tx.ExecContext(ctx, query, status, id); err != nil {
+		return "", err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return "", err
+	}
+	return status, nil
+}
+
+func (r *PurchaseRepository) queryPurchaseOrders(ctx context.Context, connection DBTX, query string, args ...any) ([]model.PurchaseOrder, error) {
+
+	orders := []model.PurchaseOrder{}
+
+	rows, err := connection.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	index := make(map[int]int)
+	var ids []int
+
+	for rows.Next() {
+		var order model.PurchaseOrder
+		err := rows.Scan(
+			&order.Id,
+			&order.SupplierId,
+			&order.SupplierName,
+			&order.Status,
+			&order.Observation,
+			&order.UserId,
+			&order.CreatedAt,
+			&order.SentAt,
+			&order.ReceivedAt,
+			&order.TotalCost,
+		)
+		if err != nil {
+			return nil, err
+		}
+		order.Items = []model.PurchaseOrderItem{}
+		index[order.Id] = len(orders)
+		ids = append(ids, order.Id)
+		orders = append(orders, order)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return orders, nil
+	}
+
+	itemRows, err := r.connection.QueryContext(ctx, "SELECT i.id_item_pedido, i.pedido_id, i.produto_id, p.nome, i.quantidade,"+
+		" i.quantidade_recebida, i.custo_unitario"+
+		" FROM item_pedido_compra i JOIN produto p ON p.id_produto = i.produto_id"+
+		" WHERE i.pedido_id = ANY($1) ORDER BY i.id_item_pedido", pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var item model.PurchaseOrderItem
+		var orderId int
+		err := itemRows.Scan(
+			&item.Id,
+			&orderId,
+			&item.ProductId,
+			&item.ProductName,
+			&item.Quantity,
+			&item.ReceivedQuantity,
+			&item.UnitCost,
+		)
+		if err != nil {
+			return nil, err
+		}
+		order := &orders[index[orderId]]
+		order.Items = append(order.Items, item)
+	}
+
+	if err = itemRows.Err(); err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestReceivePurchaseOrderRequiresTheLot(t *testing.T) {
+	db, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status FROM pedido_compra WHERE id_pedido = $1 FOR UPDATE").WithArgs(4).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.PurchaseStatusSent))
+	mock.ExpectQuery("UPDATE item_pedido_compra SET quantidade_recebida = quantidade_recebida + $1,"+
+		" custo_unitario = COALESCE($2, custo_unitario)"+
+		" WHERE pedido_id = $3 AND produto_id = $4 AND quantidade_recebida + $1 <= quantidade"+
+		" RETURNING custo_unitario").WithArgs(6, nil, 4, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"custo_unitario"}).AddRow(2.5))
+	mock.ExpectQuery("UPDATE produto p SET preco_custo = $1, estoque_atual = p.estoque_atual + $2,"+
+		" data_atualizacao = NOW()"+
+		" FROM (SELECT preco_custo FROM produto WHERE id_produto = $3 FOR UPDATE) old"+
+		" WHERE p.id_produto = $3"+
+		" RETURNING old.preco_custo, p.preco_venda, p.controla_lote").WithArgs(2.5, 6, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"preco_custo", "preco_venda", "controla_lote"}).AddRow(2.5, 4.0, true))
+	mock.ExpectRollback()
+
+	repo := NewPurchaseRepository(db, Timeouts{})
+	_, err := repo.ReceivePurchaseOrder(context.Background(), 4, []model.PurchaseOrderItemRequest{{ProductId: 7, Quantity: 6}}, nil)
+
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Code != "lot_required" {
+		t.Fatalf("got %v, want lot_required", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+// Reader picks the pool for listings, reports and exports, which tolerate a little
+// replication lag: a read replica while it is healthy, the primary otherwise
+type Reader interface {
+	Reader() *sql.DB
+}
+
+// readConnection returns where a read-only query runs. Repositories without a reader, or bound
+// to a transaction, whose writes the replica has not seen, keep their own connection.
+// Lookups by id or code stay on the primary on purpose: they answer right after a write, or
+// validate one, as the session read with its inventory report, and a lagging replica would
+// return the row as it was before
+func readConnection(connection DBTX, reader Reader) DBTX {
+	if _, inTx := connection.(*Tx); inTx || reader == nil {
+		return connection
+	}
+	return reader.Reader()
+}
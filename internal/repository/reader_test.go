@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+)
+
+type fixedReader struct{ db *sql.DB }
+
+func (r fixedReader) Reader() *sql.DB { return r.db }
+
+func TestReadConnection(t *testing.T) {
+	primary, _ := newMock(t)
+	replica, _ := newMock(t)
+
+	if readConnection(primary, nil) != primary {
+		t.Error("expected the primary without a reader")
+	}
+	if readConnection(primary, fixedReader{replica}) != replica {
+		t.Error("expected the reader's pool")
+	}
+}
+
+func TestReadConnectionInTransaction(t *testing.T) {
+	primary, mock := newMock(t)
+	replica, _ := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	reportRepository := NewReportRepository(primary, Timeouts{})
+	reportRepository = reportRepository.WithReader(fixedReader{replica})
+
+	NewUnitOfWork(primary, Timeouts{}).Do(context.Background(), func(ctx context.Context, tx *Tx) error {
+		bound := reportRepository.WithTx(tx)
+		if readConnection(bound.connection, bound.reader) != tx {
+			t.Error("expected reads in the transaction, which the replica has not seen")
+		}
+		return sql.ErrNoRows
+	})
+}
@@ -0,0 +1,271 @@
+package repository
+
+import (
+	"APIGolang/internal/model"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// salesGrouping describes how the item_venda rows are grouped for a report
+// weight splits the values of a sale between its payment methods, it is 1 for the other groupings
+// Periods are ordered by date, the other groupings by net revenue
+type salesGrouping struct {
+	key     string
+	label   string
+	joins   string
+	weight  string
+	orderBy string
+}
+
+var salesGroupings = map[string]salesGrouping{
+	model.ReportGroupDay: {
+		key:     "to_char(date_trunc('day', v.data_venda), 'YYYY-MM-DD')",
+		label:   "to_char(date_trunc('day', v.data_venda), 'DD/MM/YYYY')",
+		weight:  "1",
+		orderBy: "chave",
+	},
+	model.ReportGroupWeek: {
+		key:     "to_char(date_trunc('week', v.data_venda), 'YYYY-MM-DD')",
+		label:   "to_char(date_trunc('week', v.data_venda), 'IYYY-\"S\"IW')",
+		weight:  "1",
+		orderBy: "chave",
+	},
+	model.ReportGroupMonth: {
+		key:     "to_char(date_trunc('month', v.data_venda), 'YYYY-MM')",
+		label:   "to_char(date_trunc('month', v.data_venda), 'MM/YYYY')",
+		weight:  "1",
+		orderBy: "chave",
+	},
+	model.ReportGroupUser: {
+		key:    "v.usuario_id::text",
+		label:  "u.nome",
+		joins:  " JOIN usuario u ON u.id_usuario = v.usuario_id",
+		weight: "1",
+	},
+	model.ReportGroupCategory: {
+		key:    "p.categoria_id::text",
+		label:  "c.nome",
+		joins:  " JOIN produto p ON p.id_produto = iv.produto_id JOIN categoria c ON c.id_categoria = p.categoria_id",
+		weight: "1",
+	},
+	model.ReportGroupPaymentMethod: {
+		key:   "f.id_forma_pagamento::text",
+		label: "f.descricao",
+		joins: " JOIN (SELECT venda_id, forma_pagamento_id," +
+			" SUM(valor_pago) / NULLIF(SUM(SUM(valor_pago)) OVER (PARTITION BY venda_id), 0) AS peso" +
+			" FROM pagamento GROUP BY venda_id, forma_pagamento_id) pg ON pg.venda_id = v.id_venda" +
+			" JOIN forma_pagamento f ON f.id_forma_pagamento = pg.forma_pagamento_id",
+		weight: "pg.peso",
+	},
+}
+
+// ValidSalesGrouping reports whether the report can be grouped by groupBy
+func ValidSalesGrouping(groupBy string) bool {
+	_, ok := salesGroupings[groupBy]
+	return ok
+}
+
+type ReportRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+	reader     Reader
+}
+
+func NewReportRepository(connection *sql.DB, timeouts Timeouts) ReportRepository {
+	return ReportRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *ReportRepository) WithTx(tx *Tx) ReportRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+// WithReader returns a copy of the repository whose listings and reports run on reader
+func (r *ReportRepository) WithReader(reader Reader) ReportRepository {
+	bound := *r
+	bound.reader = reader
+	return bound
+}
+
+// GetSalesTotals sums the completed sales with data_venda in [from, to)
+// Only the raw sums are filled, the derived values are left to the caller
+func (r *ReportRepository) GetSalesTotals(ctx context.Context, from, to time.Time) (model.SalesSummary, error) {
+
+	ctx, cancel := r.timeouts.report(ctx)
+	defer cancel()
+
+	var summary model.SalesSummary
+
+	query := "SELECT COUNT(DISTINCT iv.venda_id), COALESCE(SUM(iv.quantidade), 0), COALESCE(SUM(iv.subtotal), 0)," +
+		" COALESCE(SUM(iv.desconto), 0), COALESCE(SUM(iv.quantidade * iv.custo_unitario), 0)" +
+		" FROM item_venda iv JOIN venda v ON v.id_venda = iv.venda_id" +
+		" WHERE v.status = $1 AND v.data_venda >= $2 AND v.data_venda < $3"
+
+	err := readConnection(r.connection, r.reader).QueryRowContext(ctx, query, model.SaleStatusCompleted, from, to).Scan(
+		&summary.Sales,
+		&summary.ItemCount,
+		&summary.GrossRevenue,
+		&summary.Discounts,
+		&summary.Cost,
+	)
+	return summary, err
+}
+
+// GetSalesByGroup sums the completed sales with data_venda in [from, to) per group
+// Only the raw sums are filled, the derived values are left to the caller
+func (r *ReportRepository) GetSalesByGroup(ctx context.Context, groupBy string, from, to time.Time) ([]model.SalesReportGroup, error) {
+
+	ctx, cancel := r.timeouts.report(ctx)
+	defer cancel()
+
+	grouping, ok := salesGroupings[groupBy]
+	if !ok {
+		return nil, fmt.Errorf("agrupamento %q inválido", groupBy)
+	}
+
+	orderBy := grouping.orderBy
+	if orderBy == "" {
+		orderBy = fmt.Sprintf("SUM((iv.subtotal - iv.desconto) * %s) DESC", grouping.weight)
+	}
+
+	groups := []model.SalesReportGroup{}
+
+	query := fmt.Sprintf("SELECT %[1]s AS chave, %[2]s AS rotulo, COUNT(DISTINCT iv.venda_id),"+
+		" SUM(iv.quantidade * %[3]s), SUM(iv.subtotal * %[3]s), SUM(iv.desconto * %[3]s),"+
+		" SUM(iv.quantidade * iv.custo_unitario * %[3]s)"+
+		" FROM item_venda iv JOIN venda v ON v.id_venda = iv.venda_id%[4]s"+
+		" WHERE v.status = $1 AND v.data_venda >= $2 AND v.data_venda < $3"+
+		" GROUP BY 1, 2 ORDER BY %[5]s",
+		grouping.key, grouping.label, grouping.weight, grouping.joins, orderBy)
+
+	rows, err := readConnection(r.connection, r.reader).QueryContext(ctx, query, model.SaleStatusCompleted, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var group model.SalesReportGroup
+		err := rows.Scan(
+			&group.Key,
+			&group.Label,
+			&group.Sales,
+			&group.ItemCount,
+			&group.GrossRevenue,
+			&group.Discounts,
+			&group.Cost,
+		)
+		if err != nil {
+			return nil, err
+		}
+		groups = append(groups, group)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// GetProductSales returns the quantity and net revenue of every product in the completed sales
+// with data_venda in [from, to), sorted by revenue. Inactive products only appear if they were sold
+func (r *ReportRepository) GetProductSales(ctx context.Context, from, to time.Time) ([]model.AbcItem, error) {
+
+	ctx, cancel := r.timeouts.report(ctx)
+	defer cancel()
+
+	items := []model.AbcItem{}
+
+	query := "SELECT p.id_produto, p.codigo_produto, p.nome, c.nome, p.estoque_atual," +
+		" COALESCE(s.quantidade, 0), COALESCE(s.receita, 0)" +
+		" FROM produto p JOIN categoria c ON c.id_categoria = p.categoria_id" +
+		" LEFT JOIN (SELECT iv.produto_id, SUM(iv.quantidade) AS quantidade, SUM(iv.subtotal - iv.desconto) AS receita" +
+		" FROM item_venda iv JOIN venda v ON v.id_venda = iv.venda_id" +
+		" WHERE v.status = $1 AND v.data_venda >= $2 AND v.data_venda < $3" +
+		" GROUP BY iv.produto_id) s ON s.produto_id = p.id_produto" +
+		" WHERE p.ativo OR s.produto_id IS NOT NULL" +
+		" ORDER BY COALESCE(s.receita, 0) DESC, p.nome"
+
+	rows, err := readConnection(r.connection, r.reader).QueryContext(ctx, query, model.SaleStatusCompleted, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item model.AbcItem
+		err := rows.Scan(
+			&item.ProductId,
+			&item.Code,
+			&item.Name,
+			&item.Category,
+			&item.CurrentStock,
+			&item.QuantitySold,
+			&item.Revenue,
+		)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// GetIdleProducts returns the active products created before since that have no completed
+// sale after it, the ones with more money in stock first
+func (r *ReportRepository) GetIdleProducts(ctx context.Context, since time.Time) ([]model.IdleProduct, error) {
+
+	ctx, cancel := r.timeouts.report(ctx)
+	defer cancel()
+
+	products := []model.IdleProduct{}
+
+	query := "SELECT p.id_produto, p.codigo_produto, p.nome, c.nome, p.estoque_atual, p.preco_custo," +
+		" p.estoque_atual * p.preco_custo, s.ultima_venda" +
+		" FROM produto p JOIN categoria c ON c.id_categoria = p.categoria_id" +
+		" LEFT JOIN (SELECT iv.produto_id, MAX(v.data_venda) AS ultima_venda" +
+		" FROM item_venda iv JOIN venda v ON v.id_venda = iv.venda_id" +
+		" WHERE v.status = $1 GROUP BY iv.produto_id) s ON s.produto_id = p.id_produto" +
+		" WHERE p.ativo AND p.data_criacao < $2 AND (s.ultima_venda IS NULL OR s.ultima_venda < $2)" +
+		" ORDER BY p.estoque_atual * p.preco_custo DESC, p.nome"
+
+	rows, err := readConnection(r.connection, r.reader).QueryContext(ctx, query, model.SaleStatusCompleted, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var product model.IdleProduct
+		err := rows.Scan(
+			&product.ProductId,
+			&product.Code,
+			&product.Name,
+			&product.Category,
+			&product.CurrentStock,
+			&product.CostPrice,
+			&product.StockValue,
+			&product.LastSaleAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"context"
+	"database/sql"
+	"fmt"
+)
+
+// Cash register status stored in caixa.status
+const CashRegisterOpen = "A"
+
+var ErrInsufficientStock = apperror.Conflict("insufficient_stock", "Estoque insuficiente")
+
+type SaleRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+}
+
+func NewSaleRepository(connection *sql.DB, timeouts Timeouts) SaleRepository {
+	return SaleRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *SaleRepository) WithTx(tx *Tx) SaleRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+// GetCashRegisterStatus returns nil when the cash register does not exist
+func (r *SaleRepository) GetCashRegisterStatus(ctx context.Context, cashRegisterId int) (*string, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	var status string
+
+	query := "SELECT status FROM caixa WHERE id_caixa = $1"
+	err := r.connection.QueryRowContext(ctx, query, cashRegisterId).Scan(&status)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// CreateSale stores the sale, its items and payments and takes the sold quantities out of
+// stock with one SAIDA movement per item, all in one transaction
+// Returns ErrInsufficientStock, wrapped with the product, when a product that controls stock
+// does not have enough units
+func (r *SaleRepository) CreateSale(ctx context.Context, sale *model.Sale) (int, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.connection)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var saleId int
+	err = tx.QueryRowContext(ctx, "INSERT INTO venda"+
+		" (data_venda, valor_bruto, desconto, valor_total, status, cliente_id, usuario_id, caixa_id, autorizado_por)"+
+		" VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id_venda",
+		sale.Date, sale.GrossValue, sale.Discount, sale.TotalValue, sale.Status, sale.CustomerId, sale.UserId,
+		sale.CashRegisterId, sale.AuthorizedBy,
+	).Scan(&saleId)
+	if err != nil {
+		return 0, err
+	}
+
+	observation := fmt.Sprintf("Venda %d", saleId)
+
+	for i := range sale.Items {
+		item := &sale.Items[i]
+
+		err = tx.QueryRowContext(ctx, "INSERT INTO item_venda"+
+			" (venda_id, produto_id, quantidade, preco_unitario, subtotal, custo_unitario, desconto, promocao_id)"+
+			" VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id_item_venda",
+			saleId, item.ProductId, item.Quantity, item.UnitPrice, item.Subtotal, item.UnitCost, item.Discount,
+			item.PromotionId,
+		).Scan(&item.Id)
+		if err != nil {
+			return 0, err
+		}
+
+		// Units of expired lots stay in estoque_atual until written off, but can't be sold
+		var controlsStock, controlsLots bool
+		err = tx.QueryRowContext(ctx, "UPDATE produto SET estoque_atual = estoque_atual - $1"+
+			" WHERE id_produto = $2 AND (NOT COALESCE(controla_estoque, TRUE) OR estoque_atual - $1 >= CASE WHEN controla_lote"+
+			" THEN (SELECT COALESCE(SUM(quantidade), 0) FROM lote WHERE produto_id = $2 AND quantidade > 0 AND data_validade < CURRENT_DATE)"+
+			" ELSE 0 END)"+
+			" RETURNING COALESCE(controla_estoque, TRUE), controla_lote",
+			item.Quantity, item.ProductId,
+		).Scan(&controlsStock, &controlsLots)
+		if err == sql.ErrNoRows {
+			return 0, ErrInsufficientStock.With("Estoque insuficiente para o produto %s", item.ProductName)
+		}
+		if err != nil {
+			return 0, err
+		}
+
+		if controlsLots {
+			if err = consumeLots(ctx, tx, item.ProductId, item.Quantity, item.Id); err != nil {
+				return 0, err
+			}
+		}
+
+		if controlsStock {
+			_, err = tx.ExecContext(ctx, "INSERT INTO movimentacao_estoque (produto_id, tipo_movimentacao, quantidade, observacao, usuario_id)"+
+				" VALUES ($1, 'SAIDA', $2, $3, $4)",
+				item.ProductId, item.Quantity, observation, sale.UserId)
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	for _, payment := range sale.Payments {
+		_, err = tx.ExecContext(ctx, "INSERT INTO pagamento (venda_id, forma_pagamento_id, valor_pago) VALUES ($1, $2, $3)",
+			saleId, payment.PaymentMethodId, payment.Amount)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	return saleId, nil
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"APIGolang/internal/model"
+	"context"
+	"database/sql"
+)
+
+type StockRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+	reader     Reader
+}
+
+func NewStockRepository(connection *sql.DB, timeouts Timeouts) StockRepository {
+	return StockRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *StockRepository) WithTx(tx *Tx) StockRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+// WithReader returns a copy of the repository whose listings and reports run on reader
+func (r *StockRepository) WithReader(reader Reader) StockRepository {
+	bound := *r
+	bound.reader = reader
+	return bound
+}
+
+func (r *StockRepository) GetStockPositions(ctx context.Context) ([]model.StockPosition, error) {
+
+	positions := []model.StockPosition{}
+	err := r.EachStockPosition(ctx, func(position model.StockPosition) error {
+		positions = append(positions, position)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return positions, nil
+}
+
+// EachStockPosition calls fn for the stock position of every active product that controls stock,
+// reading one row at a time
+func (r *StockRepository) EachStockPosition(ctx context.Context, fn func(model.StockPosition) error) error {
+
+	ctx, cancel := r.timeouts.report(ctx)
+	defer cancel()
+
+	query := "SELECT p.id_produto, p.codigo_produto, p.nome, c.nome, COALESCE(p.unidade_medida, 'UN')," +
+		" p.estoque_atual, COALESCE(p.estoque_minimo, 0), p.preco_custo" +
+		" FROM produto p" +
+		" JOIN categoria c ON c.id_categoria = p.categoria_id" +
+		" WHERE p.ativo AND COALESCE(p.controla_estoque, TRUE)" +
+		" ORDER BY c.nome, p.nome"
+
+	rows, err := readConnection(r.connection, r.reader).QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var position model.StockPosition
+		err := rows.Scan(
+			&position.ProductId,
+			&position.Code,
+			&position.Name,
+			&position.Category,
+			&position.Unit,
+			&position.CurrentStock,
+			&position.MinimumStock,
+			&position.CostPrice,
+		)
+		if err != nil {
+			return err
+		}
+
+		position.StockValue = float64(position.CurrentStock) * position.CostPrice
+		position.BelowMinimum = position.CurrentStock < position.MinimumStock
+
+		if err := fn(position); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// RegisterEntry adds the units to estoque_atual, updates preco_custo when informed (recording it in
+// historico_preco), creates or tops up the lot and inserts the ENTRADA movement
+func (r *StockRepository) RegisterEntry(ctx context.Context, entry model.StockEntryRequest, userId *int) error {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	tx, err := beginTx(ctx, r.connection)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldCost, newCost, salePrice float64
+	err = tx.QueryRowContext(ctx, "UPDATE produto p SET estoque_atual = p.estoque_atual + $1,"+
+		" preco_custo = COALESCE($2, p.preco_custo), data_atualizacao = NOW()"+
+		" FROM (SELECT preco_custo FROM produto WHERE id_produto = $3 FOR UPDATE) old"+
+		" WHERE p.id_produto = $3"+
+		" RETURNING old.preco_custo, p.preco_custo, p.preco_venda",
+		entry.Quantity, entry.UnitCost, entry.ProductId,
+	).Scan(&oldCost, &newCost, &salePrice)
+	if err != nil {
+		return err
+	}
+
+	err = recordPriceChange(ctx, tx, entry.ProductId, &salePrice, &oldCost, salePrice, newCost, model.PriceOriginManual, userId)
+	if err != nil {
+		return err
+	}
+
+	var lotId *int
+	if entry.Lot != nil {
+		id, err := addLot(ctx, tx, entry.ProductId, *entry.Lot)
+		if err != nil {
+			return err
+		}
+		lotId = &id
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO movimentacao_estoque (produto_id, tipo_movimentacao, quantidade, observacao, usuario_id, lote_id)"+
+		" VALUES ($1, 'ENTRADA', $2, $3, $4, $5)",
+		entry.ProductId, entry.Quantity, entry.Observation, userId, lotId)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
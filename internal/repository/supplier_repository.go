@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"APIGolang/internal/model"
+	"context"
+	"database/sql"
+)
+
+type SupplierRepository struct {
+	connection DBTX
+	timeouts   Timeouts
+}
+
+func NewSupplierRepository(connection *sql.DB, timeouts Timeouts) SupplierRepository {
+	return SupplierRepository{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *SupplierRepository) WithTx(tx *Tx) SupplierRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+func (r *SupplierRepository) GetSuppliers(ctx context.Context) ([]model.Supplier, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	var suppliers []model.Supplier
+
+	query := "SELECT id_fornecedor, nome, cnpj, ativo FROM fornecedor ORDER BY nome"
+	rows, err := r.connection.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var supplier model.Supplier
+		if err := rows.Scan(&supplier.Id, &supplier.Name, &supplier.Cnpj, &supplier.Active); err != nil {
+			return nil, err
+		}
+		suppliers = append(suppliers, supplier)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return suppliers, nil
+}
+
+func (r *SupplierRepository) GetSupplierByCnpj(ctx context.Context, cnpj string) (*model.Supplier, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	var supplier model.Supplier
+
+	query := "SELECT id_fornecedor, nome, cnpj, ativo FROM fornecedor WHERE cnpj = $1"
+	err := r.connection.QueryRowContext(ctx, query, cnpj).Scan(&supplier.Id, &supplier.Name, &supplier.Cnpj, &supplier.Active)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &supplier, nil
+}
+
+func (r *SupplierRepository) GetSupplierById(ctx context.Context, id int) (*model.Supplier, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	var supplier model.Supplier
+
+	query := "SELECT id_fornecedor, nome, cnpj, ativo FROM fornecedor WHERE id_fornecedor = $1"
+	err := r.connection.QueryRowContext(ctx, query, id).Scan(&supplier.Id, &supplier.Name, &supplier.Cnpj, &supplier.Active)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &supplier, nil
+}
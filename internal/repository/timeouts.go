@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"APIGolang/internal/config"
+	"context"
+	"time"
+)
+
+// Timeouts bound every repository call, so a slow query is cancelled even when the caller's
+// context has no deadline. A shorter deadline already in the context is kept
+type Timeouts struct {
+	Query  time.Duration
+	Report time.Duration
+}
+
+func NewTimeouts(cfg config.Database) Timeouts {
+	return Timeouts{Query: cfg.QueryTimeout, Report: cfg.ReportTimeout}
+}
+
+// query bounds single statements and transactions
+func (t Timeouts) query(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, t.Query)
+}
+
+// report bounds reports and exports that read many rows
+func (t Timeouts) report(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, t.Report)
+}
+
+// withTimeout treats zero as no timeout, as in repositories built without configuration
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
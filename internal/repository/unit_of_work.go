@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// maxTxAttempts bounds how many times a unit of work runs while PostgreSQL keeps aborting it
+const maxTxAttempts = 3
+
+// DBTX is where repositories run their statements: the connection pool, or the transaction
+// of a unit of work when the repository was bound to it with WithTx
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Tx is a database transaction, or a savepoint of one when begun inside another transaction.
+// Commit and Rollback behave as in sql.Tx, so a deferred Rollback after Commit is harmless.
+// Savepoints must be begun and finished in order, from one goroutine
+type Tx struct {
+	*sql.Tx
+
+	savepoint string
+	ctx       context.Context
+	count     *int
+	done      bool
+}
+
+func begin(ctx context.Context, connection *sql.DB, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := connection.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, count: new(int)}, nil
+}
+
+// beginTx starts a transaction on the pool, or a savepoint when the repository is bound to a
+// unit of work, so the repository's own transactions nest in it
+func beginTx(ctx context.Context, connection DBTX) (*Tx, error) {
+	switch conn := connection.(type) {
+	case *sql.DB:
+		return begin(ctx, conn, nil)
+	case *Tx:
+		return conn.Savepoint(ctx)
+	}
+	return nil, fmt.Errorf("repository: cannot begin a transaction on %T", connection)
+}
+
+// Savepoint starts a nested transaction, whose Rollback undoes only the statements run since
+func (tx *Tx) Savepoint(ctx context.Context) (*Tx, error) {
+	*tx.count++
+	name := fmt.Sprintf("sp_%d", *tx.count)
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx.Tx, savepoint: name, ctx: ctx, count: tx.count}, nil
+}
+
+// Commit commits the transaction, or releases the savepoint into the enclosing transaction
+func (tx *Tx) Commit() error {
+	if tx.savepoint == "" {
+		return tx.Tx.Commit()
+	}
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	_, err := tx.ExecContext(tx.ctx, "RELEASE SAVEPOINT "+tx.savepoint)
+	return err
+}
+
+// Rollback aborts the transaction, or rolls back to the savepoint
+func (tx *Tx) Rollback() error {
+	if tx.savepoint == "" {
+		return tx.Tx.Rollback()
+	}
+	if tx.done {
+		return sql.ErrTxDone
+	}
+	tx.done = true
+	// The enclosing transaction goes on even when the savepoint's context was cancelled
+	_, err := tx.ExecContext(context.WithoutCancel(tx.ctx), "ROLLBACK TO SAVEPOINT "+tx.savepoint)
+	return err
+}
+
+type txKey struct{}
+
+// UnitOfWork runs several repository operations atomically
+type UnitOfWork struct {
+	connection *sql.DB
+	timeouts   Timeouts
+}
+
+func NewUnitOfWork(connection *sql.DB, timeouts Timeouts) UnitOfWork {
+	return UnitOfWork{
+		connection: connection,
+		timeouts:   timeouts,
+	}
+}
+
+// Do runs fn in a transaction, committed when fn returns nil and rolled back otherwise.
+// Repositories bound to the transaction with WithTx run their statements in it.
+// Called with the context given to fn, Do runs the inner fn in a savepoint, so its failure
+// can be handled without losing the work done so far.
+// A transaction aborted by a serialization failure or a deadlock runs again from the start,
+// so fn must not have effects outside the database
+func (u UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, tx *Tx) error) error {
+	return u.DoWith(ctx, nil, fn)
+}
+
+// DoWith is Do with transaction options such as the isolation level.
+// Nested calls run in the enclosing transaction and ignore opts
+func (u UnitOfWork) DoWith(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context, tx *Tx) error) error {
+
+	if outer, ok := ctx.Value(txKey{}).(*Tx); ok {
+		tx, err := outer.Savepoint(ctx)
+		if err != nil {
+			return err
+		}
+		return runTx(ctx, tx, fn)
+	}
+
+	ctx, cancel := u.timeouts.query(ctx)
+	defer cancel()
+
+	for attempt := 1; ; attempt++ {
+		tx, err := begin(ctx, u.connection, opts)
+		if err != nil {
+			return err
+		}
+
+		err = runTx(ctx, tx, fn)
+		if err == nil || attempt == maxTxAttempts || !isRetryable(err) {
+			return err
+		}
+
+		// Back off with jitter so the conflicting transactions do not collide again
+		backoff := time.Duration(attempt*attempt)*10*time.Millisecond + rand.N(10*time.Millisecond)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func runTx(ctx context.Context, tx *Tx, fn func(ctx context.Context, tx *Tx) error) error {
+	defer tx.Rollback()
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx), tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// isRetryable reports whether PostgreSQL aborted the transaction because of a concurrent one:
+// serialization_failure or deadlock_detected
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
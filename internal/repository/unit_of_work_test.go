@@ -0,0 +1,175 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+)
+
+func newMock(t *testing.T) (*sql.DB, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New(sqlmock.QueryMatcherOption(sqlmock.QueryMatcherEqual))
+	if err != nil {
+		t.Fatalf("failed to create mock database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, mock
+}
+
+func TestUnitOfWorkCommits(t *testing.T) {
+	db, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM lote").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := NewUnitOfWork(db, Timeouts{Query: time.Second}).Do(context.Background(), func(ctx context.Context, tx *Tx) error {
+		_, err := tx.ExecContext(ctx, "DELETE FROM lote")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnitOfWorkRollsBackOnError(t *testing.T) {
+	db, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	failure := errors.New("failure")
+	err := NewUnitOfWork(db, Timeouts{}).Do(context.Background(), func(ctx context.Context, tx *Tx) error {
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("got %v, want %v", err, failure)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnitOfWorkRetriesSerializationFailures(t *testing.T) {
+	db, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectCommit().WillReturnError(&pq.Error{Code: "40P01"})
+	mock.ExpectBegin()
+	mock.ExpectCommit()
+
+	attempts := 0
+	err := NewUnitOfWork(db, Timeouts{}).Do(context.Background(), func(ctx context.Context, tx *Tx) error {
+		attempts++
+		if attempts == 1 {
+			return &pq.Error{Code: "40001"}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if attempts != 3 {
+		t.Errorf("got %d attempts, want 3", attempts)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestUnitOfWorkGivesUpAfterMaxAttempts(t *testing.T) {
+	db, mock := newMock(t)
+	for range maxTxAttempts {
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+	}
+
+	attempts := 0
+	err := NewUnitOfWork(db, Timeouts{}).Do(context.Background(), func(ctx context.Context, tx *Tx) error {
+		attempts++
+		return &pq.Error{Code: "40001"}
+	})
+	if !isRetryable(err) {
+		t.Fatalf("got %v, want the serialization failure", err)
+	}
+	if attempts != maxTxAttempts {
+		t.Errorf("got %d attempts, want %d", attempts, maxTxAttempts)
+	}
+}
+
+func TestUnitOfWorkDoesNotRetryOtherErrors(t *testing.T) {
+	db, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	attempts := 0
+	NewUnitOfWork(db, Timeouts{}).Do(context.Background(), func(ctx context.Context, tx *Tx) error {
+		attempts++
+		return &pq.Error{Code: "23505"}
+	})
+	if attempts != 1 {
+		t.Errorf("got %d attempts, want 1", attempts)
+	}
+}
+
+func TestUnitOfWorkNestsInSavepoints(t *testing.T) {
+	db, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO lote").WillReturnError(errors.New("failure"))
+	mock.ExpectExec("ROLLBACK TO SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_2").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	unitOfWork := NewUnitOfWork(db, Timeouts{})
+	err := unitOfWork.Do(context.Background(), func(ctx context.Context, tx *Tx) error {
+		err := unitOfWork.Do(ctx, func(ctx context.Context, tx *Tx) error {
+			_, err := tx.ExecContext(ctx, "INSERT INTO lote")
+			return err
+		})
+		if err == nil {
+			t.Error("expected the inner error")
+		}
+		return unitOfWork.Do(ctx, func(ctx context.Context, tx *Tx) error {
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRepositoryTransactionBecomesSavepoint(t *testing.T) {
+	db, mock := newMock(t)
+	mock.ExpectBegin()
+	mock.ExpectExec("SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("RELEASE SAVEPOINT sp_1").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	stockRepository := NewStockRepository(db, Timeouts{})
+	err := NewUnitOfWork(db, Timeouts{}).Do(context.Background(), func(ctx context.Context, tx *Tx) error {
+		inner, err := beginTx(ctx, stockRepository.WithTx(tx).connection)
+		if err != nil {
+			return err
+		}
+		defer inner.Rollback()
+		return inner.Commit()
+	})
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
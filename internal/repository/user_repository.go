@@ -1,32 +1,59 @@
 package repository
 
 import (
+	"APIGolang/internal/apperror"
 	"APIGolang/internal/model"
+	"context"
 	"database/sql"
-	"errors"
-	"fmt"
+	"log/slog"
+	"time"
 )
 
+// ErrUserNotFound is returned when no user matches the id or email
+var ErrUserNotFound = apperror.NotFound("user_not_found", "usuário não encontrado")
+
 type UserRepository struct {
-	connection *sql.DB
+	connection DBTX
+	timeouts   Timeouts
+	reader     Reader
+	logger     *slog.Logger
 }
 
-func NewUserRepository(connection *sql.DB) UserRepository {
+func NewUserRepository(connection *sql.DB, timeouts Timeouts, logger *slog.Logger) UserRepository {
 	return UserRepository{
 		connection: connection,
+		timeouts:   timeouts,
+		logger:     logger.With("repository", "user"),
 	}
 }
 
-func (r *UserRepository) GetUserById(id int) (*model.User, error) {
+// WithTx returns a copy of the repository that runs in the unit of work transaction
+func (r *UserRepository) WithTx(tx *Tx) UserRepository {
+	bound := *r
+	bound.connection = tx
+	return bound
+}
+
+// WithReader returns a copy of the repository whose listings and reports run on reader
+func (r *UserRepository) WithReader(reader Reader) UserRepository {
+	bound := *r
+	bound.reader = reader
+	return bound
+}
+
+func (r *UserRepository) GetUserById(ctx context.Context, id int) (*model.User, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
 
 	var user model.User
 
-	query := "SELECT id_usuario, nome, nome_usuario, email, perfil, ativo FROM usuario WHERE id_usuario = $1"
+	query := "SELECT id_usuario, nome, nome_usuario, email, perfil, role, ativo FROM usuario WHERE id_usuario = $1"
 
-	err := r.connection.QueryRow(query, id).Scan(&user.Id, &user.Name, &user.Username, &user.Email, &user.Profile, &user.Active)
+	err := r.connection.QueryRowContext(ctx, query, id).Scan(&user.Id, &user.Name, &user.Username, &user.Email, &user.Profile, &user.Role, &user.Active)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("usuário não encontrado")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -34,16 +61,19 @@ func (r *UserRepository) GetUserById(id int) (*model.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) GetUserByEmail(email string) (*model.User, error) {
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (*model.User, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
 
 	var user model.User
 
 	query := "SELECT id_usuario, nome, nome_usuario, email, senha, perfil, ativo FROM usuario WHERE email = $1"
 
-	err := r.connection.QueryRow(query, email).Scan(&user.Id, &user.Name, &user.Username, &user.Email, &user.Password, &user.Profile, &user.Active)
+	err := r.connection.QueryRowContext(ctx, query, email).Scan(&user.Id, &user.Name, &user.Username, &user.Email, &user.Password, &user.Profile, &user.Active)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("usuário não encontrado")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -51,40 +81,81 @@ func (r *UserRepository) GetUserByEmail(email string) (*model.User, error) {
 	return &user, nil
 }
 
-func (r *UserRepository) GetToken(request_name string) (*model.User, string, error) {
+func (r *UserRepository) GetToken(ctx context.Context, request_name string) (*model.User, string, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
 
 	var user model.User
 	var user_password string
 
-	query := "SELECT id_usuario, nome, nome_usuario, email, senha, perfil, role, ativo FROM usuario WHERE nome_usuario = $1"
+	query := "SELECT id_usuario, nome, nome_usuario, email, senha, perfil, role, ativo, tentativas_login, bloqueado_ate FROM usuario WHERE nome_usuario = $1"
 
-	err := r.connection.QueryRow(query, request_name).Scan(&user.Id, &user.Name, &user.Username, &user.Email, &user_password, &user.Profile, &user.Role, &user.Active)
+	err := r.connection.QueryRowContext(ctx, query, request_name).Scan(&user.Id, &user.Name, &user.Username, &user.Email, &user_password, &user.Profile, &user.Role, &user.Active, &user.LoginFailures, &user.LockedUntil)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, "", errors.New("Ocorreu um erro")
+			return nil, "", ErrUserNotFound
 		}
-		fmt.Println(err)
+		r.logger.ErrorContext(ctx, "query failed", "operation", "GetToken", "error", err)
 		return nil, "", err
 	}
 
 	return &user, user_password, nil
 }
 
-func (r *UserRepository) CreateUser(user model.User) error {
+// RecordLoginFailure counts a wrong password. The maxFailures-th failure locks the login for
+// lockout and starts the count again
+func (r *UserRepository) RecordLoginFailure(ctx context.Context, user_id int, maxFailures int, lockout time.Duration) error {
 
-	query := "INSERT INTO usuario (nome, nome_usuario, email, senha, perfil, role, ativo)" +
-			 " VALUES ($1, $2, $3, $4, $5, $6, $7)"
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	query := "UPDATE usuario SET" +
+		" tentativas_login = CASE WHEN tentativas_login + 1 >= $2 THEN 0 ELSE tentativas_login + 1 END," +
+		" bloqueado_ate = CASE WHEN tentativas_login + 1 >= $2 THEN NOW() + make_interval(secs => $3) ELSE bloqueado_ate END" +
+		" WHERE id_usuario = $1"
+
+	_, err := r.connection.ExecContext(ctx, query, user_id, maxFailures, lockout.Seconds())
+	if err != nil {
+		r.logger.ErrorContext(ctx, "query failed", "operation", "RecordLoginFailure", "error", err)
+	}
+	return err
+}
 
-	_, err := r.connection.Exec(query, user.Name, user.Username, user.Email, user.Password, user.Profile, user.Role, user.Active)
+// ResetLoginFailures clears the failure count and the lockout after a successful login
+func (r *UserRepository) ResetLoginFailures(ctx context.Context, user_id int) error {
 
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	query := "UPDATE usuario SET tentativas_login = 0, bloqueado_ate = NULL WHERE id_usuario = $1"
+
+	_, err := r.connection.ExecContext(ctx, query, user_id)
 	return err
 }
 
-func (r *UserRepository) ChangePassword(email, password string) (bool, error) {
+func (r *UserRepository) CreateUser(ctx context.Context, user model.User) (int, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
+	query := "INSERT INTO usuario (nome, nome_usuario, email, senha, perfil, role, ativo)" +
+			 " VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id_usuario"
+
+	var id int
+	err := r.connection.QueryRowContext(ctx, query, user.Name, user.Username, user.Email, user.Password, user.Profile, user.Role, user.Active).Scan(&id)
+
+	return id, err
+}
+
+func (r *UserRepository) ChangePassword(ctx context.Context, email, password string) (bool, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
 
 	query := "UPDATE usuario SET senha = $1 WHERE email = $2"
-	result, err := r.connection.Exec(query, password, email)
+	result, err := r.connection.ExecContext(ctx, query, password, email)
 	if err != nil {
 		return false, err
 	}
@@ -98,12 +169,15 @@ func (r *UserRepository) ChangePassword(email, password string) (bool, error) {
 
 }
 
-func (r *UserRepository) UserExists(user_username string) (bool, error) {
+func (r *UserRepository) UserExists(ctx context.Context, user_username string) (bool, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
 
 	var count int
 
 	query := "SELECT Count(1) FROM usuario WHERE nome_usuario = $1"
-	err := r.connection.QueryRow(query, user_username).Scan(&count)
+	err := r.connection.QueryRowContext(ctx, query, user_username).Scan(&count)
 
 	if err != nil {
 		return false, err
@@ -111,10 +185,14 @@ func (r *UserRepository) UserExists(user_username string) (bool, error) {
 	return count > 0, nil
 }
 
-func (r *UserRepository) UsernameExistsForOtherUser(username string, user_id int) (bool, error) {
+func (r *UserRepository) UsernameExistsForOtherUser(ctx context.Context, username string, user_id int) (bool, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
 	query := "SELECT 1 FROM usuario WHERE nome_usuario = $1 AND id_usuario <> $2"
 	var exists int
-	err := r.connection.QueryRow(query, username, user_id).Scan(&exists)
+	err := r.connection.QueryRowContext(ctx, query, username, user_id).Scan(&exists)
 
 	if err == sql.ErrNoRows {
 		return false, nil
@@ -125,12 +203,15 @@ func (r *UserRepository) UsernameExistsForOtherUser(username string, user_id int
 	return true, nil
 }
 
-func (r *UserRepository) EmailExists(user_email string) (bool, error) {
+func (r *UserRepository) EmailExists(ctx context.Context, user_email string) (bool, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
 
 	var count int
 
 	query := "SELECT Count(1) FROM usuario WHERE email = $1"
-	err := r.connection.QueryRow(query, user_email).Scan(&count)
+	err := r.connection.QueryRowContext(ctx, query, user_email).Scan(&count)
 
 	if err != nil {
 		return false, err
@@ -138,10 +219,14 @@ func (r *UserRepository) EmailExists(user_email string) (bool, error) {
 	return count > 0, nil
 }
 
-func (r *UserRepository) EmailExistsForOtherUser(email string, user_id int) (bool, error) {
+func (r *UserRepository) EmailExistsForOtherUser(ctx context.Context, email string, user_id int) (bool, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
+
 	query := "SELECT 1 FROM usuario WHERE email = $1 AND id_usuario <> $2"
 	var exists int
-	err := r.connection.QueryRow(query, email, user_id).Scan(&exists)
+	err := r.connection.QueryRowContext(ctx, query, email, user_id).Scan(&exists)
 
 	if err == sql.ErrNoRows {
 		return false, nil
@@ -152,52 +237,69 @@ func (r *UserRepository) EmailExistsForOtherUser(email string, user_id int) (boo
 	return true, nil
 }
 
-func (r *UserRepository) GetAllUsers() ([]model.User, error) {
+func (r *UserRepository) GetAllUsers(ctx context.Context) ([]model.User, error) {
 
 	var users []model.User
 
-	query := "SELECT id_usuario, nome, nome_usuario, email, senha, perfil, role, ativo FROM usuario"
-	rows, err := r.connection.Query(query)
+	err := r.EachUser(ctx, func(user model.User) error {
+		users = append(users, user)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	return users, nil
+}
+
+// EachUser calls fn for every user, reading one row at a time
+// The password hash is never returned
+func (r *UserRepository) EachUser(ctx context.Context, fn func(model.User) error) error {
+
+	ctx, cancel := r.timeouts.report(ctx)
+	defer cancel()
+
+	query := "SELECT id_usuario, nome, nome_usuario, email, perfil, role, ativo FROM usuario ORDER BY id_usuario"
+	rows, err := readConnection(r.connection, r.reader).QueryContext(ctx, query)
+	if err != nil {
+		return err
+	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var user model.User
-		var password string
 
 		err := rows.Scan(
 			&user.Id,
 			&user.Name,
 			&user.Username,
 			&user.Email,
-			&password,
 			&user.Profile,
 			&user.Role,
 			&user.Active,
 		)
 		if err != nil {
-			fmt.Println(err)
-			return nil, err
+			r.logger.ErrorContext(ctx, "query failed", "operation", "EachUser", "error", err)
+			return err
 		}
-		
-		users = append(users, user)
-	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+		if err := fn(user); err != nil {
+			return err
+		}
 	}
-	return users, nil
+
+	return rows.Err()
 }
 
-func (r *UserRepository) DeleteUserById(user_id int) (bool, error) {
+func (r *UserRepository) DeleteUserById(ctx context.Context, user_id int) (bool, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
 
 	query := "DELETE FROM usuario WHERE id_usuario = $1"
 
-	result, err := r.connection.Exec(query, user_id)
+	result, err := r.connection.ExecContext(ctx, query, user_id)
 	if err != nil{
-		fmt.Println(err)
+		r.logger.ErrorContext(ctx, "query failed", "operation", "DeleteUserById", "error", err)
 		return false, err
 	}
 
@@ -213,13 +315,16 @@ func (r *UserRepository) DeleteUserById(user_id int) (bool, error) {
 	return true, nil
 }
 
-func (r *UserRepository) UpdateUserById(user model.UpdateUserRequest, user_id int) (bool, error) {
+func (r *UserRepository) UpdateUserById(ctx context.Context, user model.UpdateUserRequest, user_id int) (bool, error) {
+
+	ctx, cancel := r.timeouts.query(ctx)
+	defer cancel()
 
 	query := "UPDATE usuario SET nome = $1, nome_usuario = $2, email = $3, perfil = $4, role = $5 WHERE id_usuario = $6"
 
-	result, err := r.connection.Exec(query, user.Name, user.Username, user.Email, user.Profile, user.Role, user_id)
+	result, err := r.connection.ExecContext(ctx, query, user.Name, user.Username, user.Email, user.Profile, user.Role, user_id)
 	if err != nil{
-		fmt.Println(err)
+		r.logger.ErrorContext(ctx, "query failed", "operation", "UpdateUserById", "error", err)
 		return false, err
 	}
 
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"APIGolang/internal/auth"
+	"APIGolang/internal/controller"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/middleware"
+	"APIGolang/internal/repository"
+	"APIGolang/internal/usecase"
+	"database/sql"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterAuditRoutes(r *gin.Engine, db *sql.DB, reader repository.Reader, timeouts repository.Timeouts, tokens *auth.Tokens, logger *slog.Logger, m *metrics.Metrics) {
+
+	auditRepository := repository.NewAuditRepository(db, timeouts)
+	auditRepository = auditRepository.WithReader(reader)
+	auditUsecase := usecase.NewAuditUseCase(auditRepository)
+	auditController := controller.NewAuditController(auditUsecase)
+	auditRoutes := r.Group("/audit")
+
+	auditRoutes.Use(middleware.JWTAuth(tokens), middleware.RequireRole("ADM"))
+	{
+		auditRoutes.GET("", auditController.GetAuditEntries)
+	}
+}
@@ -1,22 +1,28 @@
 package routes
 
 import (
+	"APIGolang/internal/auth"
 	"APIGolang/internal/controller"
+	"APIGolang/internal/metrics"
 	"APIGolang/internal/repository"
 	"APIGolang/internal/usecase"
 	"database/sql"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterAuthRoutes(r *gin.Engine, db *sql.DB) {
+func RegisterAuthRoutes(r *gin.Engine, db *sql.DB, timeouts repository.Timeouts, tokens *auth.Tokens, logger *slog.Logger, m *metrics.Metrics) {
 	
-	userRepository := repository.NewUserRepository(db)
+	userRepository := repository.NewUserRepository(db, timeouts, logger)
 
-	authUsecase := usecase.NewAuthUseCase(&userRepository, &userRepository)
-	userUsecase := usecase.NewUserUseCase(&userRepository)
+	auditRepository := repository.NewAuditRepository(db, timeouts)
+	unitOfWork := repository.NewUnitOfWork(db, timeouts)
+
+	authUsecase := usecase.NewAuthUseCase(userRepository, auditRepository, unitOfWork, m)
+	userUsecase := usecase.NewUserUseCase(userRepository, auditRepository, unitOfWork)
 	
-	authController := controller.NewAuthController(authUsecase, userUsecase)
+	authController := controller.NewAuthController(authUsecase, userUsecase, tokens)
 
 	r.POST("/auth/login", authController.Login)
 	r.POST("/auth/refresh", authController.Refresh)
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"APIGolang/internal/auth"
+	"APIGolang/internal/controller"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/middleware"
+	"APIGolang/internal/repository"
+	"APIGolang/internal/usecase"
+	"database/sql"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterCategoryRoutes(r *gin.Engine, db *sql.DB, timeouts repository.Timeouts, tokens *auth.Tokens, logger *slog.Logger, m *metrics.Metrics) {
+
+	ruleRepository := repository.NewPricingRuleRepository(db, timeouts)
+	productRepository := repository.NewProductRepository(db, timeouts, logger)
+	categoryRepository := repository.NewCategoryRepository(db, timeouts)
+	pricingUsecase := usecase.NewPricingUseCase(ruleRepository, productRepository, categoryRepository)
+	pricingController := controller.NewPricingController(pricingUsecase)
+	categoryRoutes := r.Group("/category")
+
+	categoryRoutes.Use(middleware.JWTAuth(tokens))
+	{
+		categoryRoutes.GET("/:id/pricing-rule", pricingController.GetCategoryRule)
+		categoryRoutes.PUT("/:id/pricing-rule", pricingController.SaveCategoryRule)
+	}
+}
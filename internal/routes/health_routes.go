@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"APIGolang/internal/controller"
+	"APIGolang/internal/health"
+	"database/sql"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterHealthRoutes(r *gin.Engine, db *sql.DB, readiness *health.Readiness, timeout time.Duration) {
+
+	checker := health.NewChecker(readiness, timeout)
+	checker.Register("database", health.DatabaseCheck(db))
+	checker.Register("migrations", health.MigrationCheck(db))
+	healthController := controller.NewHealthController(checker)
+
+	r.GET("/healthz", healthController.Liveness)
+	r.GET("/readyz", healthController.Readiness)
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"APIGolang/internal/auth"
+	"APIGolang/internal/controller"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/middleware"
+	"APIGolang/internal/repository"
+	"APIGolang/internal/usecase"
+	"database/sql"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterInventoryRoutes(r *gin.Engine, db *sql.DB, reader repository.Reader, timeouts repository.Timeouts, tokens *auth.Tokens, logger *slog.Logger, m *metrics.Metrics) {
+
+	inventoryRepository := repository.NewInventoryRepository(db, timeouts)
+	inventoryRepository = inventoryRepository.WithReader(reader)
+	productRepository := repository.NewProductRepository(db, timeouts, logger)
+	categoryRepository := repository.NewCategoryRepository(db, timeouts)
+	inventoryUsecase := usecase.NewInventoryUseCase(inventoryRepository, productRepository, categoryRepository, m)
+	inventoryController := controller.NewInventoryController(inventoryUsecase)
+	inventoryRoutes := r.Group("/inventory")
+
+	inventoryRoutes.Use(middleware.JWTAuth(tokens))
+	{
+		inventoryRoutes.GET("", inventoryController.GetSessions)
+		inventoryRoutes.POST("", inventoryController.OpenSession)
+		inventoryRoutes.GET("/:id", inventoryController.GetSession)
+		inventoryRoutes.POST("/:id/counts", inventoryController.SubmitCounts)
+		inventoryRoutes.POST("/:id/close", inventoryController.CloseSession)
+		inventoryRoutes.GET("/:id/report", inventoryController.GetReport)
+	}
+}
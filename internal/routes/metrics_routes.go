@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"APIGolang/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterMetricsRoutes exposes /metrics for Prometheus. It is not authenticated, so keep it
+// reachable only from the internal network
+func RegisterMetricsRoutes(r *gin.Engine, m *metrics.Metrics) {
+	r.GET("/metrics", gin.WrapH(m.Handler()))
+}
@@ -1,28 +1,61 @@
 package routes
 
 import (
+	"APIGolang/internal/auth"
 	"APIGolang/internal/controller"
+	"APIGolang/internal/metrics"
 	"APIGolang/internal/middleware"
 	"APIGolang/internal/repository"
 	"APIGolang/internal/usecase"
 	"database/sql"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterProductRoutes(r *gin.Engine, db *sql.DB) {
+func RegisterProductRoutes(r *gin.Engine, db *sql.DB, reader repository.Reader, timeouts repository.Timeouts, tokens *auth.Tokens, logger *slog.Logger, m *metrics.Metrics) {
 
-	productRepository := repository.NewProductRepository(db)
-	productUsecase := usecase.NewProductUseCase(productRepository)
+	productRepository := repository.NewProductRepository(db, timeouts, logger)
+	productRepository = productRepository.WithReader(reader)
+	auditRepository := repository.NewAuditRepository(db, timeouts)
+	unitOfWork := repository.NewUnitOfWork(db, timeouts)
+	productUsecase := usecase.NewProductUseCase(productRepository, auditRepository, unitOfWork)
 	productController := controller.NewProductController(productUsecase)
+
+	categoryRepository := repository.NewCategoryRepository(db, timeouts)
+	supplierRepository := repository.NewSupplierRepository(db, timeouts)
+	importUsecase := usecase.NewProductImportUseCase(productRepository, categoryRepository, supplierRepository, auditRepository, unitOfWork)
+	importController := controller.NewProductImportController(importUsecase)
+
+	priceRepository := repository.NewPriceRepository(db, timeouts)
+	priceRepository = priceRepository.WithReader(reader)
+	ruleRepository := repository.NewPricingRuleRepository(db, timeouts)
+	priceUsecase := usecase.NewPriceUseCase(priceRepository, productRepository, ruleRepository)
+	priceController := controller.NewPriceController(priceUsecase)
+
+	pricingUsecase := usecase.NewPricingUseCase(ruleRepository, productRepository, categoryRepository)
+	pricingController := controller.NewPricingController(pricingUsecase)
+
+	lotRepository := repository.NewLotRepository(db, timeouts)
+	lotRepository = lotRepository.WithReader(reader)
+	stockRepository := repository.NewStockRepository(db, timeouts)
+	lotUsecase := usecase.NewLotUseCase(lotRepository, stockRepository, productRepository, unitOfWork, m)
+	lotController := controller.NewLotController(lotUsecase)
+
 	productsRoutes := r.Group("/product")
 	
-	productsRoutes.Use(middleware.JWTAuth()) 
+	productsRoutes.Use(middleware.JWTAuth(tokens)) 
 	{
 		productsRoutes.GET("", productController.GetProducts)
 		productsRoutes.GET("/:id", productController.GetProductById)
 		productsRoutes.POST("", productController.CreateProduct)
+		productsRoutes.POST("/import", importController.ImportProducts)
 		productsRoutes.PUT("/:id", productController.UpdateProductById)
 		productsRoutes.DELETE("/:id", productController.DeleteProductById)
+		productsRoutes.GET("/:id/prices", priceController.GetPriceTimeline)
+		productsRoutes.GET("/:id/pricing", pricingController.GetProductPricing)
+		productsRoutes.GET("/:id/lots", lotController.GetProductLots)
+		productsRoutes.POST("/:id/prices/schedule", priceController.SchedulePriceChange)
+		productsRoutes.DELETE("/:id/prices/schedule/:scheduleId", priceController.CancelScheduledChange)
 	}
 }
\ No newline at end of file
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"APIGolang/internal/auth"
+	"APIGolang/internal/controller"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/middleware"
+	"APIGolang/internal/repository"
+	"APIGolang/internal/usecase"
+	"database/sql"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterPromotionRoutes(r *gin.Engine, db *sql.DB, timeouts repository.Timeouts, tokens *auth.Tokens, logger *slog.Logger, m *metrics.Metrics) {
+
+	promotionRepository := repository.NewPromotionRepository(db, timeouts)
+	productRepository := repository.NewProductRepository(db, timeouts, logger)
+	categoryRepository := repository.NewCategoryRepository(db, timeouts)
+	promotionUsecase := usecase.NewPromotionUseCase(promotionRepository, productRepository, categoryRepository)
+	promotionController := controller.NewPromotionController(promotionUsecase)
+	promotionRoutes := r.Group("/promotion")
+
+	promotionRoutes.Use(middleware.JWTAuth(tokens))
+	{
+		promotionRoutes.GET("", promotionController.GetPromotions)
+		promotionRoutes.POST("", promotionController.CreatePromotion)
+		promotionRoutes.DELETE("/:id", promotionController.DeactivatePromotion)
+	}
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"APIGolang/internal/auth"
+	"APIGolang/internal/controller"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/middleware"
+	"APIGolang/internal/repository"
+	"APIGolang/internal/usecase"
+	"database/sql"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterPurchaseRoutes(r *gin.Engine, db *sql.DB, reader repository.Reader, timeouts repository.Timeouts, tokens *auth.Tokens, logger *slog.Logger, m *metrics.Metrics) {
+
+	purchaseRepository := repository.NewPurchaseRepository(db, timeouts)
+	purchaseRepository = purchaseRepository.WithReader(reader)
+	productRepository := repository.NewProductRepository(db, timeouts, logger)
+	supplierRepository := repository.NewSupplierRepository(db, timeouts)
+	purchaseUsecase := usecase.NewPurchaseUseCase(purchaseRepository, productRepository, supplierRepository, m)
+	purchaseController := controller.NewPurchaseController(purchaseUsecase)
+	purchaseRoutes := r.Group("/purchase")
+
+	purchaseRoutes.Use(middleware.JWTAuth(tokens))
+	{
+		purchaseRoutes.GET("/suggestions", purchaseController.GetSuggestions)
+		purchaseRoutes.GET("/order", purchaseController.GetPurchaseOrders)
+		purchaseRoutes.POST("/order", purchaseController.CreatePurchaseOrder)
+		purchaseRoutes.GET("/order/:id", purchaseController.GetPurchaseOrderById)
+		purchaseRoutes.POST("/order/:id/send", purchaseController.SendPurchaseOrder)
+		purchaseRoutes.POST("/order/:id/receive", purchaseController.ReceivePurchaseOrder)
+	}
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"APIGolang/internal/auth"
+	"APIGolang/internal/controller"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/middleware"
+	"APIGolang/internal/repository"
+	"APIGolang/internal/usecase"
+	"database/sql"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterReportRoutes(r *gin.Engine, db *sql.DB, reader repository.Reader, timeouts repository.Timeouts, tokens *auth.Tokens, logger *slog.Logger, m *metrics.Metrics) {
+
+	reportRepository := repository.NewReportRepository(db, timeouts)
+	reportRepository = reportRepository.WithReader(reader)
+	reportUsecase := usecase.NewReportUseCase(reportRepository)
+	reportController := controller.NewReportController(reportUsecase)
+	reportRoutes := r.Group("/report")
+
+	reportRoutes.Use(middleware.JWTAuth(tokens))
+	{
+		reportRoutes.GET("/sales", reportController.GetSalesReport)
+		reportRoutes.GET("/abc", reportController.GetAbcAnalysis)
+		reportRoutes.GET("/idle-products", reportController.GetIdleProducts)
+	}
+}
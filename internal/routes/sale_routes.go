@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"APIGolang/internal/auth"
+	"APIGolang/internal/controller"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/middleware"
+	"APIGolang/internal/repository"
+	"APIGolang/internal/usecase"
+	"database/sql"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterSaleRoutes(r *gin.Engine, db *sql.DB, timeouts repository.Timeouts, tokens *auth.Tokens, logger *slog.Logger, m *metrics.Metrics) {
+
+	saleRepository := repository.NewSaleRepository(db, timeouts)
+	productRepository := repository.NewProductRepository(db, timeouts, logger)
+	promotionRepository := repository.NewPromotionRepository(db, timeouts)
+	ruleRepository := repository.NewPricingRuleRepository(db, timeouts)
+	userRepository := repository.NewUserRepository(db, timeouts, logger)
+	auditRepository := repository.NewAuditRepository(db, timeouts)
+	unitOfWork := repository.NewUnitOfWork(db, timeouts)
+	authUsecase := usecase.NewAuthUseCase(userRepository, auditRepository, unitOfWork, m)
+	saleUsecase := usecase.NewSaleUseCase(saleRepository, productRepository, promotionRepository, ruleRepository, unitOfWork, authUsecase, m)
+	saleController := controller.NewSaleController(saleUsecase)
+	saleRoutes := r.Group("/sale")
+
+	saleRoutes.Use(middleware.JWTAuth(tokens))
+	{
+		saleRoutes.POST("", saleController.CreateSale)
+		saleRoutes.POST("/quote", saleController.QuoteSale)
+	}
+}
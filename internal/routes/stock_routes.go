@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"APIGolang/internal/auth"
+	"APIGolang/internal/controller"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/middleware"
+	"APIGolang/internal/repository"
+	"APIGolang/internal/usecase"
+	"database/sql"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterStockRoutes(r *gin.Engine, db *sql.DB, reader repository.Reader, timeouts repository.Timeouts, tokens *auth.Tokens, logger *slog.Logger, m *metrics.Metrics) {
+
+	stockRepository := repository.NewStockRepository(db, timeouts)
+	stockRepository = stockRepository.WithReader(reader)
+	stockUsecase := usecase.NewStockUseCase(stockRepository)
+	stockController := controller.NewStockController(stockUsecase)
+
+	invoiceRepository := repository.NewInvoiceRepository(db, timeouts)
+	productRepository := repository.NewProductRepository(db, timeouts, logger)
+	categoryRepository := repository.NewCategoryRepository(db, timeouts)
+	supplierRepository := repository.NewSupplierRepository(db, timeouts)
+	ruleRepository := repository.NewPricingRuleRepository(db, timeouts)
+	auditRepository := repository.NewAuditRepository(db, timeouts)
+	unitOfWork := repository.NewUnitOfWork(db, timeouts)
+	invoiceUsecase := usecase.NewInvoiceUseCase(invoiceRepository, productRepository, categoryRepository, supplierRepository, ruleRepository, auditRepository, unitOfWork, m)
+	invoiceController := controller.NewInvoiceController(invoiceUsecase)
+
+	lotRepository := repository.NewLotRepository(db, timeouts)
+	lotRepository = lotRepository.WithReader(reader)
+	lotUsecase := usecase.NewLotUseCase(lotRepository, stockRepository, productRepository, unitOfWork, m)
+	lotController := controller.NewLotController(lotUsecase)
+
+	stockRoutes := r.Group("/stock")
+
+	stockRoutes.Use(middleware.JWTAuth(tokens))
+	{
+		stockRoutes.GET("", stockController.GetStockPositions)
+		stockRoutes.POST("/invoice", invoiceController.ImportInvoice)
+		stockRoutes.POST("/entry", lotController.RegisterEntry)
+		stockRoutes.GET("/lots/expiring", lotController.GetExpiringLots)
+		stockRoutes.POST("/lots/:id/write-off", lotController.WriteOffLot)
+	}
+}
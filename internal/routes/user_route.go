@@ -1,28 +1,34 @@
 package routes
 
 import (
+	"APIGolang/internal/auth"
 	"APIGolang/internal/controller"
+	"APIGolang/internal/metrics"
 	"APIGolang/internal/middleware"
 	"APIGolang/internal/repository"
 	"APIGolang/internal/usecase"
 	"database/sql"
+	"log/slog"
 
 	"github.com/gin-gonic/gin"
 )
 
-func RegisterUserRoutes(r *gin.Engine, db *sql.DB) {
+func RegisterUserRoutes(r *gin.Engine, db *sql.DB, reader repository.Reader, timeouts repository.Timeouts, tokens *auth.Tokens, logger *slog.Logger, m *metrics.Metrics) {
 	
-	userRepository := repository.NewUserRepository(db)
-	userUsecase := usecase.NewUserUseCase(&userRepository)
+	userRepository := repository.NewUserRepository(db, timeouts, logger)
+	userRepository = userRepository.WithReader(reader)
+	auditRepository := repository.NewAuditRepository(db, timeouts)
+	unitOfWork := repository.NewUnitOfWork(db, timeouts)
+	userUsecase := usecase.NewUserUseCase(userRepository, auditRepository, unitOfWork)
 	userController := controller.NewUserController(userUsecase)
 	userRoutes := r.Group("/user")
 
-	userRoutes.Use(middleware.JWTAuth()) 
+	userRoutes.Use(middleware.JWTAuth(tokens)) 
 	{
-		userRoutes.POST("/create", userController.CreateUser)
+		userRoutes.POST("/create", middleware.RequireRole("ADM"), userController.CreateUser)
 		userRoutes.GET("/getAll", userController.GetAllUsers)
-		userRoutes.DELETE("/delete/:id", userController.DeleteUserById)
-		userRoutes.PUT("/update/:id", userController.UpdateUserById)
+		userRoutes.DELETE("/delete/:id", middleware.RequireRole("ADM"), userController.DeleteUserById)
+		userRoutes.PUT("/update/:id", middleware.RequireRole("ADM"), userController.UpdateUserById)
 	}
 }
 
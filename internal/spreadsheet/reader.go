@@ -0,0 +1,109 @@
+package spreadsheet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// Supported spreadsheet formats
+const (
+	FormatCSV  = "csv"
+	FormatXLSX = "xlsx"
+)
+
+// FormatFromFilename detects the spreadsheet format by the file extension
+func FormatFromFilename(filename string) (string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		return FormatCSV, nil
+	case ".xlsx":
+		return FormatXLSX, nil
+	default:
+		return "", fmt.Errorf("formato de arquivo não suportado: %s", filepath.Ext(filename))
+	}
+}
+
+// ReadRows reads every row of the first sheet (XLSX) or of the file (CSV)
+// The first returned row is the header
+func ReadRows(r io.Reader, format string) ([][]string, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatXLSX:
+		return readXLSX(r)
+	default:
+		return nil, fmt.Errorf("formato de arquivo não suportado: %s", format)
+	}
+}
+
+func readCSV(r io.Reader) ([][]string, error) {
+	buffered := bufio.NewReader(r)
+
+	// Skip the UTF-8 BOM written by spreadsheet tools
+	if bom, err := buffered.Peek(3); err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+		buffered.Discard(3)
+	}
+
+	firstLine, err := buffered.Peek(4096)
+	if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+
+	reader := csv.NewReader(buffered)
+	reader.Comma = detectDelimiter(firstLine)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler CSV: %w", err)
+	}
+
+	// Undo escapeFormula, so an exported file imports back as it was
+	for _, row := range rows {
+		for i, cell := range row {
+			if len(cell) > 1 && cell[0] == '\'' && escapeFormula(cell[1:]) == cell {
+				row[i] = cell[1:]
+			}
+		}
+	}
+	return rows, nil
+}
+
+// detectDelimiter picks ';' when it appears more than ',' in the header line,
+// since spreadsheets exported with pt-BR locale use it as separator
+func detectDelimiter(sample []byte) rune {
+	if idx := bytes.IndexByte(sample, '\n'); idx >= 0 {
+		sample = sample[:idx]
+	}
+	if bytes.Count(sample, []byte{';'}) > bytes.Count(sample, []byte{','}) {
+		return ';'
+	}
+	return ','
+}
+
+func readXLSX(r io.Reader) ([][]string, error) {
+	file, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao abrir XLSX: %w", err)
+	}
+	defer file.Close()
+
+	sheets := file.GetSheetList()
+	if len(sheets) == 0 {
+		return nil, fmt.Errorf("o arquivo XLSX não possui planilhas")
+	}
+
+	rows, err := file.GetRows(sheets[0])
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler XLSX: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,202 @@
+package spreadsheet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+)
+
+func TestNegotiateFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		accept  string
+		want    string
+		wantErr bool
+	}{
+		{"default json", "", "", FormatJSON, false},
+		{"query wins over accept", "csv", "application/pdf", FormatCSV, false},
+		{"accept xlsx", "", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", FormatXLSX, false},
+		{"accept with quality", "", "text/html, application/pdf;q=0.9", FormatPDF, false},
+		{"unsupported query", "odt", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NegotiateFormat(tt.query, tt.accept)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NegotiateFormat() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteAndReadRows(t *testing.T) {
+	header := []string{"codigo_produto", "nome", "preco_venda"}
+	rows := [][]string{{"001", "Café Torrado", "12,90"}, {"002", "Pão de Queijo", "8,50"}}
+
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewWriter(&buf, format, "Produtos", header)
+			if err != nil {
+				t.Fatalf("NewWriter: %v", err)
+			}
+			for _, row := range rows {
+				if err := writer.WriteRow(row); err != nil {
+					t.Fatalf("WriteRow: %v", err)
+				}
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			read, err := ReadRows(&buf, format)
+			if err != nil {
+				t.Fatalf("ReadRows: %v", err)
+			}
+			if len(read) != 3 || read[2][1] != "Pão de Queijo" {
+				t.Errorf("unexpected rows: %v", read)
+			}
+		})
+	}
+}
+
+func TestEscapeFormula(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"Café Torrado", "Café Torrado"},
+		{"=HYPERLINK(\"http://x\")", "'=HYPERLINK(\"http://x\")"},
+		{"+55 11 9999", "'+55 11 9999"},
+		{"-2+3", "'-2+3"},
+		{"@SUM(A1)", "'@SUM(A1)"},
+		{"\t=1", "'\t=1"},
+		{"-5", "-5"},
+		{"+1.5", "+1.5"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := escapeFormula(tt.value); got != tt.want {
+			t.Errorf("escapeFormula(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestFormulaCellsRoundTrip(t *testing.T) {
+	row := []string{"=1+1", "-5", "'texto"}
+
+	for _, format := range []string{FormatCSV, FormatXLSX} {
+		t.Run(format, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := NewWriter(&buf, format, "", []string{"a", "b", "c"})
+			if err != nil {
+				t.Fatalf("NewWriter: %v", err)
+			}
+			if err := writer.WriteRow(row); err != nil {
+				t.Fatalf("WriteRow: %v", err)
+			}
+			if err := writer.Close(); err != nil {
+				t.Fatalf("Close: %v", err)
+			}
+
+			// Only CSV needs the escape, XLSX string cells are never evaluated
+			if format == FormatCSV {
+				if written := strings.Split(buf.String(), "\n")[1]; written != "'=1+1,-5,'texto" {
+					t.Errorf("expected only the formula escaped, got %q", written)
+				}
+			} else {
+				file, err := excelize.OpenReader(bytes.NewReader(buf.Bytes()))
+				if err != nil {
+					t.Fatalf("OpenReader: %v", err)
+				}
+				defer file.Close()
+				if value, _ := file.GetCellValue("Sheet1", "A2"); value != "=1+1" {
+					t.Errorf("expected the formula written as typed, got %q", value)
+				}
+				if formula, _ := file.GetCellFormula("Sheet1", "A2"); formula != "" {
+					t.Errorf("expected no formula, got %q", formula)
+				}
+			}
+
+			read, err := ReadRows(&buf, format)
+			if err != nil {
+				t.Fatalf("ReadRows: %v", err)
+			}
+			if len(read) != 2 || read[1][0] != row[0] || read[1][1] != row[1] || read[1][2] != row[2] {
+				t.Errorf("unexpected rows: %q", read)
+			}
+		})
+	}
+}
+
+func TestReadCSV_SemicolonWithBOM(t *testing.T) {
+	input := "\xEF\xBB\xBFcodigo;nome;preco\n001;Arroz;25,90\n"
+
+	rows, err := ReadRows(strings.NewReader(input), FormatCSV)
+	if err != nil {
+		t.Fatalf("ReadRows: %v", err)
+	}
+	if rows[0][0] != "codigo" || rows[1][2] != "25,90" {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+}
+
+func TestPDFWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, FormatPDF, "Posição de estoque", []string{"produto", "estoque"})
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if err := writer.WriteRow([]string{"Feijão", "10"}); err != nil {
+		t.Fatalf("WriteRow: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if !bytes.HasPrefix(buf.Bytes(), []byte("%PDF")) {
+		t.Errorf("output is not a PDF")
+	}
+}
+
+func TestXLSXNumericCells(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, FormatXLSX, "", []string{"a", "b", "c", "d", "e", "f"})
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	if err := writer.WriteRow([]string{"12.90", "-5", "0012", "7891234567890", "+5511999990000", "12,90"}); err != nil {
+		t.Fatalf("WriteRow: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	file, err := excelize.OpenReader(&buf)
+	if err != nil {
+		t.Fatalf("OpenReader: %v", err)
+	}
+	defer file.Close()
+
+	// Cells without a type are numbers
+	wantText := map[string]bool{"A2": false, "B2": false, "C2": true, "D2": true, "E2": true, "F2": true}
+	for cell, text := range wantText {
+		cellType, err := file.GetCellType("Sheet1", cell)
+		if err != nil {
+			t.Fatalf("GetCellType(%s): %v", cell, err)
+		}
+		if got := cellType == excelize.CellTypeInlineString || cellType == excelize.CellTypeSharedString; got != text {
+			t.Errorf("cell %s has type %v, want text %v", cell, cellType, text)
+		}
+	}
+	if value, _ := file.GetCellValue("Sheet1", "E2"); value != "+5511999990000" {
+		t.Errorf("expected the phone number as typed, got %q", value)
+	}
+}
@@ -0,0 +1,279 @@
+package spreadsheet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/xuri/excelize/v2"
+)
+
+// FormatPDF is only available for export, spreadsheets are not imported from PDF
+const FormatPDF = "pdf"
+
+// FormatJSON means the client did not ask for a file export
+const FormatJSON = "json"
+
+var contentTypes = map[string]string{
+	FormatCSV:  "text/csv; charset=utf-8",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatPDF:  "application/pdf",
+	FormatJSON: "application/json",
+}
+
+// ContentType returns the MIME type of the format
+func ContentType(format string) string {
+	return contentTypes[format]
+}
+
+// NegotiateFormat chooses the export format from the ?format= query value,
+// falling back to the Accept header and finally to JSON
+func NegotiateFormat(formatQuery, accept string) (string, error) {
+	if formatQuery != "" {
+		format := strings.ToLower(formatQuery)
+		if _, ok := contentTypes[format]; !ok {
+			return "", fmt.Errorf("formato não suportado: %s", formatQuery)
+		}
+		return format, nil
+	}
+
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		for format, contentType := range contentTypes {
+			if mediaType == strings.SplitN(contentType, ";", 2)[0] {
+				return format, nil
+			}
+		}
+	}
+
+	return FormatJSON, nil
+}
+
+// Writer receives the rows of a report one at a time, so the caller can stream
+// them straight from the database
+type Writer interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+// NewWriter creates a writer for the format and writes the header row
+// The title is only used by formats that render it (PDF and the XLSX sheet name)
+func NewWriter(w io.Writer, format, title string, header []string) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w, header)
+	case FormatXLSX:
+		return newXLSXWriter(w, title, header)
+	case FormatPDF:
+		return newPDFWriter(w, title, header)
+	default:
+		return nil, fmt.Errorf("formato não suportado: %s", format)
+	}
+}
+
+// formulaTriggers are the leading characters that make spreadsheet tools evaluate a CSV cell
+const formulaTriggers = "=+-@\t\r"
+
+// numericPattern matches the values written as XLSX numbers. Codes with leading zeros and
+// barcodes longer than the 11 digits Excel shows keep being text
+var numericPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]{0,10})(\.[0-9]+)?$`)
+
+// escapeFormula prefixes with ' the CSV cells a spreadsheet tool would run as a formula, so a
+// product name like =HYPERLINK(...) is shown as typed. Numbers such as -5 are left alone.
+// XLSX cells are written as strings, which are never evaluated, so they need no escaping
+func escapeFormula(value string) string {
+	if value == "" || !strings.ContainsRune(formulaTriggers, rune(value[0])) {
+		return value
+	}
+	if number, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(number, 0) && !math.IsNaN(number) {
+		return value
+	}
+	return "'" + value
+}
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCSVWriter(w io.Writer, header []string) (*csvWriter, error) {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+	return &csvWriter{writer: writer}, nil
+}
+
+func (c *csvWriter) WriteRow(values []string) error {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = escapeFormula(value)
+	}
+	if err := c.writer.Write(escaped); err != nil {
+		return err
+	}
+	// Flush each row so it reaches the client instead of piling up in memory
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.writer.Flush()
+	return c.writer.Error()
+}
+
+type xlsxWriter struct {
+	out    io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXWriter(w io.Writer, title string, header []string) (*xlsxWriter, error) {
+	file := excelize.NewFile()
+
+	sheet := "Sheet1"
+	if title != "" {
+		sheet = sheetName(title)
+		if err := file.SetSheetName("Sheet1", sheet); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+
+	// The stream writer spills rows to a temporary file, keeping memory bounded
+	stream, err := file.NewStreamWriter(sheet)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	x := &xlsxWriter{out: w, file: file, stream: stream}
+	if err := x.WriteRow(header); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return x, nil
+}
+
+func (x *xlsxWriter) WriteRow(values []string) error {
+	x.row++
+	cell, err := excelize.CoordinatesToCellName(1, x.row)
+	if err != nil {
+		return err
+	}
+
+	// Numbers are written as numeric cells, so the spreadsheet can sum them
+	row := make([]interface{}, len(values))
+	for i, value := range values {
+		row[i] = value
+		if numericPattern.MatchString(value) {
+			if number, err := strconv.ParseFloat(value, 64); err == nil {
+				row[i] = number
+			}
+		}
+	}
+	return x.stream.SetRow(cell, row)
+}
+
+func (x *xlsxWriter) Close() error {
+	defer x.file.Close()
+
+	if err := x.stream.Flush(); err != nil {
+		return err
+	}
+	return x.file.Write(x.out)
+}
+
+// sheetName trims the title to the 31 characters allowed by Excel
+func sheetName(title string) string {
+	runes := []rune(title)
+	if len(runes) > 31 {
+		runes = runes[:31]
+	}
+	return string(runes)
+}
+
+type pdfWriter struct {
+	out       io.Writer
+	pdf       *fpdf.Fpdf
+	header    []string
+	widths    []float64
+	translate func(string) string
+}
+
+const (
+	pdfLineHeight = 6.0
+	pdfMargin     = 10.0
+)
+
+func newPDFWriter(w io.Writer, title string, header []string) (*pdfWriter, error) {
+	pdf := fpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+
+	p := &pdfWriter{
+		out:       w,
+		pdf:       pdf,
+		header:    header,
+		translate: pdf.UnicodeTranslatorFromDescriptor(""),
+	}
+
+	pageWidth, _ := pdf.GetPageSize()
+	columnWidth := (pageWidth - 2*pdfMargin) / float64(len(header))
+	for range header {
+		p.widths = append(p.widths, columnWidth)
+	}
+
+	// Repeat the column titles on every page
+	pdf.SetHeaderFunc(func() {
+		if title != "" {
+			pdf.SetFont("Helvetica", "B", 14)
+			pdf.CellFormat(0, 10, p.translate(title), "", 1, "L", false, 0, "")
+		}
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(230, 230, 230)
+		for i, column := range p.header {
+			pdf.CellFormat(p.widths[i], pdfLineHeight, p.fit(column, p.widths[i]), "1", 0, "L", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	})
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-pdfMargin)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.CellFormat(0, 5, fmt.Sprintf("%d", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+
+	pdf.AddPage()
+	return p, nil
+}
+
+func (p *pdfWriter) WriteRow(values []string) error {
+	for i := range p.widths {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		p.pdf.CellFormat(p.widths[i], pdfLineHeight, p.fit(value, p.widths[i]), "1", 0, "L", false, 0, "")
+	}
+	p.pdf.Ln(-1)
+	return p.pdf.Error()
+}
+
+// fit translates the value to the PDF encoding and truncates it to the column width
+func (p *pdfWriter) fit(value string, width float64) string {
+	text := p.translate(value)
+	for len(text) > 0 && p.pdf.GetStringWidth(text) > width-2 {
+		text = text[:len(text)-1]
+	}
+	return text
+}
+
+func (p *pdfWriter) Close() error {
+	return p.pdf.Output(p.out)
+}
@@ -0,0 +1,73 @@
+package tracing
+
+import (
+	"APIGolang/internal/config"
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+func TestSetup_Console(t *testing.T) {
+	var out bytes.Buffer
+	shutdown, err := Setup(context.Background(), config.Tracing{
+		Exporter:    config.TraceExporterConsole,
+		ServiceName: "mercado-teste",
+		SampleRatio: 0,
+	}, &out)
+	if err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	// A request sampled upstream is kept even with ratio 0 and continues the caller's trace
+	header := http.Header{"Traceparent": {traceparent}}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(header))
+	_, span := otel.Tracer("test").Start(ctx, "POST /sale")
+	if !span.SpanContext().IsSampled() {
+		t.Error("expected the span of a sampled parent to be sampled")
+	}
+	span.End()
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	for _, expected := range []string{`"Name":"POST /sale"`, "4bf92f3577b34da6a3ce929d0e0e4736", "mercado-teste"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("expected %s in the exported spans:\n%s", expected, out.String())
+		}
+	}
+}
+
+func TestSetup_NonePropagates(t *testing.T) {
+	if _, err := Setup(context.Background(), config.Tracing{Exporter: config.TraceExporterNone}, nil); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+
+	propagator := otel.GetTextMapPropagator()
+	ctx := propagator.Extract(context.Background(), propagation.HeaderCarrier(http.Header{"Traceparent": {traceparent}}))
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		t.Fatal("expected the incoming trace context to be extracted")
+	}
+
+	outgoing := http.Header{}
+	propagator.Inject(ctx, propagation.HeaderCarrier(outgoing))
+	if outgoing.Get("traceparent") != traceparent {
+		t.Errorf("expected traceparent %s to be forwarded, got %q", traceparent, outgoing.Get("traceparent"))
+	}
+}
+
+func TestTraced(t *testing.T) {
+	for path, expected := range map[string]bool{"/sale": true, "/product/1": true, "/healthz": false, "/readyz": false, "/metrics": false} {
+		if got := Traced(httptest.NewRequest(http.MethodGet, path, nil)); got != expected {
+			t.Errorf("Traced(%s) = %v, expected %v", path, got, expected)
+		}
+	}
+}
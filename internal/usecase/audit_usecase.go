@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/audit"
+	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"context"
+	"encoding/json"
+	"strconv"
+)
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 500
+)
+
+// AuditRecorder appends entries to the audit log
+type AuditRecorder interface {
+	Record(ctx context.Context, entry model.AuditEntry) error
+}
+
+type AuditUseCase struct {
+	repository repository.AuditRepository
+}
+
+func NewAuditUseCase(repo repository.AuditRepository) AuditUseCase {
+	return AuditUseCase{
+		repository: repo,
+	}
+}
+
+func (au *AuditUseCase) GetEntries(ctx context.Context, filter model.AuditFilter) ([]model.AuditEntry, error) {
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	if filter.Limit < 0 || filter.Limit > maxAuditLimit {
+		return nil, apperror.Validation("invalid_limit", "O limite deve estar entre 1 e %d", maxAuditLimit)
+	}
+	if filter.Offset < 0 {
+		return nil, apperror.Validation("invalid_offset", "O deslocamento não pode ser negativo")
+	}
+	if !filter.To.After(filter.From) {
+		return nil, apperror.Validation("invalid_period", "A data final deve ser posterior à data inicial")
+	}
+
+	return au.repository.GetEntries(ctx, filter)
+}
+
+// recordAudit stores the diff between before and after. It must run in the transaction of the
+// operation, with recorder bound to it, so the change and its entry are committed together
+func recordAudit(ctx context.Context, recorder AuditRecorder, actor model.Actor, entity string, entityId int, action string, before, after any) error {
+	return recordAuditEntry(ctx, recorder, actor, entity, strconv.Itoa(entityId), action, before, after)
+}
+
+// recordAuditEntry is recordAudit for entities not identified by a numeric id
+func recordAuditEntry(ctx context.Context, recorder AuditRecorder, actor model.Actor, entity, entityId, action string, before, after any) error {
+
+	changes, err := audit.Diff(before, after)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+	return recorder.Record(ctx, model.AuditEntry{
+		UserId:    actor.UserId,
+		Username:  actor.Username,
+		IP:        actor.IP,
+		UserAgent: actor.UserAgent,
+		Entity:    entity,
+		EntityId:  entityId,
+		Action:    action,
+		Changes:   data,
+	})
+}
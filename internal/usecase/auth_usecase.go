@@ -1,36 +1,99 @@
 package usecase
 
 import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/metrics"
 	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"context"
 	"errors"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
-type AuthRepository interface {
-	GetToken(request_name string) (*model.User, string, error)
-	ChangePassword(email, password string) (bool, error)
-	EmailExists(user_email string) (bool, error)
-}
+// ErrInvalidCredentials does not tell whether the username or the password is wrong
+var ErrInvalidCredentials = apperror.Unauthorized("invalid_credentials", "credenciais inválidas")
+
+var ErrUserInactive = apperror.Forbidden("user_inactive", "O usuário está inativo")
+
+// ErrLoginLocked is returned while the login is locked by too many wrong passwords, even if the
+// password informed is right
+var ErrLoginLocked = apperror.Forbidden("login_locked", "Login bloqueado por excesso de tentativas, tente novamente mais tarde")
+
+// Wrong passwords accepted before the login is locked, and for how long it stays locked
+const (
+	maxLoginFailures = 5
+	loginLockout     = 15 * time.Minute
+)
 
 type AuthUseCase struct {
-	authRepo AuthRepository
-	userRepo UserRepository
+	userRepo   repository.UserRepository
+	audit      repository.AuditRepository
+	unitOfWork repository.UnitOfWork
+	metrics    *metrics.Metrics
+}
+
+func NewAuthUseCase(userRepo repository.UserRepository, audit repository.AuditRepository, unitOfWork repository.UnitOfWork, m *metrics.Metrics) *AuthUseCase {
+	return &AuthUseCase{userRepo: userRepo, audit: audit, unitOfWork: unitOfWork, metrics: m}
+}
+
+// Login checks the credentials and counts the attempt by result; repository failures are not
+// counted since they say nothing about the credentials
+func (a *AuthUseCase) Login(ctx context.Context, request_name, request_password string) (*model.User, error) {
+
+	user, err := a.login(ctx, request_name, request_password)
+	a.countLogin(err)
+	return user, err
+}
+
+// AuthorizeOverride checks the credentials of the manager authorizing a sale. It goes through the
+// failure count and lockout of the login, and a refused authorization is recorded in the audit log
+// in the name of actor, the operator of the sale
+func (a *AuthUseCase) AuthorizeOverride(ctx context.Context, request_name, request_password string, actor model.Actor) (*model.User, error) {
+
+	manager, err := a.login(ctx, request_name, request_password)
+	a.countLogin(err)
+	if err == nil && manager.Role != "ADM" {
+		err = ErrOverrideNotAllowed
+	}
+
+	var denied *apperror.Error
+	if errors.As(err, &denied) && denied.Kind != apperror.KindInternal {
+		recordErr := recordAuditEntry(ctx, &a.audit, actor, model.AuditEntitySaleOverride, request_name,
+			model.AuditActionDenied, nil, map[string]any{"reason": denied.Code})
+		if recordErr != nil {
+			return nil, recordErr
+		}
+	}
+	return manager, err
 }
 
-func NewAuthUseCase(authRepo AuthRepository, userRepo UserRepository) *AuthUseCase {
-	return &AuthUseCase{authRepo: authRepo, userRepo: userRepo}
+func (a *AuthUseCase) countLogin(err error) {
+	switch {
+	case err == nil:
+		a.metrics.Login(metrics.LoginSuccess)
+	case errors.Is(err, ErrInvalidCredentials):
+		a.metrics.Login(metrics.LoginInvalidCredentials)
+	case errors.Is(err, ErrUserInactive):
+		a.metrics.Login(metrics.LoginUserInactive)
+	case errors.Is(err, ErrLoginLocked):
+		a.metrics.Login(metrics.LoginLocked)
+	}
 }
 
-func (a *AuthUseCase) Login(request_name, request_password string) (*model.User, error) {
-	
-	user, user_password, err := a.authRepo.GetToken(request_name)
+func (a *AuthUseCase) login(ctx context.Context, request_name, request_password string) (*model.User, error) {
+
+	user, user_password, err := a.userRepo.GetToken(ctx, request_name)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	if !user.Active {
-		return nil, errors.New("O usuário está inativo")
+	if user.LockedUntil != nil && time.Now().Before(*user.LockedUntil) {
+		return nil, ErrLoginLocked
 	}
 
 	err = bcrypt.CompareHashAndPassword(
@@ -39,26 +102,49 @@ func (a *AuthUseCase) Login(request_name, request_password string) (*model.User,
 	)
 
 	if err != nil {
-		return nil, errors.New("Senha inválida")
+		if err := a.userRepo.RecordLoginFailure(ctx, user.Id, maxLoginFailures, loginLockout); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidCredentials
+	}
+
+	if user.LoginFailures > 0 || user.LockedUntil != nil {
+		if err := a.userRepo.ResetLoginFailures(ctx, user.Id); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only reported after the password matches, so it does not reveal which usernames exist
+	if !user.Active {
+		return nil, ErrUserInactive
 	}
 
 	return user, nil
 }
 
-func (a *AuthUseCase) ChangePassword(user_request model.ChangePassword) (bool, error) {
+// ChangePassword requires the current password, as the route is also used without a session.
+// Like the login, it does not tell whether the email or the password is wrong. Without a session
+// the audit entry is recorded in the name of the user whose password changed
+func (a *AuthUseCase) ChangePassword(ctx context.Context, user_request model.ChangePassword, actor model.Actor) (bool, error) {
 
-	emailExist, err := a.authRepo.EmailExists(user_request.Email)
+	user, err := a.userRepo.GetUserByEmail(ctx, user_request.Email)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return false, ErrInvalidCredentials
+	}
 	if err != nil {
 		return false, err
 	}
-	if !emailExist {
-		return false, errors.New("Esse email não existe")
+
+	if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(user_request.CurrentPassword)) != nil {
+		return false, ErrInvalidCredentials
+	}
+	if !user.Active {
+		return false, ErrUserInactive
 	}
 
-	// user, err := a.userRepo.GetUserByEmail(user_request.Email)
-	// if err != nil {
-	// 	return false, errors.New("Esse email não está cadastrado")
-	// }
+	if actor.UserId == nil {
+		actor.UserId, actor.Username = &user.Id, &user.Username
+	}
 
 	hash, err := bcrypt.GenerateFromPassword(
 		[]byte(user_request.NewPassword),
@@ -68,5 +154,17 @@ func (a *AuthUseCase) ChangePassword(user_request model.ChangePassword) (bool, e
 		return false, err
 	}
 
-	return a.authRepo.ChangePassword(user_request.Email, string(hash))
+	var isUpdated bool
+	err = a.unitOfWork.Do(ctx, func(ctx context.Context, tx *repository.Tx) error {
+		users, audit := a.userRepo.WithTx(tx), a.audit.WithTx(tx)
+		var err error
+		isUpdated, err = users.ChangePassword(ctx, user_request.Email, string(hash))
+		if err != nil || !isUpdated {
+			return err
+		}
+		// Both values are redacted by the diff, the entry only records that the password changed
+		return recordAudit(ctx, &audit, actor, model.AuditEntityUser, user.Id, model.AuditActionUpdate,
+			map[string]any{"password": user.Password}, map[string]any{"password": string(hash)})
+	})
+	return isUpdated, err
 }
\ No newline at end of file
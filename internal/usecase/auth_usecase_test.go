@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"bytes"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestChangePassword(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("atual1234"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword: %v", err)
+	}
+	userRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id_usuario", "nome", "nome_usuario", "email", "senha", "perfil", "ativo"}).
+			AddRow(4, "Maria", "maria", "maria@mercado.com", string(hash), "ADM", true)
+	}
+
+	tests := []struct {
+		name        string
+		current     string
+		expect      func(mock sqlmock.Sqlmock)
+		wantErr     error
+		wantUpdated bool
+	}{
+		{
+			name:    "unknown email",
+			current: "atual1234",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM usuario WHERE email").WithArgs("maria@mercado.com").
+					WillReturnRows(sqlmock.NewRows([]string{"id_usuario"}))
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "wrong current password",
+			current: "errada1234",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM usuario WHERE email").WithArgs("maria@mercado.com").WillReturnRows(userRow())
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:    "current password matches",
+			current: "atual1234",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM usuario WHERE email").WithArgs("maria@mercado.com").WillReturnRows(userRow())
+				mock.ExpectBegin()
+				mock.ExpectExec("UPDATE usuario SET senha").WithArgs(sqlmock.AnyArg(), "maria@mercado.com").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec("INSERT INTO auditoria").WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+			wantUpdated: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock database: %v", err)
+			}
+			defer db.Close()
+			tt.expect(mock)
+
+			users := repository.NewUserRepository(db, repository.Timeouts{}, slog.Default())
+			audit := repository.NewAuditRepository(db, repository.Timeouts{})
+			uc := NewAuthUseCase(users, audit, repository.NewUnitOfWork(db, repository.Timeouts{}), metrics.New())
+
+			updated, err := uc.ChangePassword(context.Background(), model.ChangePassword{
+				Email: "maria@mercado.com", CurrentPassword: tt.current, NewPassword: "nova12345",
+			}, model.Actor{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if updated != tt.wantUpdated {
+				t.Errorf("got updated %v, want %v", updated, tt.wantUpdated)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("atual1234"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword: %v", err)
+	}
+	userRow := func(failures int, lockedUntil any) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id_usuario", "nome", "nome_usuario", "email", "senha", "perfil", "role", "ativo", "tentativas_login", "bloqueado_ate"}).
+			AddRow(4, "Maria", "maria", "maria@mercado.com", string(hash), model.ProfileAdmin, "ADM", true, failures, lockedUntil)
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		expect   func(mock sqlmock.Sqlmock)
+		wantErr  error
+	}{
+		{
+			name:     "locked login refuses the right password",
+			password: "atual1234",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM usuario WHERE nome_usuario").WithArgs("maria").
+					WillReturnRows(userRow(0, time.Now().Add(time.Minute)))
+			},
+			wantErr: ErrLoginLocked,
+		},
+		{
+			name:     "wrong password is counted",
+			password: "errada1234",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM usuario WHERE nome_usuario").WithArgs("maria").WillReturnRows(userRow(2, nil))
+				mock.ExpectExec("UPDATE usuario SET tentativas_login").
+					WithArgs(4, maxLoginFailures, loginLockout.Seconds()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:     "success after an expired lockout clears it",
+			password: "atual1234",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM usuario WHERE nome_usuario").WithArgs("maria").
+					WillReturnRows(userRow(0, time.Now().Add(-time.Minute)))
+				mock.ExpectExec("UPDATE usuario SET tentativas_login = 0, bloqueado_ate = NULL").WithArgs(4).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:     "success without failures writes nothing",
+			password: "atual1234",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM usuario WHERE nome_usuario").WithArgs("maria").WillReturnRows(userRow(0, nil))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock database: %v", err)
+			}
+			defer db.Close()
+			tt.expect(mock)
+
+			users := repository.NewUserRepository(db, repository.Timeouts{}, slog.Default())
+			audit := repository.NewAuditRepository(db, repository.Timeouts{})
+			uc := NewAuthUseCase(users, audit, repository.NewUnitOfWork(db, repository.Timeouts{}), metrics.New())
+
+			_, err = uc.Login(context.Background(), "maria", tt.password)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+// containsArg matches a []byte argument holding the text, such as a field of the audit changes
+type containsArg string
+
+func (c containsArg) Match(value driver.Value) bool {
+	data, ok := value.([]byte)
+	return ok && bytes.Contains(data, []byte(c))
+}
+
+func TestAuthorizeOverride(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("gerente123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("GenerateFromPassword: %v", err)
+	}
+	userRow := func(role string) *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id_usuario", "nome", "nome_usuario", "email", "senha", "perfil", "role", "ativo", "tentativas_login", "bloqueado_ate"}).
+			AddRow(9, "Joana", "joana", "joana@mercado.com", string(hash), model.ProfileOperator, role, true, 0, nil)
+	}
+	expectDenied := func(mock sqlmock.Sqlmock, reason string) {
+		mock.ExpectExec("INSERT INTO auditoria").
+			WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
+				model.AuditEntitySaleOverride, "joana", model.AuditActionDenied, containsArg(reason)).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+
+	tests := []struct {
+		name     string
+		password string
+		expect   func(mock sqlmock.Sqlmock)
+		wantErr  error
+	}{
+		{
+			name:     "unknown manager is recorded",
+			password: "gerente123",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM usuario WHERE nome_usuario").WithArgs("joana").
+					WillReturnRows(sqlmock.NewRows([]string{"id_usuario"}))
+				expectDenied(mock, "invalid_credentials")
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:     "wrong password is counted and recorded",
+			password: "errada1234",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM usuario WHERE nome_usuario").WithArgs("joana").WillReturnRows(userRow("ADM"))
+				mock.ExpectExec("UPDATE usuario SET tentativas_login").WillReturnResult(sqlmock.NewResult(0, 1))
+				expectDenied(mock, "invalid_credentials")
+			},
+			wantErr: ErrInvalidCredentials,
+		},
+		{
+			name:     "user without the ADM role is recorded",
+			password: "gerente123",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM usuario WHERE nome_usuario").WithArgs("joana").WillReturnRows(userRow("NO-ROLE"))
+				expectDenied(mock, "override_not_allowed")
+			},
+			wantErr: ErrOverrideNotAllowed,
+		},
+		{
+			name:     "manager authorizes",
+			password: "gerente123",
+			expect: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery("FROM usuario WHERE nome_usuario").WithArgs("joana").WillReturnRows(userRow("ADM"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock database: %v", err)
+			}
+			defer db.Close()
+			tt.expect(mock)
+
+			users := repository.NewUserRepository(db, repository.Timeouts{}, slog.Default())
+			audit := repository.NewAuditRepository(db, repository.Timeouts{})
+			uc := NewAuthUseCase(users, audit, repository.NewUnitOfWork(db, repository.Timeouts{}), metrics.New())
+
+			operatorId := 3
+			_, err = uc.AuthorizeOverride(context.Background(), "joana", tt.password, model.Actor{UserId: &operatorId})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
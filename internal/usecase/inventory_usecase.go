@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/model"
+	"APIGolang/internal/promotion"
+	"APIGolang/internal/repository"
+	"context"
+	"strings"
+)
+
+var ErrInventoryNotFound = apperror.NotFound("inventory_not_found", "Inventário não encontrado")
+
+// ErrInventoryOverlap is returned when an open session already covers the requested scope
+var ErrInventoryOverlap = apperror.Conflict("inventory_overlap", "Já existe um inventário aberto que abrange esses produtos")
+
+type InventoryUseCase struct {
+	inventoryRepo repository.InventoryRepository
+	productRepo   repository.ProductRepository
+	categoryRepo  repository.CategoryRepository
+	metrics       *metrics.Metrics
+}
+
+func NewInventoryUseCase(inventoryRepo repository.InventoryRepository, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, m *metrics.Metrics) InventoryUseCase {
+	return InventoryUseCase{
+		inventoryRepo: inventoryRepo,
+		productRepo:   productRepo,
+		categoryRepo:  categoryRepo,
+		metrics:       m,
+	}
+}
+
+// OpenSession starts a count of every product or of one category, freezing the current stock
+func (iu *InventoryUseCase) OpenSession(ctx context.Context, request model.InventorySessionRequest, userId *int) (*model.InventorySession, error) {
+
+	if request.CategoryId != nil {
+		category, err := iu.categoryRepo.GetCategoryById(ctx, *request.CategoryId)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			return nil, ErrCategoryNotFound
+		}
+	}
+
+	open, err := iu.inventoryRepo.GetOpenSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range open {
+		if session.CategoryId == nil || request.CategoryId == nil || *session.CategoryId == *request.CategoryId {
+			return nil, ErrInventoryOverlap
+		}
+	}
+
+	id, err := iu.inventoryRepo.OpenSession(ctx, model.InventorySession{
+		CategoryId:  request.CategoryId,
+		Observation: request.Observation,
+		OpenedBy:    userId,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return iu.inventoryRepo.GetSessionById(ctx, id)
+}
+
+func (iu *InventoryUseCase) GetSessions(ctx context.Context, status *string) ([]model.InventorySession, error) {
+	return iu.inventoryRepo.GetSessions(ctx, status)
+}
+
+func (iu *InventoryUseCase) GetSession(ctx context.Context, id int) (*model.InventorySession, error) {
+
+	session, err := iu.inventoryRepo.GetSessionById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, ErrInventoryNotFound
+	}
+	return session, nil
+}
+
+// SubmitCounts records the quantities counted by one device. Products may be informed by id
+// or barcode; a product repeated in the same submission has its quantities summed
+func (iu *InventoryUseCase) SubmitCounts(ctx context.Context, id int, request model.InventoryCountRequest, userId *int) (*model.InventorySession, error) {
+
+	if _, err := iu.GetSession(ctx, id); err != nil {
+		return nil, err
+	}
+
+	device := strings.TrimSpace(request.Device)
+	if device == "" || len([]rune(device)) > 50 {
+		return nil, apperror.Validation("invalid_device", "Informe o dispositivo com até 50 caracteres")
+	}
+	if len(request.Items) == 0 {
+		return nil, apperror.Validation("items_required", "Informe ao menos um item")
+	}
+
+	merged := []model.InventoryCountItem{}
+	index := make(map[int]int)
+
+	for _, item := range request.Items {
+		if item.Quantity < 0 {
+			return nil, apperror.Validation("invalid_counted_quantity", "A quantidade contada não pode ser negativa")
+		}
+
+		if item.ProductId == nil {
+			if item.Barcode == nil {
+				return nil, apperror.Validation("product_reference_required", "Informe o id ou o código de barras do produto")
+			}
+			product, err := iu.productRepo.GetProductByBarcode(ctx, *item.Barcode)
+			if err != nil {
+				return nil, err
+			}
+			if product == nil {
+				return nil, apperror.NotFound("barcode_not_found", "Código de barras %s não encontrado", *item.Barcode)
+			}
+			item.ProductId = &product.Id
+		}
+
+		if i, ok := index[*item.ProductId]; ok {
+			merged[i].Quantity += item.Quantity
+			continue
+		}
+		index[*item.ProductId] = len(merged)
+		merged = append(merged, item)
+	}
+
+	if err := iu.inventoryRepo.SaveCounts(ctx, id, device, merged, userId); err != nil {
+		return nil, err
+	}
+	return iu.inventoryRepo.GetSessionById(ctx, id)
+}
+
+// CloseSession posts the stock adjustments and returns the final variance report
+func (iu *InventoryUseCase) CloseSession(ctx context.Context, id int, request model.InventoryCloseRequest, userId *int) (*model.InventoryReport, error) {
+
+	if _, err := iu.GetSession(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := iu.inventoryRepo.CloseSession(ctx, id, request.ZeroUncounted, userId); err != nil {
+		return nil, err
+	}
+
+	report, err := iu.GetReport(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	// The report variances are the adjustments just posted
+	for _, item := range report.Items {
+		switch {
+		case item.Variance == nil || *item.Variance == 0:
+		case *item.Variance > 0:
+			iu.metrics.StockMoved(model.StockMovementAdjustmentIn, metrics.SourceInventory, *item.Variance)
+		default:
+			iu.metrics.StockMoved(model.StockMovementAdjustmentOut, metrics.SourceInventory, -*item.Variance)
+		}
+	}
+	return report, nil
+}
+
+// GetReport compares the counted quantities with the frozen stock and values the variance by preco_custo
+func (iu *InventoryUseCase) GetReport(ctx context.Context, id int) (*model.InventoryReport, error) {
+
+	session, err := iu.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := iu.inventoryRepo.GetReportItems(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.InventoryReport{Session: *session}
+
+	for i := range items {
+		item := &items[i]
+		if item.CountedQuantity == nil {
+			continue
+		}
+
+		variance := *item.CountedQuantity - item.FrozenStock
+		value := promotion.Round(float64(variance) * item.UnitCost)
+		item.Variance = &variance
+		item.VarianceValue = &value
+
+		report.CountedItems++
+		if variance != 0 {
+			report.ItemsWithVariance++
+		}
+		if value > 0 {
+			report.SurplusValue += value
+		} else {
+			report.ShortageValue -= value
+		}
+	}
+
+	report.SurplusValue = promotion.Round(report.SurplusValue)
+	report.ShortageValue = promotion.Round(report.ShortageValue)
+	report.NetVarianceValue = promotion.Round(report.SurplusValue - report.ShortageValue)
+	report.Items = items
+
+	return report, nil
+}
@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"context"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func newInventoryMock(t *testing.T) (InventoryUseCase, sqlmock.Sqlmock) {
+	t.Helper()
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	uc := NewInventoryUseCase(repository.NewInventoryRepository(db, repository.Timeouts{}),
+		repository.NewProductRepository(db, repository.Timeouts{}, slog.Default()),
+		repository.NewCategoryRepository(db, repository.Timeouts{}), metrics.New())
+	return uc, mock
+}
+
+func expectSession(mock sqlmock.Sqlmock, status string) {
+	mock.ExpectQuery("FROM inventario i WHERE i.id_inventario").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id_inventario", "categoria_id", "status", "observacao", "usuario_abertura",
+			"usuario_fechamento", "data_abertura", "data_fechamento", "produtos", "contados"}).
+			AddRow(1, nil, status, nil, nil, nil, time.Now(), nil, 4, 3))
+}
+
+func expectReportItems(mock sqlmock.Sqlmock, items ...[]driver.Value) {
+	rows := sqlmock.NewRows([]string{"id_produto", "codigo_produto", "nome", "categoria", "estoque_congelado",
+		"custo_unitario", "contado"})
+	for _, item := range items {
+		rows.AddRow(append([]driver.Value{item[0], "P", "Produto", "Mercearia"}, item[1:]...)...)
+	}
+	mock.ExpectQuery("FROM inventario_item ii").WithArgs(1).WillReturnRows(rows)
+}
+
+func TestGetReportVariance(t *testing.T) {
+	uc, mock := newInventoryMock(t)
+	expectSession(mock, model.InventoryStatusOpen)
+	// product, frozen stock, unit cost, counted
+	expectReportItems(mock,
+		[]driver.Value{1, 10, 2.5, 7},
+		[]driver.Value{2, 4, 1.1, 6},
+		[]driver.Value{3, 5, 3.0, nil},
+		[]driver.Value{4, 3, 9.9, 3},
+	)
+
+	report, err := uc.GetReport(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetReport: %v", err)
+	}
+
+	wantVariance := map[int]int{1: -3, 2: 2, 4: 0}
+	wantValue := map[int]float64{1: -7.5, 2: 2.2, 4: 0}
+	for _, item := range report.Items {
+		variance, counted := wantVariance[item.ProductId]
+		if !counted {
+			if item.Variance != nil || item.VarianceValue != nil {
+				t.Errorf("product %d was not counted but has a variance", item.ProductId)
+			}
+			continue
+		}
+		if item.Variance == nil || *item.Variance != variance || *item.VarianceValue != wantValue[item.ProductId] {
+			t.Errorf("product %d: got %v / %v, want %d / %v", item.ProductId, item.Variance, item.VarianceValue,
+				variance, wantValue[item.ProductId])
+		}
+	}
+
+	if report.CountedItems != 3 || report.ItemsWithVariance != 2 {
+		t.Errorf("got %d counted and %d with variance, want 3 and 2", report.CountedItems, report.ItemsWithVariance)
+	}
+	if report.SurplusValue != 2.2 || report.ShortageValue != 7.5 || report.NetVarianceValue != -5.3 {
+		t.Errorf("got surplus %v, shortage %v, net %v", report.SurplusValue, report.ShortageValue, report.NetVarianceValue)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSubmitCountsMergesRepeatedProducts(t *testing.T) {
+	uc, mock := newInventoryMock(t)
+	expectSession(mock, model.InventoryStatusOpen)
+	mock.ExpectQuery("FROM produto WHERE codigo_barras").WithArgs("789").
+		WillReturnRows(sqlmock.NewRows([]string{"id_produto", "codigo_produto", "codigo_barras", "nome", "descricao",
+			"categoria_id", "fornecedor_id", "preco_custo", "preco_venda", "unidade_medida", "estoque_atual",
+			"estoque_minimo", "controla_estoque", "controla_lote", "ativo"}).
+			AddRow(7, "CAF-1", "789", "Café", nil, 3, nil, 8.0, 9.0, "UN", 10, 0, true, false, true))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT status FROM inventario").WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.InventoryStatusOpen))
+	// The product informed by id and by barcode is stored once, with the quantities summed
+	mock.ExpectExec("INSERT INTO inventario_contagem").WithArgs(1, 7, "coletor-1", 5, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO inventario_contagem").WithArgs(1, 8, "coletor-1", 2, nil).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	expectSession(mock, model.InventoryStatusOpen)
+
+	productId, otherId, barcode := 7, 8, "789"
+	_, err := uc.SubmitCounts(context.Background(), 1, model.InventoryCountRequest{
+		Device: " coletor-1 ",
+		Items: []model.InventoryCountItem{
+			{ProductId: &productId, Quantity: 3},
+			{ProductId: &otherId, Quantity: 2},
+			{Barcode: &barcode, Quantity: 2},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("SubmitCounts: %v", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCountsOfSeveralDevicesAreSummed(t *testing.T) {
+	uc, mock := newInventoryMock(t)
+
+	// Each device keeps its own count of the product
+	productId := 7
+	for _, device := range []string{"coletor-1", "coletor-2"} {
+		expectSession(mock, model.InventoryStatusOpen)
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM inventario").WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.InventoryStatusOpen))
+		mock.ExpectExec("ON CONFLICT \\(inventario_id, produto_id, dispositivo\\)").WithArgs(1, 7, device, 4, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+		expectSession(mock, model.InventoryStatusOpen)
+
+		_, err := uc.SubmitCounts(context.Background(), 1, model.InventoryCountRequest{
+			Device: device,
+			Items:  []model.InventoryCountItem{{ProductId: &productId, Quantity: 4}},
+		}, nil)
+		if err != nil {
+			t.Fatalf("SubmitCounts %s: %v", device, err)
+		}
+	}
+
+	// The report reads the sum of the devices
+	expectSession(mock, model.InventoryStatusOpen)
+	expectReportItems(mock, []driver.Value{7, 10, 2.0, 8})
+
+	report, err := uc.GetReport(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("GetReport: %v", err)
+	}
+	if item := report.Items[0]; *item.CountedQuantity != 8 || *item.Variance != -2 || *item.VarianceValue != -4 {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCloseSessionZeroUncounted(t *testing.T) {
+	tests := []struct {
+		name          string
+		zeroUncounted bool
+	}{
+		{name: "uncounted products are kept", zeroUncounted: false},
+		{name: "uncounted products are zeroed", zeroUncounted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc, mock := newInventoryMock(t)
+			expectSession(mock, model.InventoryStatusOpen)
+			mock.ExpectBegin()
+			mock.ExpectQuery("SELECT status FROM inventario").WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.InventoryStatusOpen))
+			// Product 1 was not counted, product 2 has 1 unit more than frozen
+			mock.ExpectQuery("SELECT ii.produto_id, ii.estoque_congelado, c.contado").WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"produto_id", "estoque_congelado", "contado"}).
+					AddRow(1, 4, nil).AddRow(2, 2, 3))
+
+			if tt.zeroUncounted {
+				mock.ExpectExec("UPDATE inventario_item SET quantidade_contada").WithArgs(0, 1, 1).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectQuery("UPDATE produto SET estoque_atual = estoque_atual \\+ \\$1").WithArgs(-4, 1).
+					WillReturnRows(sqlmock.NewRows([]string{"controla_lote"}).AddRow(false))
+				mock.ExpectExec("INSERT INTO movimentacao_estoque").
+					WithArgs(1, model.StockMovementAdjustmentOut, 4, "Inventário 1", nil, 1, nil).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+			mock.ExpectExec("UPDATE inventario_item SET quantidade_contada").WithArgs(3, 1, 2).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectQuery("UPDATE produto SET estoque_atual = estoque_atual \\+ \\$1").WithArgs(1, 2).
+				WillReturnRows(sqlmock.NewRows([]string{"controla_lote"}).AddRow(false))
+			mock.ExpectExec("INSERT INTO movimentacao_estoque").
+				WithArgs(2, model.StockMovementAdjustmentIn, 1, "Inventário 1", nil, 1, nil).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("UPDATE inventario SET status").WithArgs(model.InventoryStatusClosed, nil, 1).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectCommit()
+
+			expectSession(mock, model.InventoryStatusClosed)
+			if tt.zeroUncounted {
+				expectReportItems(mock, []driver.Value{1, 4, 1.5, 0}, []driver.Value{2, 2, 2.0, 3})
+			} else {
+				expectReportItems(mock, []driver.Value{1, 4, 1.5, nil}, []driver.Value{2, 2, 2.0, 3})
+			}
+
+			report, err := uc.CloseSession(context.Background(), 1, model.InventoryCloseRequest{ZeroUncounted: tt.zeroUncounted}, nil)
+			if err != nil {
+				t.Fatalf("CloseSession: %v", err)
+			}
+
+			wantShortage := 0.0
+			if tt.zeroUncounted {
+				wantShortage = 6
+			}
+			if report.ShortageValue != wantShortage || report.SurplusValue != 2 {
+				t.Errorf("got shortage %v and surplus %v, want %v and 2", report.ShortageValue, report.SurplusValue, wantShortage)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestClosedSessionRejectsCountsAndClosing(t *testing.T) {
+	productId := 7
+
+	t.Run("submit counts", func(t *testing.T) {
+		uc, mock := newInventoryMock(t)
+		expectSession(mock, model.InventoryStatusClosed)
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM inventario").WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.InventoryStatusClosed))
+		mock.ExpectRollback()
+
+		_, err := uc.SubmitCounts(context.Background(), 1, model.InventoryCountRequest{
+			Device: "coletor-1",
+			Items:  []model.InventoryCountItem{{ProductId: &productId, Quantity: 1}},
+		}, nil)
+		if !errors.Is(err, repository.ErrInventoryClosed) {
+			t.Fatalf("got %v, want ErrInventoryClosed", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("close session", func(t *testing.T) {
+		uc, mock := newInventoryMock(t)
+		expectSession(mock, model.InventoryStatusClosed)
+		mock.ExpectBegin()
+		mock.ExpectQuery("SELECT status FROM inventario").WithArgs(1).
+			WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(model.InventoryStatusClosed))
+		mock.ExpectRollback()
+
+		_, err := uc.CloseSession(context.Background(), 1, model.InventoryCloseRequest{}, nil)
+		if !errors.Is(err, repository.ErrInventoryClosed) {
+			t.Fatalf("got %v, want ErrInventoryClosed", err)
+		}
+		if err := mock.ExpectationsWereMet(); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("unknown session", func(t *testing.T) {
+		uc, mock := newInventoryMock(t)
+		mock.ExpectQuery("FROM inventario i WHERE i.id_inventario").WithArgs(1).
+			WillReturnRows(sqlmock.NewRows(nil))
+
+		_, err := uc.CloseSession(context.Background(), 1, model.InventoryCloseRequest{}, nil)
+		if !errors.Is(err, ErrInventoryNotFound) {
+			t.Fatalf("got %v, want ErrInventoryNotFound", err)
+		}
+	})
+}
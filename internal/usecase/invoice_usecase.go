@@ -0,0 +1,407 @@
+package usecase
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/model"
+	"APIGolang/internal/nfe"
+	"APIGolang/internal/repository"
+	"APIGolang/internal/validation"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strings"
+)
+
+// ErrInvoiceItemsPending is returned when some item can't be registered yet: it has no
+// matching product and no category was informed to create it, or its quantity is invalid
+var ErrInvoiceItemsPending = apperror.Validation("invoice_items_pending", "Existem itens da NF-e pendentes de associação")
+
+type InvoiceUseCase struct {
+	invoiceRepo  repository.InvoiceRepository
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	supplierRepo repository.SupplierRepository
+	ruleRepo     repository.PricingRuleRepository
+	audit        repository.AuditRepository
+	unitOfWork   repository.UnitOfWork
+	metrics      *metrics.Metrics
+}
+
+func NewInvoiceUseCase(invoiceRepo repository.InvoiceRepository, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, supplierRepo repository.SupplierRepository, ruleRepo repository.PricingRuleRepository, audit repository.AuditRepository, unitOfWork repository.UnitOfWork, m *metrics.Metrics) InvoiceUseCase {
+	return InvoiceUseCase{
+		invoiceRepo:  invoiceRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		supplierRepo: supplierRepo,
+		ruleRepo:     ruleRepo,
+		audit:        audit,
+		unitOfWork:   unitOfWork,
+		metrics:      m,
+	}
+}
+
+// Import parses the NF-e and matches every item to a product, first by GTIN and then by the
+// supplier's product code. Unmatched items get a proposed product; they are only created when
+// categoryId is informed. Items whose cost changed get the sale price suggested by the category
+// rule. The created products are audited in the transaction of the invoice. With dryRun the
+// matching is returned without writing anything
+func (uc *InvoiceUseCase) Import(ctx context.Context, file io.Reader, dryRun bool, categoryId *int, actor model.Actor) (*model.InvoiceImportResult, error) {
+
+	parsed, err := nfe.Parse(file)
+	if err != nil {
+		return nil, apperror.Validation("invalid_invoice", "XML da NF-e inválido: %s", err.Error())
+	}
+
+	exists, err := uc.invoiceRepo.AccessKeyExists(ctx, parsed.AccessKey)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, repository.ErrDuplicateInvoice
+	}
+
+	supplier, err := uc.supplierRepo.GetSupplierByCnpj(ctx, parsed.EmitterCnpj)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, apperror.NotFound("supplier_not_found", "Fornecedor com CNPJ %s (%s) não está cadastrado", parsed.EmitterCnpj, parsed.EmitterName)
+	}
+
+	if categoryId != nil {
+		category, err := uc.categoryRepo.GetCategoryById(ctx, *categoryId)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			return nil, ErrCategoryNotFound
+		}
+	}
+
+	result := &model.InvoiceImportResult{
+		DryRun: dryRun,
+		Invoice: model.Invoice{
+			AccessKey:    parsed.AccessKey,
+			Number:       parsed.Number,
+			Series:       parsed.Series,
+			IssuedAt:     parsed.IssuedAt,
+			SupplierId:   supplier.Id,
+			SupplierName: supplier.Name,
+			TotalValue:   parsed.TotalValue,
+		},
+	}
+
+	rules, err := uc.ruleRepo.GetRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	proposals := newProposals()
+	pending := false
+	for _, parsedItem := range parsed.Items {
+		item, err := uc.matchItem(ctx, parsedItem, supplier.Id, categoryId, rules, proposals)
+		if err != nil {
+			return nil, err
+		}
+		if item.ProductId == nil {
+			result.UnmatchedItems++
+		}
+		if item.Error != "" {
+			pending = true
+		}
+		result.Invoice.Items = append(result.Invoice.Items, item)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+	if pending {
+		return result, ErrInvoiceItemsPending
+	}
+
+	var invoiceId, created int
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context, tx *repository.Tx) error {
+		// RegisterInvoice sets the product of the items, a retry starts again from the matching
+		invoice := result.Invoice
+		invoice.Items = slices.Clone(result.Invoice.Items)
+
+		invoiceRepo, audit := uc.invoiceRepo.WithTx(tx), uc.audit.WithTx(tx)
+		var err error
+		invoiceId, created, err = invoiceRepo.RegisterInvoice(ctx, &invoice, actor.UserId)
+		if err != nil {
+			return err
+		}
+
+		audited := map[*model.Product]bool{}
+		for _, item := range invoice.Items {
+			if item.ProposedProduct == nil || audited[item.ProposedProduct] {
+				continue
+			}
+			audited[item.ProposedProduct] = true
+			err = recordAudit(ctx, &audit, actor, model.AuditEntityProduct, item.ProposedProduct.Id,
+				model.AuditActionCreate, nil, item.ProposedProduct)
+			if err != nil {
+				return err
+			}
+		}
+
+		result.Invoice = invoice
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result.Invoice.Id = invoiceId
+	result.CreatedProducts = created
+	result.StockEntries = len(result.Invoice.Items)
+	for _, item := range result.Invoice.Items {
+		uc.metrics.StockMoved(model.StockMovementIn, metrics.SourceInvoice, int(item.Quantity))
+	}
+	return result, nil
+}
+
+func (uc *InvoiceUseCase) matchItem(ctx context.Context, parsed nfe.Item, supplierId int, categoryId *int, rules map[int]model.PricingRule, proposals *proposals) (model.InvoiceItem, error) {
+
+	quantity, unit, unitCost, factor := stockQuantity(parsed)
+	item := model.InvoiceItem{
+		ItemNumber:   parsed.Number,
+		SupplierCode: parsed.SupplierCode,
+		Description:  parsed.Description,
+		Unit:         unit,
+		Quantity:     quantity,
+		UnitCost:     unitCost,
+		TotalCost:    parsed.TotalCost,
+	}
+
+	for _, lot := range parsed.Lots {
+		manufacturedAt, expiresAt := lot.ManufacturedAt, lot.ExpiresAt
+		item.Lots = append(item.Lots, model.LotEntry{
+			Number:         truncate(lot.Number, 30),
+			Quantity:       int(math.Round(lot.Quantity * factor)),
+			ManufacturedAt: &manufacturedAt,
+			ExpiresAt:      &expiresAt,
+		})
+	}
+
+	// estoque_atual is an integer, fractional quantities must be converted before importing
+	if item.Quantity <= 0 || item.Quantity != math.Trunc(item.Quantity) {
+		holdBack(&item, "invalid_quantity", "quantidade %v não é um número inteiro positivo", item.Quantity)
+	}
+
+	// Items converted to the taxable unit are matched by the GTIN of the unit, not of the box
+	barcode := parsed.Barcode
+	if factor != 1 {
+		barcode = parsed.TaxBarcode
+	}
+	if barcode != "" && validation.ValidGTIN(barcode) {
+		item.Barcode = &barcode
+
+		product, err := uc.productRepo.GetProductByBarcode(ctx, barcode)
+		if err != nil {
+			return item, err
+		}
+		if product != nil {
+			item.ProductId = &product.Id
+			item.MatchedBy = "codigo_barras"
+			item.SuggestedPrice = suggestForCostChange(product, item.UnitCost, rules)
+			checkUnit(&item, product)
+			checkLots(&item, product)
+			return item, nil
+		}
+	}
+
+	productId, err := uc.invoiceRepo.GetProductIdBySupplierCode(ctx, supplierId, parsed.SupplierCode)
+	if err != nil {
+		return item, err
+	}
+	if productId != nil {
+		product, err := uc.productRepo.GetProductById(ctx, *productId)
+		if err != nil {
+			return item, err
+		}
+		item.ProductId = productId
+		item.MatchedBy = "codigo_fornecedor"
+		if product != nil {
+			item.SuggestedPrice = suggestForCostChange(product, item.UnitCost, rules)
+			checkUnit(&item, product)
+			checkLots(&item, product)
+		}
+		return item, nil
+	}
+
+	var rule *model.PricingRule
+	if categoryId != nil {
+		if categoryRule, ok := rules[*categoryId]; ok {
+			rule = &categoryRule
+		}
+	}
+
+	item.ProposedProduct = proposals.find(item)
+	if item.ProposedProduct == nil {
+		product := proposeProduct(item, supplierId, categoryId, suggestedPrice(item.UnitCost, rule))
+		code, err := uc.freeProductCode(ctx, *product.Code, proposals)
+		if err != nil {
+			return item, err
+		}
+		product.Code = &code
+		proposals.add(item, product)
+		item.ProposedProduct = product
+	}
+	if categoryId == nil && item.Error == "" {
+		holdBack(&item, "category_required", "produto não encontrado, informe a categoria para cadastrá-lo")
+	}
+
+	return item, nil
+}
+
+// stockQuantity expresses the item in the unit the stock is kept in. Items bought by the box also
+// come in the taxable unit, 2 CX as 24 UN, and are stocked by it at the cost of a single unit.
+// factor converts the commercial quantities, such as those of the lots
+func stockQuantity(parsed nfe.Item) (quantity float64, unit string, unitCost float64, factor float64) {
+	if parsed.TaxUnit == "" || parsed.TaxUnit == parsed.Unit || parsed.TaxQuantity <= 0 || parsed.Quantity <= 0 {
+		return parsed.Quantity, parsed.Unit, parsed.UnitCost, 1
+	}
+	return parsed.TaxQuantity, parsed.TaxUnit, parsed.TotalCost / parsed.TaxQuantity, parsed.TaxQuantity / parsed.Quantity
+}
+
+// checkUnit holds back items whose unit is not the one the product is stocked in, as their
+// quantity would be added to the stock as it is
+func checkUnit(item *model.InvoiceItem, product *model.Product) {
+	unit := "UN"
+	if product.Unit != nil && *product.Unit != "" {
+		unit = strings.ToUpper(*product.Unit)
+	}
+	if item.Unit != unit && item.Error == "" {
+		holdBack(item, "unit_mismatch", "unidade %s difere da unidade %s do produto", item.Unit, unit)
+	}
+}
+
+// checkLots holds back items of products that control lots whose lots do not add up to the
+// quantity, such as items without <rastro>, as the units would enter the stock without a lot
+func checkLots(item *model.InvoiceItem, product *model.Product) {
+	if product.ControlsLots == nil || !*product.ControlsLots || item.Error != "" {
+		return
+	}
+	total := 0
+	for _, lot := range item.Lots {
+		total += lot.Quantity
+	}
+	if float64(total) != item.Quantity {
+		holdBack(item, "lots_mismatch", "o produto controla lotes e os lotes somam %d de %v unidades", total, item.Quantity)
+	}
+}
+
+// holdBack marks the item as pending, message is a catalog key translated by the controller
+func holdBack(item *model.InvoiceItem, code, message string, args ...any) {
+	item.ErrorCode, item.Error, item.ErrorArgs = code, message, args
+}
+
+// proposals keeps the products proposed for an invoice, so that lines of the same product,
+// by barcode or supplier code, share one and no two get the same code
+type proposals struct {
+	byBarcode      map[string]*model.Product
+	bySupplierCode map[string]*model.Product
+	codes          map[string]bool
+}
+
+func newProposals() *proposals {
+	return &proposals{
+		byBarcode:      map[string]*model.Product{},
+		bySupplierCode: map[string]*model.Product{},
+		codes:          map[string]bool{},
+	}
+}
+
+func (p *proposals) find(item model.InvoiceItem) *model.Product {
+	if item.Barcode != nil {
+		if product, ok := p.byBarcode[*item.Barcode]; ok {
+			return product
+		}
+	}
+	return p.bySupplierCode[item.SupplierCode]
+}
+
+func (p *proposals) add(item model.InvoiceItem, product *model.Product) {
+	if item.Barcode != nil {
+		p.byBarcode[*item.Barcode] = product
+	}
+	p.bySupplierCode[item.SupplierCode] = product
+	p.codes[*product.Code] = true
+}
+
+// freeProductCode returns code, or code with a -2, -3... suffix when a product or another
+// proposal of the invoice already has it
+func (uc *InvoiceUseCase) freeProductCode(ctx context.Context, code string, proposals *proposals) (string, error) {
+
+	candidate := code
+	for n := 2; ; n++ {
+		if !proposals.codes[candidate] {
+			product, err := uc.productRepo.GetProductByCode(ctx, candidate)
+			if err != nil {
+				return "", err
+			}
+			if product == nil {
+				return candidate, nil
+			}
+		}
+		suffix := fmt.Sprintf("-%d", n)
+		candidate = truncate(code, 30-len(suffix)) + suffix
+	}
+}
+
+// suggestForCostChange returns the price suggested by the product category rule, or nil
+// when the cost did not change or the category has no target markup
+func suggestForCostChange(product *model.Product, newCost float64, rules map[int]model.PricingRule) *float64 {
+
+	if product.CostPrice != nil && *product.CostPrice == newCost {
+		return nil
+	}
+	rule, ok := rules[*product.CategoryId]
+	if !ok {
+		return nil
+	}
+	return suggestedPrice(newCost, &rule)
+}
+
+// proposeProduct builds the product that will be created for an unmatched item
+// The sale price is the one suggested by the category rule; without a target markup
+// it starts equal to the cost and must be reviewed before selling
+func proposeProduct(item model.InvoiceItem, supplierId int, categoryId *int, suggested *float64) *model.Product {
+
+	code := fmt.Sprintf("F%d-%s", supplierId, item.SupplierCode)
+	if item.Barcode != nil {
+		code = *item.Barcode
+	}
+	code = truncate(code, 30)
+
+	name := truncate(item.Description, 100)
+	unit := truncate(item.Unit, 10)
+	cost := item.UnitCost
+	price := item.UnitCost
+	if suggested != nil {
+		price = *suggested
+	}
+
+	return &model.Product{
+		Code:       &code,
+		Barcode:    item.Barcode,
+		Name:       &name,
+		CategoryId: categoryId,
+		SupplierId: &supplierId,
+		CostPrice:  &cost,
+		Price:      &price,
+		Unit:       &unit,
+	}
+}
+
+func truncate(value string, size int) string {
+	runes := []rune(value)
+	if len(runes) > size {
+		return string(runes[:size])
+	}
+	return value
+}
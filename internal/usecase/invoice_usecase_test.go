@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"APIGolang/internal/i18n"
+	"APIGolang/internal/model"
+	"APIGolang/internal/nfe"
+	"APIGolang/internal/repository"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestStockQuantity(t *testing.T) {
+	tests := []struct {
+		name         string
+		item         nfe.Item
+		wantQuantity float64
+		wantUnit     string
+		wantCost     float64
+		wantFactor   float64
+	}{
+		{
+			name:         "same unit",
+			item:         nfe.Item{Unit: "UN", Quantity: 24, UnitCost: 2.5, TotalCost: 60, TaxUnit: "UN", TaxQuantity: 24},
+			wantQuantity: 24, wantUnit: "UN", wantCost: 2.5, wantFactor: 1,
+		},
+		{
+			name:         "boxes stocked by unit",
+			item:         nfe.Item{Unit: "CX", Quantity: 2, UnitCost: 30, TotalCost: 60, TaxUnit: "UN", TaxQuantity: 24},
+			wantQuantity: 24, wantUnit: "UN", wantCost: 2.5, wantFactor: 12,
+		},
+		{
+			name:         "without qTrib",
+			item:         nfe.Item{Unit: "CX", Quantity: 2, UnitCost: 30, TotalCost: 60, TaxUnit: "UN"},
+			wantQuantity: 2, wantUnit: "CX", wantCost: 30, wantFactor: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			quantity, unit, cost, factor := stockQuantity(tt.item)
+			if quantity != tt.wantQuantity || unit != tt.wantUnit || cost != tt.wantCost || factor != tt.wantFactor {
+				t.Errorf("got %v %s at %v (factor %v), want %v %s at %v (factor %v)",
+					quantity, unit, cost, factor, tt.wantQuantity, tt.wantUnit, tt.wantCost, tt.wantFactor)
+			}
+		})
+	}
+}
+
+func TestCheckUnit(t *testing.T) {
+	box, empty := "cx", ""
+	tests := []struct {
+		name        string
+		itemUnit    string
+		productUnit *string
+		wantError   bool
+	}{
+		{"same unit", "CX", &box, false},
+		{"different unit", "UN", &box, true},
+		{"product without unit is UN", "UN", nil, false},
+		{"empty product unit is UN", "KG", &empty, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := model.InvoiceItem{Unit: tt.itemUnit}
+			checkUnit(&item, &model.Product{Unit: tt.productUnit})
+			if got := item.Error != ""; got != tt.wantError {
+				t.Errorf("got error %q, want error %v", item.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestCheckLots(t *testing.T) {
+	controls, free := true, false
+	tests := []struct {
+		name      string
+		lots      []model.LotEntry
+		controls  *bool
+		wantError bool
+	}{
+		{"lots add up to the quantity", []model.LotEntry{{Quantity: 10}, {Quantity: 14}}, &controls, false},
+		{"lots short of the quantity", []model.LotEntry{{Quantity: 10}}, &controls, true},
+		{"no rastro", nil, &controls, true},
+		{"product without lot control", nil, &free, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := model.InvoiceItem{Quantity: 24, Lots: tt.lots}
+			checkLots(&item, &model.Product{ControlsLots: tt.controls})
+			if got := item.Error != ""; got != tt.wantError {
+				t.Errorf("got error %q, want error %v", item.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestPendingItemsAreTranslated(t *testing.T) {
+	controls := true
+	item := model.InvoiceItem{Quantity: 24, Lots: []model.LotEntry{{Quantity: 10}}}
+	checkLots(&item, &model.Product{ControlsLots: &controls})
+
+	if item.ErrorCode != "lots_mismatch" {
+		t.Errorf("got code %q, want lots_mismatch", item.ErrorCode)
+	}
+	want := "the product controls lots and the lots add up to 10 of 24 units"
+	if got := i18n.T(i18n.EnUS, item.Error, item.ErrorArgs...); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestMatchItemByTheUnitGTIN(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	// 2 boxes of 12 with two lots, the product is stocked by unit and controls lots
+	parsed := nfe.Item{Number: 1, SupplierCode: "A-100", Barcode: "17891000315504", TaxBarcode: "7891000315507",
+		Unit: "CX", Quantity: 2, UnitCost: 30, TotalCost: 60, TaxUnit: "UN", TaxQuantity: 24,
+		Lots: []nfe.Lot{{Number: "L1", Quantity: 1.5}, {Number: "L2", Quantity: 0.5}}}
+
+	mock.ExpectQuery("FROM produto WHERE codigo_barras").WithArgs("7891000315507").
+		WillReturnRows(sqlmock.NewRows([]string{"id_produto", "codigo_produto", "codigo_barras", "nome", "descricao",
+			"categoria_id", "fornecedor_id", "preco_custo", "preco_venda", "unidade_medida", "estoque_atual",
+			"estoque_minimo", "controla_estoque", "controla_lote", "ativo"}).
+			AddRow(7, "CAF-1", "7891000315507", "Café", nil, 3, nil, 2.5, 4.0, "UN", 10, 0, true, true, true))
+
+	uc := InvoiceUseCase{productRepo: repository.NewProductRepository(db, repository.Timeouts{}, slog.Default())}
+	item, err := uc.matchItem(context.Background(), parsed, 1, nil, map[int]model.PricingRule{}, newProposals())
+	if err != nil {
+		t.Fatalf("matchItem: %v", err)
+	}
+
+	if item.ProductId == nil || *item.ProductId != 7 || item.Quantity != 24 || item.Error != "" {
+		t.Errorf("unexpected item: %+v", item)
+	}
+	if len(item.Lots) != 2 || item.Lots[0].Quantity != 18 || item.Lots[1].Quantity != 6 {
+		t.Errorf("unexpected lots: %+v", item.Lots)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestProposalsShareTheProductOfTheSameItem(t *testing.T) {
+	barcode := "7891000315507"
+	first := model.InvoiceItem{ItemNumber: 1, SupplierCode: "A-100", Barcode: &barcode}
+	code := "7891000315507"
+	product := &model.Product{Code: &code}
+
+	proposals := newProposals()
+	proposals.add(first, product)
+
+	tests := []struct {
+		name string
+		item model.InvoiceItem
+		want *model.Product
+	}{
+		{"same barcode", model.InvoiceItem{ItemNumber: 2, SupplierCode: "A-101", Barcode: &barcode}, product},
+		{"same supplier code", model.InvoiceItem{ItemNumber: 3, SupplierCode: "A-100"}, product},
+		{"another product", model.InvoiceItem{ItemNumber: 4, SupplierCode: "B-200"}, nil},
+	}
+	for _, tt := range tests {
+		if got := proposals.find(tt.item); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !proposals.codes[code] {
+		t.Error("expected the proposed code to be taken")
+	}
+}
+
+func TestFreeProductCodeSkipsTakenCodes(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	long := strings.Repeat("9", 30)
+	proposals := newProposals()
+	proposals.codes["F1-A"] = true
+	proposals.codes[long] = true
+
+	uc := InvoiceUseCase{productRepo: repository.NewProductRepository(db, repository.Timeouts{}, slog.Default())}
+	tests := []struct {
+		code string
+		want string
+	}{
+		{"F1-A", "F1-A-2"},
+		{long, strings.Repeat("9", 28) + "-2"},
+	}
+	for _, tt := range tests {
+		mock.ExpectQuery("FROM produto WHERE codigo_produto").WithArgs(tt.want).WillReturnRows(sqlmock.NewRows(nil))
+		got, err := uc.freeProductCode(context.Background(), tt.code, proposals)
+		if err != nil {
+			t.Fatalf("freeProductCode: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("got %s, want %s", got, tt.want)
+		}
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
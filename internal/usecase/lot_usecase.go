@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"context"
+	"strings"
+	"time"
+)
+
+var ErrLotNotFound = apperror.NotFound("lot_not_found", "Lote não encontrado")
+
+type LotUseCase struct {
+	lotRepo     repository.LotRepository
+	stockRepo   repository.StockRepository
+	productRepo repository.ProductRepository
+	unitOfWork  repository.UnitOfWork
+	metrics     *metrics.Metrics
+}
+
+func NewLotUseCase(lotRepo repository.LotRepository, stockRepo repository.StockRepository, productRepo repository.ProductRepository, unitOfWork repository.UnitOfWork, m *metrics.Metrics) LotUseCase {
+	return LotUseCase{
+		lotRepo:     lotRepo,
+		stockRepo:   stockRepo,
+		productRepo: productRepo,
+		unitOfWork:  unitOfWork,
+		metrics:     m,
+	}
+}
+
+// RegisterEntry adds units to the stock. Products that control lots require the lot,
+// whose quantity defaults to the entry quantity
+func (lu *LotUseCase) RegisterEntry(ctx context.Context, entry model.StockEntryRequest, userId *int) error {
+
+	if entry.Quantity <= 0 {
+		return apperror.Validation("invalid_quantity", "A quantidade deve ser maior que zero")
+	}
+	if entry.UnitCost != nil && *entry.UnitCost < 0 {
+		return apperror.Validation("invalid_cost", "O custo não pode ser negativo")
+	}
+
+	err := lu.unitOfWork.Do(ctx, func(ctx context.Context, tx *repository.Tx) error {
+		return lu.withTx(tx).registerEntry(ctx, entry, userId)
+	})
+	if err != nil {
+		return err
+	}
+	lu.metrics.StockMoved(model.StockMovementIn, metrics.SourceEntry, entry.Quantity)
+	return nil
+}
+
+// withTx returns a copy of the usecase whose repositories run in tx
+func (lu *LotUseCase) withTx(tx *repository.Tx) *LotUseCase {
+	bound := *lu
+	bound.lotRepo = lu.lotRepo.WithTx(tx)
+	bound.stockRepo = lu.stockRepo.WithTx(tx)
+	bound.productRepo = lu.productRepo.WithTx(tx)
+	return &bound
+}
+
+// registerEntry checks the entry against the product settings read in the same transaction
+func (lu *LotUseCase) registerEntry(ctx context.Context, entry model.StockEntryRequest, userId *int) error {
+
+	product, err := lu.productRepo.GetProductById(ctx, entry.ProductId)
+	if err != nil {
+		return err
+	}
+	if product == nil {
+		return ErrProductNotFound
+	}
+	if product.ControlsStock != nil && !*product.ControlsStock {
+		return apperror.Validation("product_without_stock_control", "O produto não controla estoque")
+	}
+
+	controlsLots := product.ControlsLots != nil && *product.ControlsLots
+	switch {
+	case controlsLots && entry.Lot == nil:
+		return apperror.Validation("lot_required", "O produto controla lotes, informe o lote da entrada")
+	case !controlsLots && entry.Lot != nil:
+		return apperror.Validation("product_without_lot_control", "O produto não controla lotes")
+	}
+
+	if entry.Lot != nil {
+		entry.Lot.Number = strings.TrimSpace(entry.Lot.Number)
+		if entry.Lot.Number == "" || len([]rune(entry.Lot.Number)) > 30 {
+			return apperror.Validation("invalid_lot_number", "Informe o número do lote com até 30 caracteres")
+		}
+		if entry.Lot.Quantity == 0 {
+			entry.Lot.Quantity = entry.Quantity
+		}
+		if entry.Lot.Quantity != entry.Quantity {
+			return apperror.Validation("lot_quantity_mismatch", "A quantidade do lote deve ser igual à quantidade da entrada")
+		}
+		if entry.Lot.ManufacturedAt != nil && entry.Lot.ExpiresAt != nil && entry.Lot.ExpiresAt.Before(*entry.Lot.ManufacturedAt) {
+			return apperror.Validation("invalid_expiry_date", "A validade não pode ser anterior à fabricação")
+		}
+	}
+
+	return lu.stockRepo.RegisterEntry(ctx, entry, userId)
+}
+
+func (lu *LotUseCase) GetProductLots(ctx context.Context, productId int) ([]model.Lot, error) {
+
+	product, err := lu.productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+	return lu.lotRepo.GetLotsByProduct(ctx, productId)
+}
+
+// GetExpiringLots lists the lots with units left expiring in the next days, the expired ones included
+func (lu *LotUseCase) GetExpiringLots(ctx context.Context, days int, now time.Time) ([]model.Lot, error) {
+
+	if days < 0 {
+		return nil, apperror.Validation("invalid_days", "A quantidade de dias não pode ser negativa")
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return lu.lotRepo.GetExpiringLots(ctx, today.AddDate(0, 0, days))
+}
+
+// WriteOff removes expired or damaged units of a lot from the stock
+func (lu *LotUseCase) WriteOff(ctx context.Context, lotId int, request model.LotWriteOffRequest, userId *int) (*model.Lot, error) {
+
+	if request.Quantity <= 0 {
+		return nil, apperror.Validation("invalid_quantity", "A quantidade deve ser maior que zero")
+	}
+	reason := strings.TrimSpace(request.Reason)
+	if reason == "" {
+		return nil, apperror.Validation("write_off_reason_required", "Informe o motivo da baixa")
+	}
+
+	// The lot is returned as left by the write-off, not by a later movement
+	var lot *model.Lot
+	err := lu.unitOfWork.Do(ctx, func(ctx context.Context, tx *repository.Tx) error {
+		lotRepo := lu.lotRepo.WithTx(tx)
+
+		found, err := lotRepo.GetLotById(ctx, lotId)
+		if err != nil {
+			return err
+		}
+		if found == nil {
+			return ErrLotNotFound
+		}
+
+		if err := lotRepo.WriteOff(ctx, lotId, request.Quantity, reason, userId); err != nil {
+			return err
+		}
+		lot, err = lotRepo.GetLotById(ctx, lotId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	lu.metrics.StockMoved(model.StockMovementOut, metrics.SourceWriteOff, request.Quantity)
+	return lot, nil
+}
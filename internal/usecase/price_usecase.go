@@ -0,0 +1,126 @@
+package usecase
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/pricing"
+	"APIGolang/internal/repository"
+	"context"
+	"time"
+)
+
+var ErrProductNotFound = apperror.NotFound("product_not_found", "Produto não foi encontrado na base de dados")
+
+// ErrScheduledBelowMargin is returned when a scheduled price would sell below cost or below the
+// category minimum margin
+var ErrScheduledBelowMargin = apperror.Validation("below_minimum_margin", "Preço agendado abaixo da margem mínima")
+
+type PriceUseCase struct {
+	priceRepo   repository.PriceRepository
+	productRepo repository.ProductRepository
+	ruleRepo    repository.PricingRuleRepository
+}
+
+func NewPriceUseCase(priceRepo repository.PriceRepository, productRepo repository.ProductRepository, ruleRepo repository.PricingRuleRepository) PriceUseCase {
+	return PriceUseCase{
+		priceRepo:   priceRepo,
+		productRepo: productRepo,
+		ruleRepo:    ruleRepo,
+	}
+}
+
+// GetPriceTimeline returns the current prices, every past change and the scheduled ones
+func (pu *PriceUseCase) GetPriceTimeline(ctx context.Context, productId int) (*model.PriceTimeline, error) {
+
+	product, err := pu.productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	history, err := pu.priceRepo.GetPriceHistory(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduled, err := pu.priceRepo.GetScheduledChanges(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.PriceTimeline{
+		ProductId:        productId,
+		CurrentSalePrice: *product.Price,
+		CurrentCostPrice: *product.CostPrice,
+		History:          history,
+		Scheduled:        scheduled,
+	}, nil
+}
+
+func (pu *PriceUseCase) SchedulePriceChange(ctx context.Context, productId int, request model.SchedulePriceRequest, userId *int) (*model.ScheduledPriceChange, error) {
+
+	if request.SalePrice <= 0 {
+		return nil, apperror.Validation("invalid_price", "O preço de venda deve ser maior que zero")
+	}
+	if request.CostPrice != nil && *request.CostPrice < 0 {
+		return nil, apperror.Validation("invalid_cost", "O preço de custo não pode ser negativo")
+	}
+	if !request.EffectiveAt.After(time.Now()) {
+		return nil, apperror.Validation("invalid_effective_date", "A data de vigência deve estar no futuro")
+	}
+
+	product, err := pu.productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	// Checked again when the change is applied, as the cost may change in between
+	rule, err := pu.ruleRepo.GetRuleByCategory(ctx, *product.CategoryId)
+	if err != nil {
+		return nil, err
+	}
+	var minimumMargin *float64
+	if rule != nil {
+		minimumMargin = rule.MinimumMargin
+	}
+	cost := *product.CostPrice
+	if request.CostPrice != nil {
+		cost = *request.CostPrice
+	}
+	if pricing.BelowMinimumMargin(cost, request.SalePrice, minimumMargin) {
+		return nil, ErrScheduledBelowMargin
+	}
+
+	change := model.ScheduledPriceChange{
+		ProductId:   productId,
+		SalePrice:   request.SalePrice,
+		CostPrice:   request.CostPrice,
+		EffectiveAt: request.EffectiveAt,
+		Status:      model.ScheduleStatusPending,
+		UserId:      userId,
+		CreatedAt:   time.Now(),
+	}
+
+	id, err := pu.priceRepo.CreateScheduledChange(ctx, change)
+	if err != nil {
+		return nil, err
+	}
+	change.Id = id
+
+	return &change, nil
+}
+
+func (pu *PriceUseCase) CancelScheduledChange(ctx context.Context, productId, scheduleId int) (bool, error) {
+	return pu.priceRepo.CancelScheduledChange(ctx, productId, scheduleId)
+}
+
+// ApplyDueChanges is called periodically by the price scheduler worker. Returns how many
+// changes were applied and how many rejected for falling below the minimum margin
+func (pu *PriceUseCase) ApplyDueChanges(ctx context.Context) (int, int, error) {
+	return pu.priceRepo.ApplyDueChanges(ctx, time.Now())
+}
@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestSchedulePriceChange(t *testing.T) {
+	future := time.Now().Add(24 * time.Hour)
+	lowerCost := 6.0
+
+	tests := []struct {
+		name     string
+		request  model.SchedulePriceRequest
+		margin   any
+		wantCode string
+	}{
+		{name: "zero price", request: model.SchedulePriceRequest{SalePrice: 0, EffectiveAt: future}, wantCode: "invalid_price"},
+		{name: "past date", request: model.SchedulePriceRequest{SalePrice: 10, EffectiveAt: time.Now().Add(-time.Minute)}, wantCode: "invalid_effective_date"},
+		{name: "below cost", request: model.SchedulePriceRequest{SalePrice: 7.5, EffectiveAt: future}, margin: nil, wantCode: "below_minimum_margin"},
+		{name: "below minimum margin", request: model.SchedulePriceRequest{SalePrice: 10, EffectiveAt: future}, margin: 30.0, wantCode: "below_minimum_margin"},
+		{name: "within the margin with the scheduled cost", request: model.SchedulePriceRequest{SalePrice: 10, CostPrice: &lowerCost, EffectiveAt: future}, margin: 30.0},
+		{name: "category without rule", request: model.SchedulePriceRequest{SalePrice: 10, EffectiveAt: future}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			if tt.request.SalePrice > 0 && tt.request.EffectiveAt.After(time.Now()) {
+				// The product costs 8 and belongs to category 3
+				mock.ExpectPrepare("FROM produto WHERE id_produto").ExpectQuery().WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id_produto", "codigo_produto", "codigo_barras", "nome", "descricao",
+						"categoria_id", "fornecedor_id", "preco_custo", "preco_venda", "unidade_medida", "estoque_atual",
+						"estoque_minimo", "controla_estoque", "controla_lote", "ativo"}).
+						AddRow(1, "CAF-1", nil, "Café", nil, 3, nil, 8.0, 9.0, "UN", 10, 0, true, false, true))
+				rule := mock.ExpectQuery("FROM regra_preco_categoria WHERE categoria_id").WithArgs(3)
+				if tt.margin != nil || tt.name == "below cost" {
+					rule.WillReturnRows(sqlmock.NewRows([]string{"categoria_id", "markup_alvo", "margem_minima", "arredondamento"}).
+						AddRow(3, nil, tt.margin, "NENHUM"))
+				} else {
+					rule.WillReturnRows(sqlmock.NewRows(nil))
+				}
+				if tt.wantCode == "" {
+					mock.ExpectQuery("INSERT INTO alteracao_preco_agendada").
+						WillReturnRows(sqlmock.NewRows([]string{"id_alteracao"}).AddRow(5))
+				}
+			}
+
+			uc := NewPriceUseCase(repository.NewPriceRepository(db, repository.Timeouts{}),
+				repository.NewProductRepository(db, repository.Timeouts{}, slog.Default()),
+				repository.NewPricingRuleRepository(db, repository.Timeouts{}))
+			change, err := uc.SchedulePriceChange(context.Background(), 1, tt.request, nil)
+
+			if tt.wantCode != "" {
+				var appErr *apperror.Error
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Fatalf("got %v, want %s", err, tt.wantCode)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("SchedulePriceChange: %v", err)
+				}
+				if change.Id != 5 || change.Status != model.ScheduleStatusPending {
+					t.Errorf("unexpected change: %+v", change)
+				}
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
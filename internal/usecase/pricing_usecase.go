@@ -0,0 +1,104 @@
+package usecase
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/pricing"
+	"APIGolang/internal/repository"
+	"context"
+)
+
+// ErrCategoryNotFound is returned when the category informed does not exist
+var ErrCategoryNotFound = apperror.NotFound("category_not_found", "Categoria não encontrada")
+
+type PricingUseCase struct {
+	ruleRepo     repository.PricingRuleRepository
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+}
+
+func NewPricingUseCase(ruleRepo repository.PricingRuleRepository, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository) PricingUseCase {
+	return PricingUseCase{
+		ruleRepo:     ruleRepo,
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// GetProductPricing returns markup, margin and, when the category has a target markup,
+// the suggested sale price for the current cost
+func (pu *PricingUseCase) GetProductPricing(ctx context.Context, productId int) (*model.ProductPricing, error) {
+
+	product, err := pu.productRepo.GetProductById(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	rule, err := pu.ruleRepo.GetRuleByCategory(ctx, *product.CategoryId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.ProductPricing{
+		ProductId:      product.Id,
+		CostPrice:      *product.CostPrice,
+		SalePrice:      *product.Price,
+		Markup:         pricing.Markup(*product.CostPrice, *product.Price),
+		Margin:         pricing.Margin(*product.CostPrice, *product.Price),
+		Rule:           rule,
+		SuggestedPrice: suggestedPrice(*product.CostPrice, rule),
+	}, nil
+}
+
+func (pu *PricingUseCase) GetCategoryRule(ctx context.Context, categoryId int) (*model.PricingRule, error) {
+
+	rule, err := pu.ruleRepo.GetRuleByCategory(ctx, categoryId)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, apperror.NotFound("pricing_rule_not_found", "Categoria sem regra de preço")
+	}
+	return rule, nil
+}
+
+func (pu *PricingUseCase) SaveCategoryRule(ctx context.Context, rule model.PricingRule) (*model.PricingRule, error) {
+
+	if rule.Rounding == "" {
+		rule.Rounding = pricing.RoundingNone
+	}
+	if !pricing.ValidRounding(rule.Rounding) {
+		return nil, apperror.Validation("invalid_rounding", "Arredondamento inválido, use NENHUM, X_99 ou X_49")
+	}
+	if rule.TargetMarkup != nil && *rule.TargetMarkup < 0 {
+		return nil, apperror.Validation("invalid_target_markup", "O markup alvo não pode ser negativo")
+	}
+	if rule.MinimumMargin != nil && (*rule.MinimumMargin < 0 || *rule.MinimumMargin >= 100) {
+		return nil, apperror.Validation("invalid_minimum_margin", "A margem mínima deve estar entre 0 e 100")
+	}
+
+	category, err := pu.categoryRepo.GetCategoryById(ctx, rule.CategoryId)
+	if err != nil {
+		return nil, err
+	}
+	if category == nil {
+		return nil, ErrCategoryNotFound
+	}
+
+	if err := pu.ruleRepo.SaveRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// suggestedPrice returns nil when the category has no target markup
+func suggestedPrice(cost float64, rule *model.PricingRule) *float64 {
+	if rule == nil || rule.TargetMarkup == nil {
+		return nil
+	}
+	price := pricing.SuggestPrice(cost, *rule.TargetMarkup, rule.Rounding)
+	return &price
+}
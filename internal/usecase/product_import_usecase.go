@@ -0,0 +1,502 @@
+package usecase
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"APIGolang/internal/spreadsheet"
+	"APIGolang/internal/validation"
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// importColumns maps the accepted header names to the produto field they fill
+var importColumns = map[string]string{
+	"codigo_produto":   "codigo_produto",
+	"codigo":           "codigo_produto",
+	"sku":              "codigo_produto",
+	"codigo_barras":    "codigo_barras",
+	"ean":              "codigo_barras",
+	"gtin":             "codigo_barras",
+	"nome":             "nome",
+	"descricao":        "descricao",
+	"categoria":        "categoria",
+	"fornecedor":       "fornecedor",
+	"cnpj_fornecedor":  "fornecedor",
+	"preco_custo":      "preco_custo",
+	"custo":            "preco_custo",
+	"preco_venda":      "preco_venda",
+	"preco":            "preco_venda",
+	"unidade_medida":   "unidade_medida",
+	"unidade":          "unidade_medida",
+	"estoque_atual":    "estoque_atual",
+	"estoque":          "estoque_atual",
+	"estoque_minimo":   "estoque_minimo",
+	"controla_estoque": "controla_estoque",
+	"ativo":            "ativo",
+}
+
+var requiredImportColumns = []string{"codigo_produto", "nome", "categoria", "preco_custo", "preco_venda"}
+
+type ProductImportUseCase struct {
+	productRepo  repository.ProductRepository
+	categoryRepo repository.CategoryRepository
+	supplierRepo repository.SupplierRepository
+	audit        repository.AuditRepository
+	unitOfWork   repository.UnitOfWork
+}
+
+func NewProductImportUseCase(productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository, supplierRepo repository.SupplierRepository, audit repository.AuditRepository, unitOfWork repository.UnitOfWork) ProductImportUseCase {
+	return ProductImportUseCase{
+		productRepo:  productRepo,
+		categoryRepo: categoryRepo,
+		supplierRepo: supplierRepo,
+		audit:        audit,
+		unitOfWork:   unitOfWork,
+	}
+}
+
+// importLookup holds the categories and suppliers indexed by the keys a spreadsheet may reference
+type importLookup struct {
+	categoryById   map[int]bool
+	categoryByName map[string]int
+	supplierById   map[int]bool
+	supplierByCnpj map[string]int
+	supplierByName map[string]int
+}
+
+// Import validates every row of the spreadsheet and, unless dryRun is set or some row
+// is invalid, upserts the products by codigo_produto in a single transaction, along with
+// the audit entry of each one
+// Stock of existing products is never overwritten, estoque_atual only applies to new ones
+func (uc *ProductImportUseCase) Import(ctx context.Context, file io.Reader, format string, dryRun bool, actor model.Actor) (*model.ImportReport, error) {
+
+	rows, err := spreadsheet.ReadRows(file, format)
+	if err != nil {
+		return nil, apperror.Validation("invalid_file", "Não foi possível ler a planilha: %s", err.Error())
+	}
+	if len(rows) == 0 {
+		return nil, apperror.Validation("empty_file", "O arquivo está vazio")
+	}
+
+	columns, err := mapImportHeader(rows[0])
+	if err != nil {
+		return nil, err
+	}
+
+	lookup, err := uc.loadLookup(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &model.ImportReport{DryRun: dryRun, Errors: []model.ImportRowError{}}
+	var products []model.Product
+	seenCodes := make(map[string]int)
+	seenBarcodes := make(map[string]int)
+	productRows := make(map[string]int)
+
+	for i, row := range rows[1:] {
+		rowNumber := i + 2
+		if isEmptyRow(row) {
+			continue
+		}
+		report.TotalRows++
+
+		product, rowErrors := parseImportRow(row, columns, lookup, rowNumber)
+
+		if product.Code != nil {
+			if first, ok := seenCodes[*product.Code]; ok {
+				rowErrors = append(rowErrors, model.ImportRowError{Row: rowNumber, Field: "codigo_produto", Code: "duplicate_code",
+					Message: "código repetido, já informado na linha %d", Args: []any{first}})
+			} else {
+				seenCodes[*product.Code] = rowNumber
+			}
+		}
+		if product.Barcode != nil {
+			if first, ok := seenBarcodes[*product.Barcode]; ok {
+				rowErrors = append(rowErrors, model.ImportRowError{Row: rowNumber, Field: "codigo_barras", Code: "duplicate_barcode",
+					Message: "código de barras repetido, já informado na linha %d", Args: []any{first}})
+			} else {
+				seenBarcodes[*product.Barcode] = rowNumber
+			}
+		}
+
+		if len(rowErrors) > 0 {
+			report.Errors = append(report.Errors, rowErrors...)
+			continue
+		}
+
+		productRows[*product.Code] = rowNumber
+		products = append(products, product)
+	}
+
+	ownerErrors, err := uc.checkBarcodeOwners(ctx, products, productRows)
+	if err != nil {
+		return nil, err
+	}
+	report.Errors = append(report.Errors, ownerErrors...)
+
+	existing, err := uc.productRepo.ExistingProductCodes(ctx, mapKeys(seenCodes))
+	if err != nil {
+		return nil, err
+	}
+
+	invalidRows := make(map[int]bool)
+	for _, rowErr := range report.Errors {
+		invalidRows[rowErr.Row] = true
+	}
+	report.ValidRows = report.TotalRows - len(invalidRows)
+
+	if dryRun || len(report.Errors) > 0 {
+		report.Created, report.Updated = countImport(products, productRows, invalidRows, existing)
+		return report, nil
+	}
+
+	err = uc.unitOfWork.Do(ctx, func(ctx context.Context, tx *repository.Tx) error {
+		productRepo, audit := uc.productRepo.WithTx(tx), uc.audit.WithTx(tx)
+		imported, err := productRepo.ImportProducts(ctx, products, actor.UserId)
+		if err != nil {
+			return err
+		}
+
+		report.Created, report.Updated = 0, 0
+		for _, product := range imported {
+			action := model.AuditActionUpdate
+			if product.Before == nil {
+				action = model.AuditActionCreate
+				report.Created++
+			} else {
+				fillPricing(product.Before)
+				report.Updated++
+			}
+			fillPricing(&product.After)
+			err = recordAudit(ctx, &audit, actor, model.AuditEntityProduct, product.After.Id, action, product.Before, product.After)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// countImport tells how many of the valid products would be created and how many updated
+func countImport(products []model.Product, productRows map[string]int, invalidRows map[int]bool, existing map[string]bool) (created, updated int) {
+	for _, product := range products {
+		if invalidRows[productRows[*product.Code]] {
+			continue
+		}
+		if existing[*product.Code] {
+			updated++
+		} else {
+			created++
+		}
+	}
+	return created, updated
+}
+
+func (uc *ProductImportUseCase) loadLookup(ctx context.Context) (*importLookup, error) {
+
+	lookup := &importLookup{
+		categoryById:   make(map[int]bool),
+		categoryByName: make(map[string]int),
+		supplierById:   make(map[int]bool),
+		supplierByCnpj: make(map[string]int),
+		supplierByName: make(map[string]int),
+	}
+
+	categories, err := uc.categoryRepo.GetCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, category := range categories {
+		lookup.categoryById[category.Id] = true
+		lookup.categoryByName[normalizeKey(category.Name)] = category.Id
+	}
+
+	suppliers, err := uc.supplierRepo.GetSuppliers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, supplier := range suppliers {
+		lookup.supplierById[supplier.Id] = true
+		lookup.supplierByCnpj[validation.OnlyDigits(supplier.Cnpj)] = supplier.Id
+		lookup.supplierByName[normalizeKey(supplier.Name)] = supplier.Id
+	}
+
+	return lookup, nil
+}
+
+// checkBarcodeOwners rejects barcodes already used by another product of the catalog
+func (uc *ProductImportUseCase) checkBarcodeOwners(ctx context.Context, products []model.Product, productRows map[string]int) ([]model.ImportRowError, error) {
+
+	var barcodes []string
+	for _, product := range products {
+		if product.Barcode != nil {
+			barcodes = append(barcodes, *product.Barcode)
+		}
+	}
+
+	owners, err := uc.productRepo.BarcodeOwners(ctx, barcodes)
+	if err != nil {
+		return nil, err
+	}
+
+	var rowErrors []model.ImportRowError
+	for _, product := range products {
+		if product.Barcode == nil {
+			continue
+		}
+		owner, ok := owners[*product.Barcode]
+		if ok && owner != *product.Code {
+			rowErrors = append(rowErrors, model.ImportRowError{
+				Row:     productRows[*product.Code],
+				Field:   "codigo_barras",
+				Code:    "barcode_taken",
+				Message: "código de barras já pertence ao produto %s",
+				Args:    []any{owner},
+			})
+		}
+	}
+	return rowErrors, nil
+}
+
+// mapImportHeader returns the produto field handled by each column index
+func mapImportHeader(header []string) (map[string]int, error) {
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		field, ok := importColumns[normalizeKey(name)]
+		if !ok {
+			continue
+		}
+		if _, duplicated := columns[field]; duplicated {
+			return nil, apperror.Validation("duplicate_column", "a coluna %s foi informada mais de uma vez", field)
+		}
+		columns[field] = i
+	}
+
+	var missing []string
+	for _, field := range requiredImportColumns {
+		if _, ok := columns[field]; !ok {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, apperror.Validation("missing_columns", "colunas obrigatórias ausentes: %s", strings.Join(missing, ", "))
+	}
+
+	return columns, nil
+}
+
+func parseImportRow(row []string, columns map[string]int, lookup *importLookup, rowNumber int) (model.Product, []model.ImportRowError) {
+
+	var product model.Product
+	var rowErrors []model.ImportRowError
+
+	cell := func(field string) string {
+		idx, ok := columns[field]
+		if !ok || idx >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[idx])
+	}
+	fail := func(field, code, message string, args ...any) {
+		rowErrors = append(rowErrors, model.ImportRowError{Row: rowNumber, Field: field, Code: code, Message: message, Args: args})
+	}
+
+	if code := cell("codigo_produto"); code == "" {
+		fail("codigo_produto", "required", "código do produto é obrigatório")
+	} else if len(code) > 30 {
+		fail("codigo_produto", "too_long", "código do produto deve ter no máximo 30 caracteres")
+	} else {
+		product.Code = &code
+	}
+
+	if barcode := cell("codigo_barras"); barcode != "" {
+		if !validation.ValidGTIN(barcode) {
+			fail("codigo_barras", "invalid_barcode", "código de barras inválido")
+		} else {
+			product.Barcode = &barcode
+		}
+	}
+
+	if name := cell("nome"); name == "" {
+		fail("nome", "required", "nome é obrigatório")
+	} else if len([]rune(name)) > 100 {
+		fail("nome", "too_long", "nome deve ter no máximo 100 caracteres")
+	} else {
+		product.Name = &name
+	}
+
+	if description := cell("descricao"); description != "" {
+		product.Description = &description
+	}
+
+	if category := cell("categoria"); category == "" {
+		fail("categoria", "required", "categoria é obrigatória")
+	} else if id, ok := resolveCategory(category, lookup); !ok {
+		fail("categoria", "category_not_found", "categoria %q não encontrada", category)
+	} else {
+		product.CategoryId = &id
+	}
+
+	if supplier := cell("fornecedor"); supplier != "" {
+		if id, ok := resolveSupplier(supplier, lookup); !ok {
+			fail("fornecedor", "supplier_not_found", "fornecedor %q não encontrado", supplier)
+		} else {
+			product.SupplierId = &id
+		}
+	}
+
+	if cost, err := parseDecimal(cell("preco_custo")); err != nil {
+		fail("preco_custo", "invalid_number", "preço de custo inválido")
+	} else if cost < 0 {
+		fail("preco_custo", "negative", "preço de custo não pode ser negativo")
+	} else {
+		product.CostPrice = &cost
+	}
+
+	if price, err := parseDecimal(cell("preco_venda")); err != nil {
+		fail("preco_venda", "invalid_number", "preço de venda inválido")
+	} else if price <= 0 {
+		fail("preco_venda", "not_positive", "preço de venda deve ser maior que zero")
+	} else {
+		product.Price = &price
+	}
+
+	if unit := cell("unidade_medida"); unit != "" {
+		if len(unit) > 10 {
+			fail("unidade_medida", "too_long", "unidade de medida deve ter no máximo 10 caracteres")
+		} else {
+			unit = strings.ToUpper(unit)
+			product.Unit = &unit
+		}
+	}
+
+	if value := cell("estoque_atual"); value != "" {
+		if stock, err := strconv.Atoi(value); err != nil || stock < 0 {
+			fail("estoque_atual", "invalid_integer", "estoque atual deve ser um número inteiro não negativo")
+		} else {
+			product.CurrentStock = &stock
+		}
+	}
+
+	if value := cell("estoque_minimo"); value != "" {
+		if stock, err := strconv.Atoi(value); err != nil || stock < 0 {
+			fail("estoque_minimo", "invalid_integer", "estoque mínimo deve ser um número inteiro não negativo")
+		} else {
+			product.MinimumStock = &stock
+		}
+	}
+
+	if value := cell("controla_estoque"); value != "" {
+		if flag, ok := parseFlag(value); !ok {
+			fail("controla_estoque", "invalid_flag", "valor deve ser sim ou não")
+		} else {
+			product.ControlsStock = &flag
+		}
+	}
+
+	if value := cell("ativo"); value != "" {
+		if flag, ok := parseFlag(value); !ok {
+			fail("ativo", "invalid_flag", "valor deve ser sim ou não")
+		} else {
+			product.Active = &flag
+		}
+	}
+
+	return product, rowErrors
+}
+
+// resolveCategory accepts the category id or its name
+func resolveCategory(value string, lookup *importLookup) (int, bool) {
+	if id, err := strconv.Atoi(value); err == nil {
+		return id, lookup.categoryById[id]
+	}
+	id, ok := lookup.categoryByName[normalizeKey(value)]
+	return id, ok
+}
+
+// resolveSupplier accepts the supplier CNPJ (masked or not), its id or its name
+func resolveSupplier(value string, lookup *importLookup) (int, bool) {
+	if digits := validation.OnlyDigits(value); len(digits) == 14 {
+		id, ok := lookup.supplierByCnpj[digits]
+		return id, ok
+	}
+	if id, err := strconv.Atoi(value); err == nil {
+		return id, lookup.supplierById[id]
+	}
+	id, ok := lookup.supplierByName[normalizeKey(value)]
+	return id, ok
+}
+
+// parseDecimal accepts both 1,234.56 and the pt-BR format 1.234,56, with optional R$ prefix.
+// The last separator is the decimal one and the other groups thousands; a separator repeated
+// without the other, as in 1.234.567, only groups thousands
+func parseDecimal(value string) (float64, error) {
+	value = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(value), "R$"))
+	if value == "" {
+		return 0, errors.New("valor vazio")
+	}
+	if last := strings.LastIndexAny(value, ",."); last >= 0 {
+		separator := value[last : last+1]
+		if strings.Count(value, separator) > 1 {
+			value = strings.ReplaceAll(value, separator, "")
+		} else {
+			value = strings.NewReplacer(",", "", ".", "").Replace(value[:last]) + "." + value[last+1:]
+		}
+	}
+	return strconv.ParseFloat(value, 64)
+}
+
+func parseFlag(value string) (bool, bool) {
+	switch normalizeKey(value) {
+	case "sim", "s", "true", "1", "yes", "y":
+		return true, true
+	case "nao", "n", "false", "0", "no":
+		return false, true
+	}
+	return false, false
+}
+
+var accentReplacer = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ã", "a",
+	"é", "e", "ê", "e",
+	"í", "i",
+	"ó", "o", "ô", "o", "õ", "o",
+	"ú", "u", "ü", "u",
+	"ç", "c",
+)
+
+// normalizeKey lowercases, removes accents and joins words with underscore,
+// so "Código de Barras" and "codigo_barras" compare equal
+func normalizeKey(value string) string {
+	value = accentReplacer.Replace(strings.ToLower(strings.TrimSpace(value)))
+	value = strings.ReplaceAll(value, " de ", " ")
+	return strings.Join(strings.Fields(value), "_")
+}
+
+func isEmptyRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,213 @@
+package usecase
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/i18n"
+	"APIGolang/internal/model"
+	"errors"
+	"testing"
+)
+
+func TestParseDecimal(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    float64
+		wantErr bool
+	}{
+		{"1234.56", 1234.56, false},
+		{"1.234,56", 1234.56, false},
+		{"1,234.56", 1234.56, false},
+		{"1.234.567,89", 1234567.89, false},
+		{"1.234.567", 1234567, false},
+		{"R$ 12,90", 12.9, false},
+		{"0,5", 0.5, false},
+		{"", 0, true},
+		{"R$", 0, true},
+		{"doze", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseDecimal(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseDecimal(%q): got error %v, want error %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseDecimal(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestMapImportHeader(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   []string
+		want     map[string]int
+		wantCode string
+	}{
+		{
+			name:   "aliases and accents",
+			header: []string{"SKU", "Código de Barras", "Nome", "Categoria", "Custo", "Preço", "ignorada"},
+			want: map[string]int{"codigo_produto": 0, "codigo_barras": 1, "nome": 2, "categoria": 3,
+				"preco_custo": 4, "preco_venda": 5},
+		},
+		{
+			name:     "duplicated column",
+			header:   []string{"codigo", "sku", "nome", "categoria", "custo", "preco"},
+			wantCode: "duplicate_column",
+		},
+		{
+			name:     "missing columns",
+			header:   []string{"codigo", "nome"},
+			wantCode: "missing_columns",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mapImportHeader(tt.header)
+			if tt.wantCode != "" {
+				var appErr *apperror.Error
+				if !errors.As(err, &appErr) || appErr.Code != tt.wantCode {
+					t.Fatalf("got %v, want %s", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("mapImportHeader: %v", err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for field, idx := range tt.want {
+				if got[field] != idx {
+					t.Errorf("%s: got column %d, want %d", field, got[field], idx)
+				}
+			}
+		})
+	}
+}
+
+func TestParseImportRow(t *testing.T) {
+	columns, err := mapImportHeader([]string{"codigo", "ean", "nome", "categoria", "fornecedor", "custo", "preco",
+		"unidade", "estoque", "controla_estoque"})
+	if err != nil {
+		t.Fatalf("mapImportHeader: %v", err)
+	}
+	lookup := &importLookup{
+		categoryById:   map[int]bool{3: true},
+		categoryByName: map[string]int{"bebidas": 3},
+		supplierById:   map[int]bool{5: true},
+		supplierByCnpj: map[string]int{"11222333000181": 5},
+		supplierByName: map[string]int{"distribuidora": 5},
+	}
+
+	tests := []struct {
+		name       string
+		row        []string
+		wantFields []string
+		check      func(t *testing.T, product model.Product)
+	}{
+		{
+			name: "valid row",
+			row:  []string{"CAF-1", "7891000315507", "Café", "Bebidas", "11.222.333/0001-81", "10,50", "R$ 15,90", "un", "12", "sim"},
+			check: func(t *testing.T, product model.Product) {
+				if *product.Code != "CAF-1" || *product.Barcode != "7891000315507" || *product.CategoryId != 3 ||
+					*product.SupplierId != 5 || *product.CostPrice != 10.5 || *product.Price != 15.9 ||
+					*product.Unit != "UN" || *product.CurrentStock != 12 || !*product.ControlsStock {
+					t.Errorf("unexpected product: %+v", product)
+				}
+			},
+		},
+		{
+			name: "optional columns left empty",
+			row:  []string{"CAF-2", "", "Café", "3", "", "0", "9.90", "", "", ""},
+			check: func(t *testing.T, product model.Product) {
+				if product.Barcode != nil || product.SupplierId != nil || product.Unit != nil ||
+					product.CurrentStock != nil || product.ControlsStock != nil {
+					t.Errorf("expected the optional fields unset: %+v", product)
+				}
+			},
+		},
+		{
+			name:       "short row",
+			row:        []string{"CAF-3"},
+			wantFields: []string{"nome", "categoria", "preco_custo", "preco_venda"},
+		},
+		{
+			name: "invalid values",
+			row: []string{"CAF-4", "7891000315508", "Café", "Padaria", "Outro", "-1", "0", "UNIDADE_LONGA",
+				"-3", "talvez"},
+			wantFields: []string{"codigo_barras", "categoria", "fornecedor", "preco_custo", "preco_venda",
+				"unidade_medida", "estoque_atual", "controla_estoque"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			product, rowErrors := parseImportRow(tt.row, columns, lookup, 7)
+
+			var fields []string
+			for _, rowErr := range rowErrors {
+				if rowErr.Row != 7 {
+					t.Errorf("got row %d, want 7", rowErr.Row)
+				}
+				fields = append(fields, rowErr.Field)
+			}
+			if len(fields) != len(tt.wantFields) {
+				t.Fatalf("got errors on %v, want on %v", fields, tt.wantFields)
+			}
+			for i := range fields {
+				if fields[i] != tt.wantFields[i] {
+					t.Errorf("got errors on %v, want on %v", fields, tt.wantFields)
+					break
+				}
+			}
+			if tt.check != nil {
+				tt.check(t, product)
+			}
+		})
+	}
+}
+
+func TestImportRowErrorsAreTranslated(t *testing.T) {
+	columns, err := mapImportHeader([]string{"codigo", "nome", "categoria", "custo", "preco"})
+	if err != nil {
+		t.Fatalf("mapImportHeader: %v", err)
+	}
+	lookup := &importLookup{categoryByName: map[string]int{}}
+
+	_, rowErrors := parseImportRow([]string{"CAF-1", "Café", "Padaria", "10", "12"}, columns, lookup, 2)
+	if len(rowErrors) != 1 || rowErrors[0].Code != "category_not_found" {
+		t.Fatalf("got %+v, want category_not_found", rowErrors)
+	}
+	want := `category "Padaria" not found`
+	if got := i18n.T(i18n.EnUS, rowErrors[0].Message, rowErrors[0].Args...); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestCountImport(t *testing.T) {
+	code := func(value string) model.Product { return model.Product{Code: &value} }
+	products := []model.Product{code("A"), code("B"), code("C"), code("D")}
+	productRows := map[string]int{"A": 2, "B": 3, "C": 4, "D": 5}
+	existing := map[string]bool{"B": true, "D": true}
+
+	tests := []struct {
+		name        string
+		invalidRows map[int]bool
+		wantCreated int
+		wantUpdated int
+	}{
+		{"every row valid", map[int]bool{}, 2, 2},
+		{"invalid rows left out", map[int]bool{2: true, 5: true}, 1, 1},
+	}
+
+	for _, tt := range tests {
+		created, updated := countImport(products, productRows, tt.invalidRows, existing)
+		if created != tt.wantCreated || updated != tt.wantUpdated {
+			t.Errorf("%s: got %d created and %d updated, want %d and %d",
+				tt.name, created, updated, tt.wantCreated, tt.wantUpdated)
+		}
+	}
+}
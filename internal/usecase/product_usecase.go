@@ -2,57 +2,130 @@ package usecase
 
 import (
 	"APIGolang/internal/model"
+	"APIGolang/internal/pricing"
 	"APIGolang/internal/repository"
+	"context"
 )
 
 type ProductUsecase struct {
 	repository repository.ProductRepository
+	audit      repository.AuditRepository
+	unitOfWork repository.UnitOfWork
 }
 
-func NewProductUseCase(repo repository.ProductRepository) ProductUsecase {
+func NewProductUseCase(repo repository.ProductRepository, audit repository.AuditRepository, unitOfWork repository.UnitOfWork) ProductUsecase {
 	return ProductUsecase{
 		repository: repo,
+		audit:      audit,
+		unitOfWork: unitOfWork,
 	}
 }
 
-func (pu *ProductUsecase) GetProducts() ([]model.Product, error){
+// withTx returns a copy of the usecase whose repositories run in tx
+func (pu *ProductUsecase) withTx(tx *repository.Tx) *ProductUsecase {
+	bound := *pu
+	bound.repository = pu.repository.WithTx(tx)
+	bound.audit = pu.audit.WithTx(tx)
+	return &bound
+}
+
+func (pu *ProductUsecase) GetProducts(ctx context.Context) ([]model.Product, error){
 
-	return pu.repository.GetProducts()
+	products, err := pu.repository.GetProducts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		fillPricing(&products[i])
+	}
+	return products, nil
 }
 
-func (pu *ProductUsecase) GetProductById(product_id int) (*model.Product, error) {
-	product, err := pu.repository.GetProductById(product_id)
+func (pu *ProductUsecase) GetProductById(ctx context.Context, product_id int) (*model.Product, error) {
+	product, err := pu.repository.GetProductById(ctx, product_id)
 	if err != nil {
 		return nil, err
 	}
+	if product != nil {
+		fillPricing(product)
+	}
 	return product, nil
 }
 
-func (pu *ProductUsecase) CreateProduct(product model.Product) (model.Product, error) {
-	
-	productId, err := pu.repository.CreateProduct(product)
+func (pu *ProductUsecase) CreateProduct(ctx context.Context, product model.Product, actor model.Actor) (model.Product, error) {
+
+	err := pu.unitOfWork.Do(ctx, func(ctx context.Context, tx *repository.Tx) error {
+		bound := pu.withTx(tx)
+		productId, err := bound.repository.CreateProduct(ctx, product, actor.UserId)
+		if err != nil {
+			return err
+		}
+		product.Id = productId
+		return recordAudit(ctx, &bound.audit, actor, model.AuditEntityProduct, productId, model.AuditActionCreate, nil, product)
+	})
 	if err != nil {
 		return model.Product{}, err
 	}
-
-	product.Id = productId
 	return product, nil
 }
 
-func (pu *ProductUsecase) UpdateProductById(product_id int, product model.Product) (*model.Product, error) {
+func (pu *ProductUsecase) UpdateProductById(ctx context.Context, product_id int, product model.Product, actor model.Actor) (*model.Product, error) {
+
+	var updatedProduct *model.Product
+	err := pu.unitOfWork.Do(ctx, func(ctx context.Context, tx *repository.Tx) error {
+		bound := pu.withTx(tx)
 
-	updatedProduct, err := pu.repository.UpdateProductById(product_id, product)
+		before, err := bound.GetProductById(ctx, product_id)
+		if err != nil || before == nil {
+			updatedProduct = nil
+			return err
+		}
+
+		updatedProduct, err = bound.repository.UpdateProductById(ctx, product_id, product, actor.UserId)
+		if err != nil || updatedProduct == nil {
+			return err
+		}
+		fillPricing(updatedProduct)
+		return recordAudit(ctx, &bound.audit, actor, model.AuditEntityProduct, product_id, model.AuditActionUpdate, before, updatedProduct)
+	})
 	if err != nil {
 		return nil, err
 	}
 	return updatedProduct, nil
 }
 
-func (pu *ProductUsecase) DeleteProductById(product_id int) (bool, error) {
+func (pu *ProductUsecase) DeleteProductById(ctx context.Context, product_id int, actor model.Actor) (bool, error) {
 
-	isSuccess, err := pu.repository.DeleteProductById(product_id)
-	if err != nil {
-		return false, err
+	var isSuccess bool
+	err := pu.unitOfWork.Do(ctx, func(ctx context.Context, tx *repository.Tx) error {
+		bound := pu.withTx(tx)
+
+		before, err := bound.GetProductById(ctx, product_id)
+		if err != nil {
+			return err
+		}
+
+		isSuccess, err = bound.repository.DeleteProductById(ctx, product_id)
+		if err != nil || !isSuccess {
+			return err
+		}
+		return recordAudit(ctx, &bound.audit, actor, model.AuditEntityProduct, product_id, model.AuditActionDelete, before, nil)
+	})
+	return isSuccess, err
+}
+
+func (pu *ProductUsecase) EachProduct(ctx context.Context, fn func(model.Product) error) error {
+	return pu.repository.EachProduct(ctx, func(product model.Product) error {
+		fillPricing(&product)
+		return fn(product)
+	})
+}
+
+// fillPricing computes markup and margin from the stored prices
+func fillPricing(product *model.Product) {
+	if product.CostPrice == nil || product.Price == nil {
+		return
 	}
-	return isSuccess, nil
-}
\ No newline at end of file
+	product.Markup = pricing.Markup(*product.CostPrice, *product.Price)
+	product.Margin = pricing.Margin(*product.CostPrice, *product.Price)
+}
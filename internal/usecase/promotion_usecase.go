@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"context"
+)
+
+type PromotionUseCase struct {
+	promotionRepo repository.PromotionRepository
+	productRepo   repository.ProductRepository
+	categoryRepo  repository.CategoryRepository
+}
+
+func NewPromotionUseCase(promotionRepo repository.PromotionRepository, productRepo repository.ProductRepository, categoryRepo repository.CategoryRepository) PromotionUseCase {
+	return PromotionUseCase{
+		promotionRepo: promotionRepo,
+		productRepo:   productRepo,
+		categoryRepo:  categoryRepo,
+	}
+}
+
+func (pu *PromotionUseCase) GetPromotions(ctx context.Context) ([]model.Promotion, error) {
+	return pu.promotionRepo.GetPromotions(ctx)
+}
+
+func (pu *PromotionUseCase) DeactivatePromotion(ctx context.Context, id int) (bool, error) {
+	return pu.promotionRepo.DeactivatePromotion(ctx, id)
+}
+
+func (pu *PromotionUseCase) CreatePromotion(ctx context.Context, promotion model.Promotion) (*model.Promotion, error) {
+
+	if err := validatePromotionRule(promotion); err != nil {
+		return nil, err
+	}
+	if !promotion.EndsAt.After(promotion.StartsAt) {
+		return nil, apperror.Validation("invalid_period", "A data final deve ser posterior à data inicial")
+	}
+
+	if promotion.CategoryId != nil {
+		category, err := pu.categoryRepo.GetCategoryById(ctx, *promotion.CategoryId)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			return nil, ErrCategoryNotFound
+		}
+	}
+
+	ids := make([]int, 0, len(promotion.Products))
+	for _, product := range promotion.Products {
+		ids = append(ids, product.ProductId)
+	}
+	products, err := pu.productRepo.GetProductsByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if _, ok := products[id]; !ok {
+			return nil, apperror.NotFound("product_not_found", "Produto %d não encontrado", id)
+		}
+	}
+
+	id, err := pu.promotionRepo.CreatePromotion(ctx, promotion)
+	if err != nil {
+		return nil, err
+	}
+
+	promotion.Id = id
+	promotion.Active = true
+	return &promotion, nil
+}
+
+// validatePromotionRule checks that the fields required by the promotion type were informed
+func validatePromotionRule(promotion model.Promotion) error {
+
+	needsProducts := func() error {
+		if len(promotion.Products) == 0 {
+			return apperror.Validation("promotion_products_required", "Informe os produtos da promoção")
+		}
+		seen := make(map[int]bool)
+		for _, product := range promotion.Products {
+			if seen[product.ProductId] {
+				return apperror.Validation("duplicate_product", "Produto %d informado mais de uma vez", product.ProductId)
+			}
+			seen[product.ProductId] = true
+		}
+		return nil
+	}
+	needsPercent := func() error {
+		if promotion.DiscountPercent == nil || *promotion.DiscountPercent <= 0 || *promotion.DiscountPercent > 100 {
+			return apperror.Validation("invalid_discount_percentage", "O percentual de desconto deve estar entre 0 e 100")
+		}
+		return nil
+	}
+
+	switch promotion.Type {
+	case model.PromotionPercent:
+		if err := needsPercent(); err != nil {
+			return err
+		}
+		return needsProducts()
+
+	case model.PromotionFixed:
+		if promotion.FixedPrice == nil || *promotion.FixedPrice < 0 {
+			return apperror.Validation("promotion_price_required", "Informe o preço fixo da promoção")
+		}
+		return needsProducts()
+
+	case model.PromotionBuyXPayY:
+		if promotion.BuyQuantity == nil || promotion.PayQuantity == nil ||
+			*promotion.PayQuantity < 1 || *promotion.BuyQuantity <= *promotion.PayQuantity {
+			return apperror.Validation("invalid_buy_pay_quantity", "A quantidade levada deve ser maior que a quantidade paga")
+		}
+		return needsProducts()
+
+	case model.PromotionCategory:
+		if promotion.CategoryId == nil {
+			return apperror.Validation("promotion_category_required", "Informe a categoria da promoção")
+		}
+		return needsPercent()
+
+	case model.PromotionCombo:
+		if promotion.FixedPrice == nil || *promotion.FixedPrice <= 0 {
+			return apperror.Validation("combo_price_required", "Informe o preço do combo")
+		}
+		if len(promotion.Products) < 2 {
+			return apperror.Validation("combo_products_required", "O combo precisa de ao menos dois produtos")
+		}
+		return needsProducts()
+	}
+
+	return apperror.Validation("invalid_promotion_type", "Tipo de promoção inválido: %s", promotion.Type)
+}
@@ -0,0 +1,223 @@
+package usecase
+
+import (
+	"APIGolang/internal/analysis"
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/model"
+	"APIGolang/internal/promotion"
+	"APIGolang/internal/repository"
+	"context"
+	"strings"
+	"time"
+)
+
+var ErrPurchaseOrderNotFound = apperror.NotFound("purchase_order_not_found", "Pedido de compra não encontrado")
+
+type PurchaseUseCase struct {
+	purchaseRepo repository.PurchaseRepository
+	productRepo  repository.ProductRepository
+	supplierRepo repository.SupplierRepository
+	metrics      *metrics.Metrics
+}
+
+func NewPurchaseUseCase(purchaseRepo repository.PurchaseRepository, productRepo repository.ProductRepository, supplierRepo repository.SupplierRepository, m *metrics.Metrics) PurchaseUseCase {
+	return PurchaseUseCase{
+		purchaseRepo: purchaseRepo,
+		productRepo:  productRepo,
+		supplierRepo: supplierRepo,
+		metrics:      m,
+	}
+}
+
+// GetSuggestions suggests, per supplier, how much to buy of each product so the stock covers
+// estoque_minimo plus coverageDays of sales at the average pace of the last salesDays.
+// Quantities already in open orders are discounted and products with nothing to buy are omitted
+func (pu *PurchaseUseCase) GetSuggestions(ctx context.Context, salesDays, coverageDays int, supplierId *int, now time.Time) ([]model.PurchaseSuggestion, error) {
+
+	if salesDays <= 0 || coverageDays <= 0 {
+		return nil, apperror.Validation("invalid_period", "Os períodos de vendas e de cobertura devem ser maiores que zero")
+	}
+
+	candidates, err := pu.purchaseRepo.GetSuggestionCandidates(ctx, now.AddDate(0, 0, -salesDays), supplierId)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := []model.PurchaseSuggestion{}
+	for _, supplier := range candidates {
+		items := []model.PurchaseSuggestionItem{}
+		total := 0.0
+
+		for _, item := range supplier.Items {
+			item.AverageDaily, _ = analysis.Coverage(item.CurrentStock, item.QuantitySold, salesDays)
+			item.SuggestedQuantity = analysis.SuggestPurchase(item.CurrentStock, item.MinimumStock,
+				item.PendingQuantity, item.QuantitySold/float64(salesDays), coverageDays)
+			if item.SuggestedQuantity == 0 {
+				continue
+			}
+			item.TotalCost = promotion.Round(item.UnitCost * float64(item.SuggestedQuantity))
+			total += item.TotalCost
+			items = append(items, item)
+		}
+
+		if len(items) == 0 {
+			continue
+		}
+		supplier.Items = items
+		supplier.TotalCost = promotion.Round(total)
+		suggestions = append(suggestions, supplier)
+	}
+
+	return suggestions, nil
+}
+
+// CreatePurchaseOrder creates a draft order; items without unit cost use the product's preco_custo
+func (pu *PurchaseUseCase) CreatePurchaseOrder(ctx context.Context, request model.PurchaseOrderRequest, userId *int) (*model.PurchaseOrder, error) {
+
+	supplier, err := pu.supplierRepo.GetSupplierById(ctx, request.SupplierId)
+	if err != nil {
+		return nil, err
+	}
+	if supplier == nil {
+		return nil, apperror.NotFound("supplier_not_found", "Fornecedor não encontrado")
+	}
+	if !supplier.Active {
+		return nil, apperror.Validation("supplier_inactive", "O fornecedor está inativo")
+	}
+
+	items, err := mergeOrderItems(request.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.ProductId)
+	}
+	products, err := pu.productRepo.GetProductsByIds(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	order := model.PurchaseOrder{
+		SupplierId:  request.SupplierId,
+		Observation: request.Observation,
+		UserId:      userId,
+	}
+	for _, item := range items {
+		product, ok := products[item.ProductId]
+		if !ok {
+			return nil, apperror.NotFound("product_not_found", "Produto %d não encontrado", item.ProductId)
+		}
+
+		unitCost := *product.CostPrice
+		if item.UnitCost != nil {
+			if *item.UnitCost < 0 {
+				return nil, apperror.Validation("invalid_cost", "Custo inválido para o produto %d", item.ProductId)
+			}
+			unitCost = *item.UnitCost
+		}
+
+		order.Items = append(order.Items, model.PurchaseOrderItem{
+			ProductId: item.ProductId,
+			Quantity:  item.Quantity,
+			UnitCost:  unitCost,
+		})
+	}
+
+	id, err := pu.purchaseRepo.CreatePurchaseOrder(ctx, order)
+	if err != nil {
+		return nil, err
+	}
+	return pu.purchaseRepo.GetPurchaseOrderById(ctx, id)
+}
+
+func (pu *PurchaseUseCase) GetPurchaseOrders(ctx context.Context, status *string) ([]model.PurchaseOrder, error) {
+	return pu.purchaseRepo.GetPurchaseOrders(ctx, status)
+}
+
+func (pu *PurchaseUseCase) GetPurchaseOrderById(ctx context.Context, id int) (*model.PurchaseOrder, error) {
+
+	order, err := pu.purchaseRepo.GetPurchaseOrderById(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, ErrPurchaseOrderNotFound
+	}
+	return order, nil
+}
+
+// SendPurchaseOrder moves a draft to ENVIADO
+func (pu *PurchaseUseCase) SendPurchaseOrder(ctx context.Context, id int) (*model.PurchaseOrder, error) {
+
+	if _, err := pu.GetPurchaseOrderById(ctx, id); err != nil {
+		return nil, err
+	}
+	if err := pu.purchaseRepo.SendPurchaseOrder(ctx, id); err != nil {
+		return nil, err
+	}
+	return pu.purchaseRepo.GetPurchaseOrderById(ctx, id)
+}
+
+// ReceivePurchaseOrder registers a full or partial delivery of a sent order
+func (pu *PurchaseUseCase) ReceivePurchaseOrder(ctx context.Context, id int, request model.PurchaseReceiptRequest, userId *int) (*model.PurchaseOrder, error) {
+
+	if _, err := pu.GetPurchaseOrderById(ctx, id); err != nil {
+		return nil, err
+	}
+
+	// Items are not merged here: the same product may arrive in different lots
+	if len(request.Items) == 0 {
+		return nil, apperror.Validation("items_required", "Informe ao menos um item")
+	}
+	for _, item := range request.Items {
+		if item.Quantity <= 0 {
+			return nil, apperror.Validation("invalid_quantity", "Quantidade inválida para o produto %d", item.ProductId)
+		}
+		if item.UnitCost != nil && *item.UnitCost < 0 {
+			return nil, apperror.Validation("invalid_cost", "Custo inválido para o produto %d", item.ProductId)
+		}
+		if item.Lot != nil && strings.TrimSpace(item.Lot.Number) == "" {
+			return nil, apperror.Validation("invalid_lot_number", "Número do lote inválido para o produto %d", item.ProductId)
+		}
+	}
+
+	if _, err := pu.purchaseRepo.ReceivePurchaseOrder(ctx, id, request.Items, userId); err != nil {
+		return nil, err
+	}
+	for _, item := range request.Items {
+		pu.metrics.StockMoved(model.StockMovementIn, metrics.SourcePurchase, item.Quantity)
+	}
+	return pu.purchaseRepo.GetPurchaseOrderById(ctx, id)
+}
+
+// mergeOrderItems sums repeated products, keeping the last unit cost informed
+func mergeOrderItems(items []model.PurchaseOrderItemRequest) ([]model.PurchaseOrderItemRequest, error) {
+
+	if len(items) == 0 {
+		return nil, apperror.Validation("items_required", "Informe ao menos um item")
+	}
+
+	merged := []model.PurchaseOrderItemRequest{}
+	index := make(map[int]int)
+
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, apperror.Validation("invalid_quantity", "Quantidade inválida para o produto %d", item.ProductId)
+		}
+		i, ok := index[item.ProductId]
+		if !ok {
+			index[item.ProductId] = len(merged)
+			merged = append(merged, item)
+			continue
+		}
+		merged[i].Quantity += item.Quantity
+		if item.UnitCost != nil {
+			merged[i].UnitCost = item.UnitCost
+		}
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"APIGolang/internal/analysis"
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/pricing"
+	"APIGolang/internal/promotion"
+	"APIGolang/internal/repository"
+	"context"
+	"time"
+)
+
+type ReportUseCase struct {
+	reportRepo repository.ReportRepository
+}
+
+func NewReportUseCase(reportRepo repository.ReportRepository) ReportUseCase {
+	return ReportUseCase{
+		reportRepo: reportRepo,
+	}
+}
+
+// GetSalesReport aggregates the completed sales between the from and to dates, both inclusive
+func (ru *ReportUseCase) GetSalesReport(ctx context.Context, groupBy string, from, to time.Time) (*model.SalesReport, error) {
+
+	if !repository.ValidSalesGrouping(groupBy) {
+		return nil, apperror.Validation("invalid_group_by", "Agrupamento inválido, use day, week, month, user, payment_method ou category")
+	}
+	if to.Before(from) {
+		return nil, apperror.Validation("invalid_period", "A data final deve ser igual ou posterior à data inicial")
+	}
+
+	end := to.AddDate(0, 0, 1)
+
+	totals, err := ru.reportRepo.GetSalesTotals(ctx, from, end)
+	if err != nil {
+		return nil, err
+	}
+	completeSummary(&totals)
+
+	groups, err := ru.reportRepo.GetSalesByGroup(ctx, groupBy, from, end)
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups {
+		completeSummary(&groups[i].SalesSummary)
+	}
+
+	return &model.SalesReport{
+		From:    from,
+		To:      to,
+		GroupBy: groupBy,
+		Totals:  totals,
+		Groups:  groups,
+	}, nil
+}
+
+// completeSummary rounds the sums read from the database and fills the derived values
+func completeSummary(summary *model.SalesSummary) {
+
+	summary.ItemCount = promotion.Round(summary.ItemCount)
+	summary.GrossRevenue = promotion.Round(summary.GrossRevenue)
+	summary.Discounts = promotion.Round(summary.Discounts)
+	summary.Cost = promotion.Round(summary.Cost)
+	summary.NetRevenue = promotion.Round(summary.GrossRevenue - summary.Discounts)
+	summary.GrossMargin = promotion.Round(summary.NetRevenue - summary.Cost)
+	summary.GrossMarginPercent = pricing.Margin(summary.Cost, summary.NetRevenue)
+
+	summary.AverageTicket = 0
+	if summary.Sales > 0 {
+		summary.AverageTicket = promotion.Round(summary.NetRevenue / float64(summary.Sales))
+	}
+}
+
+// GetAbcAnalysis classifies the products by net revenue between the from and to dates, both
+// inclusive, and computes how many days the current stock lasts at the period's sales pace
+func (ru *ReportUseCase) GetAbcAnalysis(ctx context.Context, from, to time.Time, limitA, limitB float64) (*model.AbcAnalysis, error) {
+
+	if to.Before(from) {
+		return nil, apperror.Validation("invalid_period", "A data final deve ser igual ou posterior à data inicial")
+	}
+	if limitA <= 0 || limitB <= limitA || limitB >= 100 {
+		return nil, apperror.Validation("invalid_abc_limits", "Os limites devem respeitar 0 < limite A < limite B < 100")
+	}
+
+	end := to.AddDate(0, 0, 1)
+	days := int(end.Sub(from).Hours()/24 + 0.5)
+
+	items, err := ru.reportRepo.GetProductSales(ctx, from, end)
+	if err != nil {
+		return nil, err
+	}
+
+	revenues := make([]float64, len(items))
+	for i, item := range items {
+		revenues[i] = item.Revenue
+	}
+
+	for i, classification := range analysis.ClassifyABC(revenues, limitA, limitB) {
+		item := &items[i]
+		item.Revenue = promotion.Round(item.Revenue)
+		item.Share = classification.Share
+		item.CumulativeShare = classification.CumulativeShare
+		item.Class = classification.Class
+		item.AverageDaily, item.CoverageDays = analysis.Coverage(item.CurrentStock, item.QuantitySold, days)
+	}
+
+	return &model.AbcAnalysis{
+		From:   from,
+		To:     to,
+		Days:   days,
+		LimitA: limitA,
+		LimitB: limitB,
+		Items:  items,
+	}, nil
+}
+
+// GetIdleProducts lists the active products without sales in the last days
+func (ru *ReportUseCase) GetIdleProducts(ctx context.Context, days int, now time.Time) ([]model.IdleProduct, error) {
+
+	if days <= 0 {
+		return nil, apperror.Validation("invalid_days", "A quantidade de dias deve ser maior que zero")
+	}
+
+	return ru.reportRepo.GetIdleProducts(ctx, now.AddDate(0, 0, -days))
+}
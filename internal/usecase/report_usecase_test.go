@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestCompleteSummary(t *testing.T) {
+	margin := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		summary model.SalesSummary
+		want    model.SalesSummary
+	}{
+		{
+			name:    "empty period",
+			summary: model.SalesSummary{},
+			want:    model.SalesSummary{},
+		},
+		{
+			name:    "sales with discounts",
+			summary: model.SalesSummary{Sales: 3, ItemCount: 7, GrossRevenue: 100, Discounts: 10, Cost: 60},
+			want: model.SalesSummary{Sales: 3, ItemCount: 7, GrossRevenue: 100, Discounts: 10, Cost: 60,
+				NetRevenue: 90, AverageTicket: 30, GrossMargin: 30, GrossMarginPercent: margin(33.33)},
+		},
+		{
+			name:    "floating point sums are rounded",
+			summary: model.SalesSummary{Sales: 3, ItemCount: 1.2000000001, GrossRevenue: 10.005000001, Discounts: 0.1 + 0.2, Cost: 4.333333},
+			want: model.SalesSummary{Sales: 3, ItemCount: 1.2, GrossRevenue: 10.01, Discounts: 0.3, Cost: 4.33,
+				NetRevenue: 9.71, AverageTicket: 3.24, GrossMargin: 5.38, GrossMarginPercent: margin(55.41)},
+		},
+		{
+			name:    "fully discounted sales",
+			summary: model.SalesSummary{Sales: 2, ItemCount: 2, GrossRevenue: 20, Discounts: 20, Cost: 12},
+			want: model.SalesSummary{Sales: 2, ItemCount: 2, GrossRevenue: 20, Discounts: 20, Cost: 12,
+				GrossMargin: -12},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.summary
+			completeSummary(&got)
+
+			gotPercent, wantPercent := got.GrossMarginPercent, tt.want.GrossMarginPercent
+			got.GrossMarginPercent, tt.want.GrossMarginPercent = nil, nil
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if (gotPercent == nil) != (wantPercent == nil) || gotPercent != nil && *gotPercent != *wantPercent {
+				t.Errorf("got margin %v, want %v", gotPercent, wantPercent)
+			}
+		})
+	}
+}
+
+func TestGetSalesReportEndIsExclusive(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	// Sales made at any time of the 31st fall before the start of April 1st
+	mock.ExpectQuery("v.data_venda >= \\$2 AND v.data_venda < \\$3").WithArgs(model.SaleStatusCompleted, from, end).
+		WillReturnRows(sqlmock.NewRows([]string{"vendas", "itens", "bruto", "descontos", "custo"}).AddRow(0, 0, 0, 0, 0))
+	mock.ExpectQuery("GROUP BY 1, 2").WithArgs(model.SaleStatusCompleted, from, end).
+		WillReturnRows(sqlmock.NewRows([]string{"chave", "rotulo", "vendas", "itens", "bruto", "descontos", "custo"}))
+
+	uc := NewReportUseCase(repository.NewReportRepository(db, repository.Timeouts{}))
+	report, err := uc.GetSalesReport(context.Background(), "day", from, to)
+	if err != nil {
+		t.Fatalf("GetSalesReport: %v", err)
+	}
+
+	if !report.To.Equal(to) {
+		t.Errorf("report to = %v, want the inclusive %v", report.To, to)
+	}
+	if report.Totals.AverageTicket != 0 || report.Totals.GrossMarginPercent != nil {
+		t.Errorf("unexpected totals of an empty period: %+v", report.Totals)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetSalesReportSingleDay(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock database: %v", err)
+	}
+	defer db.Close()
+
+	day := time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery("v.data_venda >= \\$2 AND v.data_venda < \\$3").WithArgs(model.SaleStatusCompleted, day, end).
+		WillReturnRows(sqlmock.NewRows([]string{"vendas", "itens", "bruto", "descontos", "custo"}).AddRow(2, 3, 50.0, 5.0, 30.0))
+	mock.ExpectQuery("GROUP BY 1, 2").WithArgs(model.SaleStatusCompleted, day, end).
+		WillReturnRows(sqlmock.NewRows([]string{"chave", "rotulo", "vendas", "itens", "bruto", "descontos", "custo"}).
+			AddRow("PIX", "Pix", 2, 3, 50.0, 5.0, 30.0))
+
+	uc := NewReportUseCase(repository.NewReportRepository(db, repository.Timeouts{}))
+	report, err := uc.GetSalesReport(context.Background(), "payment_method", day, day)
+	if err != nil {
+		t.Fatalf("GetSalesReport: %v", err)
+	}
+
+	if report.Totals.NetRevenue != 45 || report.Totals.AverageTicket != 22.5 || report.Totals.GrossMargin != 15 {
+		t.Errorf("unexpected totals: %+v", report.Totals)
+	}
+	if len(report.Groups) != 1 || report.Groups[0].NetRevenue != 45 || report.Groups[0].AverageTicket != 22.5 {
+		t.Errorf("derived values were not filled in the groups: %+v", report.Groups)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGetSalesReportRejectsInvertedPeriod(t *testing.T) {
+	uc := NewReportUseCase(repository.NewReportRepository(nil, repository.Timeouts{}))
+	from := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	_, err := uc.GetSalesReport(context.Background(), "day", from, from.AddDate(0, 0, -1))
+	var appErr *apperror.Error
+	if !errors.As(err, &appErr) || appErr.Code != "invalid_period" {
+		t.Fatalf("got %v, want invalid_period", err)
+	}
+}
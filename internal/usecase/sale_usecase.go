@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"APIGolang/internal/apperror"
+	"APIGolang/internal/metrics"
+	"APIGolang/internal/model"
+	"APIGolang/internal/pricing"
+	"APIGolang/internal/promotion"
+	"APIGolang/internal/repository"
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrMarginOverrideRequired is returned when some item is below cost or below the category
+// minimum margin and no valid manager authorization was informed
+var ErrMarginOverrideRequired = apperror.Forbidden("margin_override_required", "Existem itens abaixo da margem mínima, é necessária a autorização de um gerente")
+
+// ErrOverrideNotAllowed is returned when the authorizing user is not an administrator
+var ErrOverrideNotAllowed = apperror.Forbidden("override_not_allowed", "O usuário informado não tem permissão para autorizar a venda")
+
+type SaleUseCase struct {
+	saleRepo      repository.SaleRepository
+	productRepo   repository.ProductRepository
+	promotionRepo repository.PromotionRepository
+	ruleRepo      repository.PricingRuleRepository
+	unitOfWork    repository.UnitOfWork
+	authUsecase   *AuthUseCase
+	metrics       *metrics.Metrics
+}
+
+func NewSaleUseCase(saleRepo repository.SaleRepository, productRepo repository.ProductRepository, promotionRepo repository.PromotionRepository, ruleRepo repository.PricingRuleRepository, unitOfWork repository.UnitOfWork, authUsecase *AuthUseCase, m *metrics.Metrics) SaleUseCase {
+	return SaleUseCase{
+		saleRepo:      saleRepo,
+		productRepo:   productRepo,
+		promotionRepo: promotionRepo,
+		ruleRepo:      ruleRepo,
+		unitOfWork:    unitOfWork,
+		authUsecase:   authUsecase,
+		metrics:       m,
+	}
+}
+
+// Quote computes the totals of the cart with the promotions in force, without registering the sale
+func (su *SaleUseCase) Quote(ctx context.Context, request model.SaleRequest) (*model.Sale, error) {
+	sale, _, err := su.buildSale(ctx, request, time.Now())
+	return sale, err
+}
+
+// CreateSale prices the cart, checks the cash register and the payments and registers the sale.
+// Everything runs in one transaction, so the sale is stored with the prices and promotions it was checked against
+func (su *SaleUseCase) CreateSale(ctx context.Context, request model.SaleRequest, actor model.Actor) (*model.Sale, error) {
+
+	userId := *actor.UserId
+
+	// Checked once, out of the transaction the unit of work may retry, so a refused
+	// authorization is counted and recorded even if the sale fails
+	override, err := su.authorizeOverride(ctx, request.ManagerOverride, actor)
+	if err != nil {
+		return nil, err
+	}
+
+	var sale *model.Sale
+	var products map[int]model.Product
+	err = su.unitOfWork.Do(ctx, func(ctx context.Context, tx *repository.Tx) error {
+		var err error
+		sale, products, err = su.withTx(tx).createSale(ctx, request, userId, override)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	su.metrics.SaleCompleted(sale.TotalValue)
+	for _, item := range sale.Items {
+		// Same rule as the repository: products without controla_estoque informed control stock
+		if controlsStock := products[item.ProductId].ControlsStock; controlsStock == nil || *controlsStock {
+			su.metrics.StockMoved(model.StockMovementOut, metrics.SourceSale, item.Quantity)
+		}
+	}
+
+	return sale, nil
+}
+
+// withTx returns a copy of the usecase whose repositories run in tx
+func (su *SaleUseCase) withTx(tx *repository.Tx) *SaleUseCase {
+	bound := *su
+	bound.saleRepo = su.saleRepo.WithTx(tx)
+	bound.productRepo = su.productRepo.WithTx(tx)
+	bound.promotionRepo = su.promotionRepo.WithTx(tx)
+	bound.ruleRepo = su.ruleRepo.WithTx(tx)
+	return &bound
+}
+
+// createSale also returns the products of the cart by id
+func (su *SaleUseCase) createSale(ctx context.Context, request model.SaleRequest, userId int, override managerOverride) (*model.Sale, map[int]model.Product, error) {
+
+	status, err := su.saleRepo.GetCashRegisterStatus(ctx, request.CashRegisterId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if status == nil {
+		return nil, nil, apperror.NotFound("cash_register_not_found", "Caixa não encontrado")
+	}
+	if *status != repository.CashRegisterOpen {
+		return nil, nil, apperror.Conflict("cash_register_closed", "O caixa não está aberto")
+	}
+
+	sale, products, err := su.buildSale(ctx, request, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if belowMinimumMargin(sale) {
+		if override.err != nil {
+			return nil, nil, override.err
+		}
+		sale.AuthorizedBy = &override.managerId
+	}
+
+	if len(request.Payments) == 0 {
+		return nil, nil, apperror.Validation("payment_required", "Informe ao menos um pagamento")
+	}
+	paid := 0.0
+	for _, payment := range request.Payments {
+		if payment.Amount <= 0 {
+			return nil, nil, apperror.Validation("invalid_payment_amount", "O valor do pagamento deve ser maior que zero")
+		}
+		paid += payment.Amount
+	}
+	paid = promotion.Round(paid)
+	if paid < sale.TotalValue {
+		return nil, nil, apperror.Validation("insufficient_payment", "Pagamento insuficiente: total %.2f, pago %.2f", sale.TotalValue, paid)
+	}
+
+	sale.Payments = request.Payments
+	sale.Change = promotion.Round(paid - sale.TotalValue)
+	sale.UserId = userId
+	sale.Status = model.SaleStatusCompleted
+
+	saleId, err := su.saleRepo.CreateSale(ctx, sale)
+	if err != nil {
+		return nil, nil, err
+	}
+	sale.Id = saleId
+
+	return sale, products, nil
+}
+
+// buildSale also returns the products of the cart by id
+func (su *SaleUseCase) buildSale(ctx context.Context, request model.SaleRequest, at time.Time) (*model.Sale, map[int]model.Product, error) {
+
+	if len(request.Items) == 0 {
+		return nil, nil, apperror.Validation("items_required", "A venda precisa ter ao menos um item")
+	}
+
+	// Merge repeated products so promotions see the whole quantity
+	quantities := make(map[int]int)
+	var order []int
+	for _, item := range request.Items {
+		if item.Quantity <= 0 {
+			return nil, nil, apperror.Validation("invalid_quantity", "Quantidade inválida para o produto %d", item.ProductId)
+		}
+		if _, ok := quantities[item.ProductId]; !ok {
+			order = append(order, item.ProductId)
+		}
+		quantities[item.ProductId] += item.Quantity
+	}
+
+	products, err := su.productRepo.GetProductsByIds(ctx, order)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	promotions, err := su.promotionRepo.GetActivePromotions(ctx, at)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	lines := make([]promotion.Line, 0, len(order))
+	for _, productId := range order {
+		product, ok := products[productId]
+		if !ok {
+			return nil, nil, apperror.NotFound("product_not_found", "Produto %d não encontrado", productId)
+		}
+		if product.Active != nil && !*product.Active {
+			return nil, nil, apperror.Validation("product_inactive", "O produto %s está inativo", *product.Name)
+		}
+
+		lines = append(lines, promotion.Line{
+			ProductId:  productId,
+			CategoryId: *product.CategoryId,
+			Quantity:   quantities[productId],
+			UnitPrice:  *product.Price,
+		})
+	}
+
+	discounts := promotion.Apply(lines, promotions)
+
+	rules, err := su.ruleRepo.GetRules(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sale := &model.Sale{
+		Date:           at,
+		CustomerId:     request.CustomerId,
+		CashRegisterId: request.CashRegisterId,
+		Items:          make([]model.SaleItem, 0, len(lines)),
+		Payments:       []model.PaymentRequest{},
+	}
+
+	for i, line := range lines {
+		product := products[line.ProductId]
+		subtotal := promotion.Round(line.UnitPrice * float64(line.Quantity))
+
+		item := model.SaleItem{
+			ProductId:   line.ProductId,
+			ProductName: *product.Name,
+			CategoryId:  line.CategoryId,
+			Quantity:    line.Quantity,
+			UnitPrice:   line.UnitPrice,
+			UnitCost:    *product.CostPrice,
+			Subtotal:    subtotal,
+			Discount:    discounts[i].Amount,
+			PromotionId: discounts[i].PromotionId,
+			Total:       promotion.Round(subtotal - discounts[i].Amount),
+		}
+
+		netUnitPrice := item.Total / float64(item.Quantity)
+		item.Margin = pricing.Margin(item.UnitCost, netUnitPrice)
+		item.BelowMinimumMargin = netUnitPrice < item.UnitCost
+		if rule, ok := rules[item.CategoryId]; ok && rule.MinimumMargin != nil && item.Margin != nil {
+			item.BelowMinimumMargin = item.BelowMinimumMargin || *item.Margin < *rule.MinimumMargin
+		}
+
+		sale.Items = append(sale.Items, item)
+		sale.GrossValue += item.Subtotal
+		sale.Discount += item.Discount
+	}
+
+	sale.GrossValue = promotion.Round(sale.GrossValue)
+	sale.Discount = promotion.Round(sale.Discount)
+	sale.TotalValue = promotion.Round(sale.GrossValue - sale.Discount)
+
+	return sale, products, nil
+}
+
+func belowMinimumMargin(sale *model.Sale) bool {
+	for _, item := range sale.Items {
+		if item.BelowMinimumMargin {
+			return true
+		}
+	}
+	return false
+}
+
+// managerOverride is the outcome of checking the manager credentials informed with a sale
+type managerOverride struct {
+	managerId int
+	// err is why the override does not authorize a sale below the minimum margin, nil when it does
+	err error
+}
+
+// authorizeOverride checks the manager credentials. Wrong credentials only matter for sales below
+// the minimum margin, so they are kept in the result; other failures are returned as they are
+func (su *SaleUseCase) authorizeOverride(ctx context.Context, override *model.ManagerOverride, actor model.Actor) (managerOverride, error) {
+
+	if override == nil {
+		return managerOverride{err: ErrMarginOverrideRequired}, nil
+	}
+
+	manager, err := su.authUsecase.AuthorizeOverride(ctx, override.Username, override.Password, actor)
+	if errors.Is(err, ErrInvalidCredentials) || errors.Is(err, ErrUserInactive) {
+		return managerOverride{err: ErrMarginOverrideRequired}, nil
+	}
+	if errors.Is(err, ErrLoginLocked) || errors.Is(err, ErrOverrideNotAllowed) {
+		return managerOverride{err: err}, nil
+	}
+	if err != nil {
+		return managerOverride{}, err
+	}
+
+	return managerOverride{managerId: manager.Id}, nil
+}
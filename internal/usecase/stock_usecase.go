@@ -0,0 +1,25 @@
+package usecase
+
+import (
+	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"context"
+)
+
+type StockUseCase struct {
+	repository repository.StockRepository
+}
+
+func NewStockUseCase(repo repository.StockRepository) StockUseCase {
+	return StockUseCase{
+		repository: repo,
+	}
+}
+
+func (su *StockUseCase) GetStockPositions(ctx context.Context) ([]model.StockPosition, error) {
+	return su.repository.GetStockPositions(ctx)
+}
+
+func (su *StockUseCase) EachStockPosition(ctx context.Context, fn func(model.StockPosition) error) error {
+	return su.repository.EachStockPosition(ctx, fn)
+}
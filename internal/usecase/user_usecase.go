@@ -1,60 +1,57 @@
 package usecase
 
 import (
+	"APIGolang/internal/apperror"
 	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"context"
 	"errors"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
-type UserRepository interface {
-	CreateUser(user model.User) error
-	UserExists(user_username string) (bool, error)
-	UsernameExistsForOtherUser(username string, user_id int) (bool, error)
-	EmailExists(user_email string) (bool, error)
-	EmailExistsForOtherUser(email string, user_id int) (bool, error)
-	GetUserById(user_id int) (*model.User, error)
-	GetAllUsers() ([]model.User, error)
-	DeleteUserById(user_id int) (bool, error)
-	UpdateUserById(user model.UpdateUserRequest, user_id int) (bool, error)
-	GetUserByEmail(email string) (*model.User, error)
+type UserUseCase struct {
+	repository repository.UserRepository
+	audit      repository.AuditRepository
+	unitOfWork repository.UnitOfWork
 }
 
-type UserUseCase struct {
-	repository UserRepository
+func NewUserUseCase(r repository.UserRepository, audit repository.AuditRepository, unitOfWork repository.UnitOfWork) *UserUseCase {
+	return &UserUseCase{repository: r, audit: audit, unitOfWork: unitOfWork}
 }
 
-func NewUserUseCase(r UserRepository) *UserUseCase {
-	return &UserUseCase{repository: r}
+// withTx returns a copy of the usecase whose repositories run in tx
+func (uc *UserUseCase) withTx(tx *repository.Tx) *UserUseCase {
+	bound := *uc
+	bound.repository = uc.repository.WithTx(tx)
+	bound.audit = uc.audit.WithTx(tx)
+	return &bound
 }
 
-func (uc *UserUseCase) GetUserById(id int) (*model.User, error) {
-	return uc.repository.GetUserById(id)
+func (uc *UserUseCase) GetUserById(ctx context.Context, id int) (*model.User, error) {
+	return uc.repository.GetUserById(ctx, id)
 }
 
-func (a *UserUseCase) CreateUser(req model.CreateUserRequest) error {
+func (a *UserUseCase) CreateUser(ctx context.Context, req model.CreateUserRequest, actor model.Actor) error {
 
-	userExists, err := a.repository.UserExists(req.Username)
+	userExists, err := a.repository.UserExists(ctx, req.Username)
 	if err != nil {
 		return err
 	}
 	if userExists {
-		return errors.New("Nome de usuário já cadastrado")
+		return apperror.Conflict("username_taken", "Nome de usuário já cadastrado")
 	}
 
-	emailExists, err := a.repository.EmailExists(req.Email)
+	emailExists, err := a.repository.EmailExists(ctx, req.Email)
 	if err != nil {
 		return err
 	}
 	if emailExists {
-		return errors.New("Esse email já está cadastrado")
+		return apperror.Conflict("email_taken", "Esse email já está cadastrado")
 	}
 
 	if req.Profile == "" {
-		req.Profile = "OPERADOR"
-	}
-	if req.Role == "" {
-		req.Role = "NO-ROLE"
+		req.Profile = model.ProfileOperator
 	}
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -68,54 +65,123 @@ func (a *UserUseCase) CreateUser(req model.CreateUserRequest) error {
 		Email: req.Email,
 		Password: string(hash),
 		Profile: req.Profile,
-		Role: req.Role,
+		Role: roleForProfile(req.Profile),
 		Active: true,
 	}
 
-	return a.repository.CreateUser(user)
+	return a.unitOfWork.Do(ctx, func(ctx context.Context, tx *repository.Tx) error {
+		bound := a.withTx(tx)
+		user.Id, err = bound.repository.CreateUser(ctx, user)
+		if err != nil {
+			return err
+		}
+		return recordAudit(ctx, &bound.audit, actor, model.AuditEntityUser, user.Id, model.AuditActionCreate, nil, userAuditSnapshot(&user))
+	})
 }
 
-func (a *UserUseCase) GetAllUsers() ([]model.User, error) {
+func (a *UserUseCase) GetAllUsers(ctx context.Context) ([]model.User, error) {
 
-	return a.repository.GetAllUsers()
+	return a.repository.GetAllUsers(ctx)
 }
 
-func (a *UserUseCase) DeleteUserById(user_id int) (bool, error) {
+func (a *UserUseCase) EachUser(ctx context.Context, fn func(model.User) error) error {
 
-	isSucess, err := a.repository.DeleteUserById(user_id)
-	if err != nil {
-		return false, err
-	}
-	return isSucess, nil
+	return a.repository.EachUser(ctx, fn)
 }
 
-func (a *UserUseCase) UpdateUserById(user model.UpdateUserRequest, user_id int) (bool, error) {
-
-	userExists, err := a.repository.UsernameExistsForOtherUser(user.Username, user_id)
-	if err != nil {
-		return false, err
-	}
-	if userExists {
-		return false, errors.New("Nome de usuário já cadastrado")
-	}
+func (a *UserUseCase) DeleteUserById(ctx context.Context, user_id int, actor model.Actor) (bool, error) {
+
+	var isSucess bool
+	err := a.unitOfWork.Do(ctx, func(ctx context.Context, tx *repository.Tx) error {
+		bound := a.withTx(tx)
+
+		before, err := bound.repository.GetUserById(ctx, user_id)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			isSucess = false
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		isSucess, err = bound.repository.DeleteUserById(ctx, user_id)
+		if err != nil || !isSucess {
+			return err
+		}
+		return recordAudit(ctx, &bound.audit, actor, model.AuditEntityUser, user_id, model.AuditActionDelete, userAuditSnapshot(before), nil)
+	})
+	return isSucess, err
+}
 
-	emailExists, err := a.repository.EmailExistsForOtherUser(user.Email, user_id)
-	if err != nil {
-		return false, err
-	}
-	if emailExists {
-		return false, errors.New("Esse email já está cadastrado")
-	}
+func (a *UserUseCase) UpdateUserById(ctx context.Context, user model.UpdateUserRequest, user_id int, actor model.Actor) (bool, error) {
+
+	var isSucess bool
+	err := a.unitOfWork.Do(ctx, func(ctx context.Context, tx *repository.Tx) error {
+		bound := a.withTx(tx)
+		// The profile is filled in below, a retry starts again from the request
+		user := user
+
+		before, err := bound.repository.GetUserById(ctx, user_id)
+		if errors.Is(err, repository.ErrUserNotFound) {
+			isSucess = false
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		userExists, err := bound.repository.UsernameExistsForOtherUser(ctx, user.Username, user_id)
+		if err != nil {
+			return err
+		}
+		if userExists {
+			return apperror.Conflict("username_taken", "Nome de usuário já cadastrado")
+		}
+
+		emailExists, err := bound.repository.EmailExistsForOtherUser(ctx, user.Email, user_id)
+		if err != nil {
+			return err
+		}
+		if emailExists {
+			return apperror.Conflict("email_taken", "Esse email já está cadastrado")
+		}
+
+		// An omitted profile keeps the stored one, so editing the name does not demote the user
+		if user.Profile == "" {
+			user.Profile, user.Role = before.Profile, before.Role
+		} else {
+			user.Role = roleForProfile(user.Profile)
+		}
+
+		isSucess, err = bound.repository.UpdateUserById(ctx, user, user_id)
+		if err != nil || !isSucess {
+			return err
+		}
+		after := *before
+		after.Name, after.Username, after.Email = user.Name, user.Username, user.Email
+		after.Profile, after.Role = user.Profile, user.Role
+		return recordAudit(ctx, &bound.audit, actor, model.AuditEntityUser, user_id, model.AuditActionUpdate, userAuditSnapshot(before), userAuditSnapshot(&after))
+	})
+	return isSucess, err
+}
 
-	if user.Profile == "Administrador" {
-		user.Role = "ADM"
-	} else {
-		user.Role = "NO-ROLE"
+// roleForProfile derives the role checked by RequireRole from the profile, so that only the
+// administrators allowed to set profiles decide who gets ADM
+func roleForProfile(profile string) string {
+	if profile == model.ProfileAdmin {
+		return "ADM"
 	}
+	return "NO-ROLE"
+}
 
-	isSucess, err := a.repository.UpdateUserById(user, user_id)
-	if err != nil {
-		return false, err
+// userAuditSnapshot lists the fields compared in the audit log, the password hash is left out
+func userAuditSnapshot(user *model.User) map[string]any {
+	return map[string]any{
+		"name":     user.Name,
+		"username": user.Username,
+		"email":    user.Email,
+		"profile":  user.Profile,
+		"role":     user.Role,
+		"active":   user.Active,
 	}
-	return isSucess, nil
 }
\ No newline at end of file
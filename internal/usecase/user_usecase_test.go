@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"APIGolang/internal/model"
+	"APIGolang/internal/repository"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestUpdateUserByIdProfile(t *testing.T) {
+	tests := []struct {
+		name        string
+		profile     string
+		wantProfile string
+		wantRole    string
+	}{
+		{"omitted profile keeps the stored one", "", model.ProfileAdmin, "ADM"},
+		{"informed profile sets the role", model.ProfileOperator, model.ProfileOperator, "NO-ROLE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			if err != nil {
+				t.Fatalf("failed to create mock database: %v", err)
+			}
+			defer db.Close()
+
+			mock.ExpectBegin()
+			mock.ExpectQuery("FROM usuario WHERE id_usuario").WithArgs(4).
+				WillReturnRows(sqlmock.NewRows([]string{"id_usuario", "nome", "nome_usuario", "email", "perfil", "role", "ativo"}).
+					AddRow(4, "Maria", "maria", "maria@mercado.com", model.ProfileAdmin, "ADM", true))
+			mock.ExpectQuery("nome_usuario = \\$1 AND id_usuario <> \\$2").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}))
+			mock.ExpectQuery("email = \\$1 AND id_usuario <> \\$2").
+				WillReturnRows(sqlmock.NewRows([]string{"exists"}))
+			mock.ExpectExec("UPDATE usuario SET").
+				WithArgs("Maria Souza", "maria", "maria@mercado.com", tt.wantProfile, tt.wantRole, 4).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec("INSERT INTO auditoria").WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+
+			users := repository.NewUserRepository(db, repository.Timeouts{}, slog.Default())
+			audit := repository.NewAuditRepository(db, repository.Timeouts{})
+			uc := NewUserUseCase(users, audit, repository.NewUnitOfWork(db, repository.Timeouts{}))
+
+			updated, err := uc.UpdateUserById(context.Background(), model.UpdateUserRequest{
+				Name: "Maria Souza", Username: "maria", Email: "maria@mercado.com", Profile: tt.profile,
+			}, 4, model.Actor{})
+			if err != nil || !updated {
+				t.Fatalf("UpdateUserById: %v, %v", updated, err)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
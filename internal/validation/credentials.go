@@ -0,0 +1,43 @@
+package validation
+
+import "unicode"
+
+const (
+	// PasswordMinLength is the shortest password accepted on creation and change
+	PasswordMinLength = 8
+	// PasswordMaxLength is the bcrypt limit, longer passwords would be silently truncated
+	PasswordMaxLength = 72
+)
+
+// ValidUsername accepts letters, digits, dots, hyphens and underscores, without spaces or accents
+func ValidUsername(username string) bool {
+	if username == "" {
+		return false
+	}
+	for _, r := range username {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '.', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// ValidPassword requires PasswordMinLength to PasswordMaxLength bytes with at least one letter and one digit
+func ValidPassword(password string) bool {
+	if len(password) < PasswordMinLength || len(password) > PasswordMaxLength {
+		return false
+	}
+	hasLetter, hasDigit := false, false
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	return hasLetter && hasDigit
+}
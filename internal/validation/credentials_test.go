@@ -0,0 +1,51 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidUsername(t *testing.T) {
+	tests := []struct {
+		name     string
+		username string
+		want     bool
+	}{
+		{"letters and digits", "joao2024", true},
+		{"dot, hyphen and underscore", "maria.silva_caixa-1", true},
+		{"space", "joao silva", false},
+		{"accent", "joão", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidUsername(tt.username); got != tt.want {
+				t.Errorf("ValidUsername(%q) = %v, want %v", tt.username, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		want     bool
+	}{
+		{"letters and digits", "mercado2024", true},
+		{"accented letter counts", "ção12345", true},
+		{"too short", "abc123", false},
+		{"only letters", "mercadinho", false},
+		{"only digits", "12345678", false},
+		{"longer than bcrypt accepts", strings.Repeat("a1", 37), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidPassword(tt.password); got != tt.want {
+				t.Errorf("ValidPassword(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
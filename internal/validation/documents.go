@@ -0,0 +1,74 @@
+package validation
+
+import "strings"
+
+// OnlyDigits strips every non numeric character, e.g. the mask of a CNPJ
+func OnlyDigits(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// ValidGTIN checks length and check digit of GTIN-8, GTIN-12 (UPC), GTIN-13 (EAN) and GTIN-14 codes
+func ValidGTIN(code string) bool {
+	switch len(code) {
+	case 8, 12, 13, 14:
+	default:
+		return false
+	}
+	if OnlyDigits(code) != code {
+		return false
+	}
+
+	sum := 0
+	// Weights alternate 3 and 1 starting from the digit next to the check digit
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	check := (10 - sum%10) % 10
+	return check == int(code[len(code)-1]-'0')
+}
+
+// ValidCNPJ checks the two verification digits of a CNPJ, masked or not
+func ValidCNPJ(cnpj string) bool {
+	cnpj = OnlyDigits(cnpj)
+	if len(cnpj) != 14 || allSameDigit(cnpj) {
+		return false
+	}
+
+	firstWeights := []int{5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+	secondWeights := []int{6, 5, 4, 3, 2, 9, 8, 7, 6, 5, 4, 3, 2}
+
+	return verifierDigit(cnpj[:12], firstWeights) == int(cnpj[12]-'0') &&
+		verifierDigit(cnpj[:13], secondWeights) == int(cnpj[13]-'0')
+}
+
+func verifierDigit(digits string, weights []int) int {
+	sum := 0
+	for i, w := range weights {
+		sum += int(digits[i]-'0') * w
+	}
+	rest := sum % 11
+	if rest < 2 {
+		return 0
+	}
+	return 11 - rest
+}
+
+func allSameDigit(value string) bool {
+	for i := 1; i < len(value); i++ {
+		if value[i] != value[0] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package validation
+
+import "testing"
+
+func TestValidGTIN(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"valid EAN-13", "7891000315507", true},
+		{"valid EAN-8", "96385074", true},
+		{"valid UPC-A", "036000291452", true},
+		{"valid GTIN-14", "17891000315504", true},
+		{"wrong check digit", "7891000315508", false},
+		{"invalid length", "7891000", false},
+		{"non numeric", "78910003155O7", false},
+		{"empty", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidGTIN(tt.code); got != tt.want {
+				t.Errorf("ValidGTIN(%q) = %v, want %v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidCNPJ(t *testing.T) {
+	tests := []struct {
+		name string
+		cnpj string
+		want bool
+	}{
+		{"valid unmasked", "11222333000181", true},
+		{"valid masked", "11.222.333/0001-81", true},
+		{"wrong verifier digit", "11222333000182", false},
+		{"all same digit", "11111111111111", false},
+		{"short", "1122233300018", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ValidCNPJ(tt.cnpj); got != tt.want {
+				t.Errorf("ValidCNPJ(%q) = %v, want %v", tt.cnpj, got, tt.want)
+			}
+		})
+	}
+}
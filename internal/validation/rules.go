@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"APIGolang/internal/i18n"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+)
+
+// rule is a custom binding tag, e.g. `binding:"omitempty,gtin"`, with its message per locale
+type rule struct {
+	valid    func(string) bool
+	messages map[string]string
+}
+
+var rules = map[string]rule{
+	"cnpj": {ValidCNPJ, map[string]string{
+		i18n.PtBR: "{0} não é um CNPJ válido",
+		i18n.EnUS: "{0} must be a valid CNPJ",
+	}},
+	"gtin": {ValidGTIN, map[string]string{
+		i18n.PtBR: "{0} não é um código de barras GTIN válido",
+		i18n.EnUS: "{0} must be a valid GTIN barcode",
+	}},
+	"username": {ValidUsername, map[string]string{
+		i18n.PtBR: "{0} deve conter apenas letras sem acento, números, ponto, hífen ou sublinhado",
+		i18n.EnUS: "{0} must contain only unaccented letters, numbers, dots, hyphens or underscores",
+	}},
+	"password": {ValidPassword, map[string]string{
+		i18n.PtBR: "{0} deve ter entre 8 e 72 caracteres, com ao menos uma letra e um número",
+		i18n.EnUS: "{0} must have between 8 and 72 characters, with at least one letter and one number",
+	}},
+}
+
+// Register adds the custom tags to v along with their messages for every supported locale
+// Must be called after i18n.RegisterValidator, which sets up the translators
+func Register(v *validator.Validate) error {
+
+	for tag, r := range rules {
+		valid := r.valid
+		err := v.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+			return valid(fl.Field().String())
+		})
+		if err != nil {
+			return err
+		}
+
+		for locale, message := range r.messages {
+			err := v.RegisterTranslation(tag, i18n.Translator(locale), registerMessage(tag, message), translateMessage)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func registerMessage(tag, message string) validator.RegisterTranslationsFunc {
+	return func(translator ut.Translator) error {
+		return translator.Add(tag, message, true)
+	}
+}
+
+func translateMessage(translator ut.Translator, fieldErr validator.FieldError) string {
+	message, err := translator.T(fieldErr.Tag(), fieldErr.Field())
+	if err != nil {
+		return fieldErr.Error()
+	}
+	return message
+}
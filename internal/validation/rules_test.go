@@ -0,0 +1,45 @@
+package validation
+
+import (
+	"testing"
+
+	"APIGolang/internal/i18n"
+
+	"github.com/go-playground/validator/v10"
+)
+
+type supplierForm struct {
+	Cnpj    string  `json:"cnpj" binding:"cnpj"`
+	Barcode *string `json:"barcode" binding:"omitempty,gtin"`
+}
+
+func TestRegister(t *testing.T) {
+	v := validator.New()
+	v.SetTagName("binding")
+	if err := i18n.RegisterValidator(v); err != nil {
+		t.Fatalf("RegisterValidator: %v", err)
+	}
+	if err := Register(v); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	if err := v.Struct(supplierForm{Cnpj: "11.222.333/0001-81"}); err != nil {
+		t.Errorf("valid form rejected: %v", err)
+	}
+
+	barcode := "7891000315508"
+	err := v.Struct(supplierForm{Cnpj: "11222333000182", Barcode: &barcode})
+	errs, ok := err.(validator.ValidationErrors)
+	if !ok {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+
+	messages := i18n.ValidationMessages(i18n.PtBR, errs)
+	if messages["cnpj"] != "cnpj não é um CNPJ válido" {
+		t.Errorf("unexpected pt-BR message: %v", messages)
+	}
+	messages = i18n.ValidationMessages(i18n.EnUS, errs)
+	if messages["barcode"] != "barcode must be a valid GTIN barcode" {
+		t.Errorf("unexpected en-US message: %v", messages)
+	}
+}
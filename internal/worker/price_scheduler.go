@@ -0,0 +1,54 @@
+package worker
+
+import (
+	"APIGolang/internal/usecase"
+	"context"
+	"log/slog"
+	"time"
+)
+
+// PriceScheduler periodically applies the scheduled price changes that became due
+type PriceScheduler struct {
+	usecase  usecase.PriceUseCase
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+func NewPriceScheduler(usecase usecase.PriceUseCase, interval time.Duration, logger *slog.Logger) *PriceScheduler {
+	return &PriceScheduler{
+		usecase:  usecase,
+		interval: interval,
+		logger:   logger.With("worker", "price_scheduler"),
+	}
+}
+
+// Run blocks applying due changes every interval until ctx is cancelled
+func (s *PriceScheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.applyDueChanges(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.applyDueChanges(ctx)
+		}
+	}
+}
+
+// applyDueChanges runs with the worker context, so a shutdown cancels the pending transaction
+func (s *PriceScheduler) applyDueChanges(ctx context.Context) {
+	applied, rejected, err := s.usecase.ApplyDueChanges(ctx)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "failed to apply scheduled price changes", "error", err)
+		return
+	}
+	if applied > 0 {
+		s.logger.InfoContext(ctx, "applied scheduled price changes", "count", applied)
+	}
+	if rejected > 0 {
+		s.logger.WarnContext(ctx, "rejected scheduled price changes below the minimum margin", "count", rejected)
+	}
+}
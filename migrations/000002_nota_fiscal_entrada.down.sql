@@ -0,0 +1,6 @@
+-- Rollback supplier invoices
+
+ALTER TABLE movimentacao_estoque DROP COLUMN IF EXISTS nota_fiscal_id;
+
+DROP TABLE IF EXISTS produto_fornecedor CASCADE;
+DROP TABLE IF EXISTS nota_fiscal_entrada CASCADE;
@@ -0,0 +1,38 @@
+-- Supplier invoices (NF-e) imported as stock entries
+
+-- ============================================================================
+-- NOTA_FISCAL_ENTRADA (Imported supplier invoices)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS nota_fiscal_entrada (
+    id_nota_fiscal SERIAL PRIMARY KEY,
+    chave_acesso VARCHAR(44) NOT NULL UNIQUE,
+    numero VARCHAR(9) NOT NULL,
+    serie VARCHAR(3) NOT NULL,
+    data_emissao TIMESTAMP NOT NULL,
+    valor_total NUMERIC(12,2) NOT NULL,
+    fornecedor_id INT NOT NULL,
+    usuario_id INT,
+    data_importacao TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Foreign keys
+    FOREIGN KEY (fornecedor_id) REFERENCES fornecedor(id_fornecedor),
+    FOREIGN KEY (usuario_id) REFERENCES usuario(id_usuario)
+);
+
+-- ============================================================================
+-- PRODUTO_FORNECEDOR (Supplier product codes)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS produto_fornecedor (
+    fornecedor_id INT NOT NULL,
+    codigo_fornecedor VARCHAR(60) NOT NULL,
+    produto_id INT NOT NULL,
+
+    PRIMARY KEY (fornecedor_id, codigo_fornecedor),
+
+    -- Foreign keys
+    FOREIGN KEY (fornecedor_id) REFERENCES fornecedor(id_fornecedor),
+    FOREIGN KEY (produto_id) REFERENCES produto(id_produto) ON DELETE CASCADE
+);
+
+ALTER TABLE movimentacao_estoque
+    ADD COLUMN IF NOT EXISTS nota_fiscal_id INT REFERENCES nota_fiscal_entrada(id_nota_fiscal);
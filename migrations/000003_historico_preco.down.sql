@@ -0,0 +1,4 @@
+-- Rollback price history and scheduled price changes
+
+DROP TABLE IF EXISTS alteracao_preco_agendada CASCADE;
+DROP TABLE IF EXISTS historico_preco CASCADE;
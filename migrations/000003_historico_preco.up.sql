@@ -0,0 +1,43 @@
+-- Price history and scheduled price changes
+
+-- ============================================================================
+-- HISTORICO_PRECO (Product price history)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS historico_preco (
+    id_historico SERIAL PRIMARY KEY,
+    produto_id INT NOT NULL,
+    preco_venda_anterior NUMERIC(10,2),
+    preco_venda_novo NUMERIC(10,2) NOT NULL,
+    preco_custo_anterior NUMERIC(10,2),
+    preco_custo_novo NUMERIC(10,2) NOT NULL,
+    origem VARCHAR(20) NOT NULL,
+    usuario_id INT,
+    data_alteracao TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Foreign keys
+    FOREIGN KEY (produto_id) REFERENCES produto(id_produto) ON DELETE CASCADE,
+    FOREIGN KEY (usuario_id) REFERENCES usuario(id_usuario)
+);
+
+CREATE INDEX IF NOT EXISTS idx_historico_preco_produto ON historico_preco (produto_id, data_alteracao);
+
+-- ============================================================================
+-- ALTERACAO_PRECO_AGENDADA (Scheduled price changes)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS alteracao_preco_agendada (
+    id_alteracao SERIAL PRIMARY KEY,
+    produto_id INT NOT NULL,
+    preco_venda NUMERIC(10,2) NOT NULL,
+    preco_custo NUMERIC(10,2),
+    data_vigencia TIMESTAMPTZ NOT NULL,  -- An instant, so a change scheduled from another offset applies on time
+    status VARCHAR(10) NOT NULL DEFAULT 'PENDENTE',
+    usuario_id INT,
+    data_criacao TIMESTAMP NOT NULL DEFAULT NOW(),
+    data_aplicacao TIMESTAMP,
+
+    -- Foreign keys
+    FOREIGN KEY (produto_id) REFERENCES produto(id_produto) ON DELETE CASCADE,
+    FOREIGN KEY (usuario_id) REFERENCES usuario(id_usuario)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alteracao_preco_pendente ON alteracao_preco_agendada (data_vigencia) WHERE status = 'PENDENTE';
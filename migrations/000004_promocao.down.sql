@@ -0,0 +1,8 @@
+-- Rollback promotions
+
+ALTER TABLE item_venda
+    DROP COLUMN IF EXISTS promocao_id,
+    DROP COLUMN IF EXISTS desconto;
+
+DROP TABLE IF EXISTS promocao_produto CASCADE;
+DROP TABLE IF EXISTS promocao CASCADE;
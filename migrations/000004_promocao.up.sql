@@ -0,0 +1,53 @@
+-- Promotions and per item discount attribution
+
+-- ============================================================================
+-- PROMOCAO (Promotions)
+-- ============================================================================
+-- tipo:
+--   PERCENTUAL  percentual_desconto off the listed products
+--   PRECO_FIXO  listed products sold for preco_fixo
+--   LEVE_PAGUE  buy quantidade_leve, pay quantidade_pague of each listed product
+--   CATEGORIA   percentual_desconto off every product of categoria_id
+--   COMBO       the listed products, in the listed quantities, sold together for preco_fixo
+CREATE TABLE IF NOT EXISTS promocao (
+    id_promocao SERIAL PRIMARY KEY,
+    nome VARCHAR(100) NOT NULL,
+    tipo VARCHAR(20) NOT NULL,
+    percentual_desconto NUMERIC(5,2),
+    preco_fixo NUMERIC(10,2),
+    quantidade_leve INT,
+    quantidade_pague INT,
+    categoria_id INT,
+    data_inicio TIMESTAMP NOT NULL,
+    data_fim TIMESTAMP NOT NULL,
+    ativo BOOLEAN NOT NULL DEFAULT TRUE,
+    data_criacao TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Foreign keys
+    FOREIGN KEY (categoria_id) REFERENCES categoria(id_categoria),
+
+    CHECK (data_fim > data_inicio)
+);
+
+-- ============================================================================
+-- PROMOCAO_PRODUTO (Products covered by a promotion)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS promocao_produto (
+    promocao_id INT NOT NULL,
+    produto_id INT NOT NULL,
+    quantidade INT NOT NULL DEFAULT 1, -- Units required by COMBO
+
+    PRIMARY KEY (promocao_id, produto_id),
+
+    -- Foreign keys
+    FOREIGN KEY (promocao_id) REFERENCES promocao(id_promocao) ON DELETE CASCADE,
+    FOREIGN KEY (produto_id) REFERENCES produto(id_produto) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_promocao_vigencia ON promocao (data_inicio, data_fim) WHERE ativo;
+
+-- item_venda.subtotal stays the gross value (quantidade * preco_unitario),
+-- the net value of the item is subtotal - desconto
+ALTER TABLE item_venda
+    ADD COLUMN IF NOT EXISTS desconto NUMERIC(10,2) NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS promocao_id INT REFERENCES promocao(id_promocao);
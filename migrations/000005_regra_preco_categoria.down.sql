@@ -0,0 +1,5 @@
+-- Rollback pricing rules per category
+
+ALTER TABLE venda DROP COLUMN IF EXISTS autorizado_por;
+
+DROP TABLE IF EXISTS regra_preco_categoria CASCADE;
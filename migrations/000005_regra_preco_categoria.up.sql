@@ -0,0 +1,18 @@
+-- Pricing rules per category
+
+-- ============================================================================
+-- REGRA_PRECO_CATEGORIA (Target markup, minimum margin and rounding per category)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS regra_preco_categoria (
+    categoria_id INT PRIMARY KEY,
+    markup_alvo NUMERIC(6,2),              -- Percent over cost used to suggest the sale price
+    margem_minima NUMERIC(5,2),            -- Lowest margin over the sale price allowed at checkout
+    arredondamento VARCHAR(10) NOT NULL DEFAULT 'NENHUM', -- NENHUM, X_99 or X_49
+    data_atualizacao TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    -- Foreign keys
+    FOREIGN KEY (categoria_id) REFERENCES categoria(id_categoria) ON DELETE CASCADE
+);
+
+-- Manager who authorized items sold below cost or below the minimum margin
+ALTER TABLE venda ADD COLUMN IF NOT EXISTS autorizado_por INT REFERENCES usuario(id_usuario);
@@ -0,0 +1,5 @@
+-- Rollback indexes used by the sales reports
+
+DROP INDEX IF EXISTS idx_pagamento_venda;
+DROP INDEX IF EXISTS idx_item_venda_venda;
+DROP INDEX IF EXISTS idx_venda_status_data;
@@ -0,0 +1,5 @@
+-- Indexes used by the sales reports
+
+CREATE INDEX IF NOT EXISTS idx_venda_status_data ON venda (status, data_venda);
+CREATE INDEX IF NOT EXISTS idx_item_venda_venda ON item_venda (venda_id);
+CREATE INDEX IF NOT EXISTS idx_pagamento_venda ON pagamento (venda_id);
@@ -0,0 +1,3 @@
+-- Rollback index used by the ABC curve and the idle products analysis
+
+DROP INDEX IF EXISTS idx_item_venda_produto;
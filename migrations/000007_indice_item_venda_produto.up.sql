@@ -0,0 +1,3 @@
+-- Index used by the ABC curve and the idle products analysis
+
+CREATE INDEX IF NOT EXISTS idx_item_venda_produto ON item_venda (produto_id);
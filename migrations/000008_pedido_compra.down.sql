@@ -0,0 +1,6 @@
+-- Rollback purchase orders to suppliers
+
+ALTER TABLE movimentacao_estoque DROP COLUMN IF EXISTS pedido_compra_id;
+
+DROP TABLE IF EXISTS item_pedido_compra CASCADE;
+DROP TABLE IF EXISTS pedido_compra CASCADE;
@@ -0,0 +1,42 @@
+-- Purchase orders to suppliers
+
+-- ============================================================================
+-- PEDIDO_COMPRA (Purchase orders)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS pedido_compra (
+    id_pedido SERIAL PRIMARY KEY,
+    fornecedor_id INT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'RASCUNHO', -- RASCUNHO, ENVIADO, RECEBIDO_PARCIAL, RECEBIDO
+    observacao TEXT,
+    usuario_id INT,
+    data_criacao TIMESTAMP NOT NULL DEFAULT NOW(),
+    data_envio TIMESTAMP,
+    data_recebimento TIMESTAMP,
+
+    -- Foreign keys
+    FOREIGN KEY (fornecedor_id) REFERENCES fornecedor(id_fornecedor),
+    FOREIGN KEY (usuario_id) REFERENCES usuario(id_usuario)
+);
+
+CREATE INDEX IF NOT EXISTS idx_pedido_compra_status ON pedido_compra (status, fornecedor_id);
+
+-- ============================================================================
+-- ITEM_PEDIDO_COMPRA (Purchase order items)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS item_pedido_compra (
+    id_item_pedido SERIAL PRIMARY KEY,
+    pedido_id INT NOT NULL,
+    produto_id INT NOT NULL,
+    quantidade INT NOT NULL CHECK (quantidade > 0),
+    quantidade_recebida INT NOT NULL DEFAULT 0,
+    custo_unitario NUMERIC(10,2) NOT NULL,
+
+    UNIQUE (pedido_id, produto_id),
+
+    -- Foreign keys
+    FOREIGN KEY (pedido_id) REFERENCES pedido_compra(id_pedido) ON DELETE CASCADE,
+    FOREIGN KEY (produto_id) REFERENCES produto(id_produto)
+);
+
+ALTER TABLE movimentacao_estoque
+    ADD COLUMN IF NOT EXISTS pedido_compra_id INT REFERENCES pedido_compra(id_pedido);
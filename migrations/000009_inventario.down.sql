@@ -0,0 +1,7 @@
+-- Rollback physical inventory count sessions
+
+ALTER TABLE movimentacao_estoque DROP COLUMN IF EXISTS inventario_id;
+
+DROP TABLE IF EXISTS inventario_contagem CASCADE;
+DROP TABLE IF EXISTS inventario_item CASCADE;
+DROP TABLE IF EXISTS inventario CASCADE;
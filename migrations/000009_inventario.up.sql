@@ -0,0 +1,59 @@
+-- Physical inventory count sessions
+
+-- ============================================================================
+-- INVENTARIO (Count sessions)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS inventario (
+    id_inventario SERIAL PRIMARY KEY,
+    categoria_id INT,                        -- NULL counts every product
+    status VARCHAR(10) NOT NULL DEFAULT 'ABERTO', -- ABERTO or FECHADO
+    observacao TEXT,
+    usuario_abertura INT,
+    usuario_fechamento INT,
+    data_abertura TIMESTAMP NOT NULL DEFAULT NOW(),
+    data_fechamento TIMESTAMP,
+
+    -- Foreign keys
+    FOREIGN KEY (categoria_id) REFERENCES categoria(id_categoria),
+    FOREIGN KEY (usuario_abertura) REFERENCES usuario(id_usuario),
+    FOREIGN KEY (usuario_fechamento) REFERENCES usuario(id_usuario)
+);
+
+-- ============================================================================
+-- INVENTARIO_ITEM (Products in scope, with the stock frozen when the session opened)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS inventario_item (
+    inventario_id INT NOT NULL,
+    produto_id INT NOT NULL,
+    estoque_congelado INT NOT NULL,
+    custo_unitario NUMERIC(10,2) NOT NULL,
+    quantidade_contada INT,                  -- Filled when the session is closed
+
+    PRIMARY KEY (inventario_id, produto_id),
+
+    -- Foreign keys
+    FOREIGN KEY (inventario_id) REFERENCES inventario(id_inventario) ON DELETE CASCADE,
+    FOREIGN KEY (produto_id) REFERENCES produto(id_produto)
+);
+
+-- ============================================================================
+-- INVENTARIO_CONTAGEM (Counts submitted by each device, summed per product)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS inventario_contagem (
+    id_contagem SERIAL PRIMARY KEY,
+    inventario_id INT NOT NULL,
+    produto_id INT NOT NULL,
+    dispositivo VARCHAR(50) NOT NULL,
+    quantidade INT NOT NULL CHECK (quantidade >= 0),
+    usuario_id INT,
+    data_contagem TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE (inventario_id, produto_id, dispositivo),
+
+    -- Foreign keys
+    FOREIGN KEY (inventario_id, produto_id) REFERENCES inventario_item(inventario_id, produto_id) ON DELETE CASCADE,
+    FOREIGN KEY (usuario_id) REFERENCES usuario(id_usuario)
+);
+
+ALTER TABLE movimentacao_estoque
+    ADD COLUMN IF NOT EXISTS inventario_id INT REFERENCES inventario(id_inventario);
@@ -0,0 +1,8 @@
+-- Rollback lot and expiration date tracking
+
+ALTER TABLE movimentacao_estoque DROP COLUMN IF EXISTS lote_id;
+
+DROP TABLE IF EXISTS item_venda_lote CASCADE;
+DROP TABLE IF EXISTS lote CASCADE;
+
+ALTER TABLE produto DROP COLUMN IF EXISTS controla_lote;
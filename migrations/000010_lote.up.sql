@@ -0,0 +1,42 @@
+-- Lot and expiration date tracking
+
+ALTER TABLE produto ADD COLUMN IF NOT EXISTS controla_lote BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- ============================================================================
+-- LOTE (Product lots)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS lote (
+    id_lote SERIAL PRIMARY KEY,
+    produto_id INT NOT NULL,
+    numero_lote VARCHAR(30) NOT NULL,
+    data_fabricacao DATE,
+    data_validade DATE,
+    quantidade INT NOT NULL DEFAULT 0 CHECK (quantidade >= 0), -- Quantity still in stock
+    quantidade_inicial INT NOT NULL DEFAULT 0,                 -- Quantity received
+    data_entrada TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    UNIQUE (produto_id, numero_lote),
+
+    -- Foreign keys
+    FOREIGN KEY (produto_id) REFERENCES produto(id_produto) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_lote_validade ON lote (data_validade) WHERE quantidade > 0;
+
+-- ============================================================================
+-- ITEM_VENDA_LOTE (Lots consumed by each sale item)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS item_venda_lote (
+    item_venda_id INT NOT NULL,
+    lote_id INT NOT NULL,
+    quantidade INT NOT NULL,
+
+    PRIMARY KEY (item_venda_id, lote_id),
+
+    -- Foreign keys
+    FOREIGN KEY (item_venda_id) REFERENCES item_venda(id_item_venda) ON DELETE CASCADE,
+    FOREIGN KEY (lote_id) REFERENCES lote(id_lote)
+);
+
+ALTER TABLE movimentacao_estoque
+    ADD COLUMN IF NOT EXISTS lote_id INT REFERENCES lote(id_lote);
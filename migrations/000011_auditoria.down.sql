@@ -0,0 +1,4 @@
+-- Rollback audit log
+
+DROP TABLE IF EXISTS auditoria CASCADE;
+DROP FUNCTION IF EXISTS auditoria_somente_insercao();
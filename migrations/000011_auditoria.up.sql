@@ -0,0 +1,36 @@
+-- Audit log of mutating operations
+
+-- ============================================================================
+-- AUDITORIA (Append-only audit log)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS auditoria (
+    id_auditoria BIGSERIAL PRIMARY KEY,
+    data TIMESTAMP NOT NULL DEFAULT NOW(),
+    usuario_id INT,                   -- No foreign key, the entry outlives the user
+    nome_usuario VARCHAR(20),
+    ip VARCHAR(45),
+    user_agent TEXT,
+    entidade VARCHAR(30) NOT NULL,
+    entidade_id VARCHAR(30) NOT NULL,
+    acao VARCHAR(10) NOT NULL CHECK (acao IN ('CRIACAO', 'ALTERACAO', 'EXCLUSAO')),
+    alteracoes JSONB NOT NULL DEFAULT '{}'  -- {campo: {before, after}}
+);
+
+CREATE INDEX IF NOT EXISTS idx_auditoria_entidade ON auditoria (entidade, entidade_id);
+CREATE INDEX IF NOT EXISTS idx_auditoria_data ON auditoria (data);
+
+CREATE OR REPLACE FUNCTION auditoria_somente_insercao() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'auditoria é somente inserção';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_auditoria_somente_insercao ON auditoria;
+CREATE TRIGGER trg_auditoria_somente_insercao
+    BEFORE UPDATE OR DELETE ON auditoria
+    FOR EACH ROW EXECUTE FUNCTION auditoria_somente_insercao();
+
+DROP TRIGGER IF EXISTS trg_auditoria_sem_truncate ON auditoria;
+CREATE TRIGGER trg_auditoria_sem_truncate
+    BEFORE TRUNCATE ON auditoria
+    FOR EACH STATEMENT EXECUTE FUNCTION auditoria_somente_insercao();
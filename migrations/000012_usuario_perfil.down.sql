@@ -0,0 +1,5 @@
+-- Rollback usuario.perfil length and role
+
+ALTER TABLE usuario DROP COLUMN IF EXISTS role;
+
+ALTER TABLE usuario ALTER COLUMN perfil TYPE VARCHAR(10);
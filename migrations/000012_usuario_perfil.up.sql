@@ -0,0 +1,6 @@
+-- "Administrador" does not fit the original VARCHAR(10) of usuario.perfil
+
+ALTER TABLE usuario ALTER COLUMN perfil TYPE VARCHAR(15);
+
+-- The role derived from the profile, read by the login and the role checks
+ALTER TABLE usuario ADD COLUMN IF NOT EXISTS role VARCHAR(10) NOT NULL DEFAULT 'NO-ROLE';
@@ -0,0 +1,9 @@
+-- Rollback the login lockout
+
+-- The audit log is append-only, the NEGACAO entries already recorded are kept
+ALTER TABLE auditoria DROP CONSTRAINT IF EXISTS auditoria_acao_check;
+ALTER TABLE auditoria ADD CONSTRAINT auditoria_acao_check
+    CHECK (acao IN ('CRIACAO', 'ALTERACAO', 'EXCLUSAO')) NOT VALID;
+
+ALTER TABLE usuario DROP COLUMN IF EXISTS bloqueado_ate;
+ALTER TABLE usuario DROP COLUMN IF EXISTS tentativas_login;
@@ -0,0 +1,9 @@
+-- Failed login counter and lockout, shared by the login and the manager authorizations
+
+ALTER TABLE usuario ADD COLUMN IF NOT EXISTS tentativas_login INT NOT NULL DEFAULT 0;
+ALTER TABLE usuario ADD COLUMN IF NOT EXISTS bloqueado_ate TIMESTAMPTZ;
+
+-- Denied manager authorizations are recorded in the audit log
+ALTER TABLE auditoria DROP CONSTRAINT IF EXISTS auditoria_acao_check;
+ALTER TABLE auditoria ADD CONSTRAINT auditoria_acao_check
+    CHECK (acao IN ('CRIACAO', 'ALTERACAO', 'EXCLUSAO', 'NEGACAO'));